@@ -3,6 +3,7 @@ package common
 import (
 	"errors"
 	"starrocks-migrate-tool/model"
+	"strings"
 )
 
 const (
@@ -15,13 +16,39 @@ const (
 	DATETIME_ISO_TEMPLATE   = "2006-01-02T15:04:05Z"
 	TIME_TEMPLATE           = "15:04:05"
 	SHARD_SUFFIX            = "_auto_shard"
+	ROWS_PER_BUCKET         = 50 * 1000 * 1000
 )
 
+// constraint types of information_schema.table_constraints
+const (
+	CONSTRAINT_PRIMARY_KEY = "PRIMARY KEY"
+	CONSTRAINT_UNIQUE      = "UNIQUE"
+	CONSTRAINT_FOREIGN_KEY = "FOREIGN KEY"
+)
+
+// charsetMaxBytes max bytes of a character of the charset encoded in utf-8
+var charsetMaxBytes = map[string]int64{
+	"binary": 1, "ascii": 1, "us7ascii": 1,
+	"latin1": 2, "iso_1": 2, "we8iso8859p1": 2,
+	"utf8": 3, "utf8mb3": 3, "ucs2": 3, "unicode": 3, "al16utf16": 3, "cp1252": 3, "we8mswin1252": 3,
+	"gbk": 3, "gb2312": 3, "big5": 3, "sjis": 3, "ujis": 3, "euckr": 3, "zhs16gbk": 3, "zht16big5": 3, "ja16sjis": 3, "ko16mswin949": 3,
+	"utf8mb4": 4, "utf16": 4, "utf32": 4, "gb18030": 4, "al32utf8": 4,
+}
+
+// CharsetMaxBytes max bytes of a character of the charset in utf-8, 4 for unknown charsets
+func CharsetMaxBytes(charset string) int64 {
+	if maxBytes, ok := charsetMaxBytes[strings.ToLower(charset)]; ok {
+		return maxBytes
+	}
+	return 4
+}
+
 type TableColumns struct {
 	Table      *model.Table
 	Columns    []*model.Column
 	PrimaryKCU []*model.KeyColumnUsage
 	UniqueKCU  []*model.KeyColumnUsage
+	ForeignKCU []*model.KeyColumnUsage
 }
 
 type DBSourceType int
@@ -35,6 +62,14 @@ const (
 	DBSourceClickHouse
 	DBSourceHive
 	DBSourceTiDB
+	DBSourceIceberg
+	DBSourceDelta
+	DBSourceMongoDB
+	DBSourceStarRocks
+	DBSourceDoris
+	DBSourceMariaDB
+	DBSourceOceanBase
+	DBSourcePolarDBX
 )
 
 var dbSourceTypeMap = map[string]DBSourceType{
@@ -45,6 +80,14 @@ var dbSourceTypeMap = map[string]DBSourceType{
 	"clickhouse": DBSourceClickHouse,
 	"hive":       DBSourceHive,
 	"tidb":       DBSourceTiDB,
+	"iceberg":    DBSourceIceberg,
+	"delta":      DBSourceDelta,
+	"mongodb":    DBSourceMongoDB,
+	"starrocks":  DBSourceStarRocks,
+	"doris":      DBSourceDoris,
+	"mariadb":    DBSourceMariaDB,
+	"oceanbase":  DBSourceOceanBase,
+	"polardbx":   DBSourcePolarDBX,
 }
 
 func ParseDBSourceType(name string) (DBSourceType, error) {
@@ -90,4 +133,7 @@ const (
 	ConvertToFlink = 1 << iota
 	ConvertToStarRocks
 	ConvertToStarRocksExternal
+	ConvertToStarRocksMaterializedView
+	ConvertToStarRocksBackfill
+	ConvertToStarRocksLoad
 )
@@ -46,6 +46,14 @@ func TestParseDBSourceType(t *testing.T) {
 		{name: "clickhouse", want: DBSourceClickHouse, wantErr: false},
 		{name: "hive", want: DBSourceHive, wantErr: false},
 		{name: "tidb", want: DBSourceTiDB, wantErr: false},
+		{name: "iceberg", want: DBSourceIceberg, wantErr: false},
+		{name: "delta", want: DBSourceDelta, wantErr: false},
+		{name: "mongodb", want: DBSourceMongoDB, wantErr: false},
+		{name: "starrocks", want: DBSourceStarRocks, wantErr: false},
+		{name: "doris", want: DBSourceDoris, wantErr: false},
+		{name: "mariadb", want: DBSourceMariaDB, wantErr: false},
+		{name: "oceanbase", want: DBSourceOceanBase, wantErr: false},
+		{name: "polardbx", want: DBSourcePolarDBX, wantErr: false},
 		{name: "rocksdb", want: DBSourceUnknow, wantErr: true},
 	}
 	for _, tt := range tests {
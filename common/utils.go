@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/gob"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/dlclark/regexp2"
 )
@@ -63,3 +65,31 @@ func RegMatchString(pattern, str string) bool {
 	}
 	return reDB.Match([]byte(str))
 }
+
+// CompareVersion compares dot separated versions like `2.5.7` numerically, suffixes like `-rc01` are ignored
+func CompareVersion(v1, v2 string) int {
+	parts1, parts2 := strings.Split(v1, "."), strings.Split(v2, ".")
+	for i := 0; i < len(parts1) || i < len(parts2); i++ {
+		n1, n2 := versionNumber(parts1, i), versionNumber(parts2, i)
+		if n1 != n2 {
+			if n1 < n2 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionNumber(parts []string, idx int) int {
+	if idx >= len(parts) {
+		return 0
+	}
+	part := strings.TrimLeft(parts[idx], "vV")
+	end := 0
+	for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+		end++
+	}
+	number, _ := strconv.Atoi(part[:end])
+	return number
+}
@@ -0,0 +1,23 @@
+package common
+
+import "testing"
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		{v1: "3.1.0", v2: "3.1", want: 0},
+		{v1: "2.5.10", v2: "2.5.7", want: 1},
+		{v1: "v3.0.4-rc01", v2: "3.1.0", want: -1},
+		{v1: "3.2", v2: "2.5.7", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+"_"+tt.v2, func(t *testing.T) {
+			if got := CompareVersion(tt.v1, tt.v2); got != tt.want {
+				t.Errorf("CompareVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package conf
+
+import "starrocks-migrate-tool/common"
+
+// Capability features of starrocks depending on the version of the target cluster
+type Capability int
+
+const (
+	CapabilityDecimalV3 Capability = iota
+	CapabilityPrimaryKey
+	CapabilityArray
+	CapabilityJSON
+	CapabilityExternalCatalog
+	CapabilityAutoBuckets
+	CapabilityExpressionPartition
+	CapabilityOrderBy
+	CapabilityAutoIncrement
+	CapabilityMapStruct
+	CapabilityGeneratedColumn
+	CapabilityRandomDistribution
+	CapabilityListPartition
+	CapabilityFilesFunction
+	CapabilityIcebergCatalog
+	CapabilityDeltaLakeCatalog
+	CapabilityJDBCCatalog
+	CapabilityBrokerlessLoad
+	CapabilityAsyncMaterializedView
+	CapabilityLongVarchar
+)
+
+type capability struct {
+	since string
+	// assumed without `starrocks_version`: the features the output relied on before the version was configurable,
+	// and the ones required by the statements generated for nothing else (async materialized views, broker-less loads)
+	byDefault bool
+}
+
+var capabilities = map[Capability]capability{
+	CapabilityDecimalV3:             {since: "1.18.0", byDefault: true},
+	CapabilityPrimaryKey:            {since: "1.19.0", byDefault: true},
+	CapabilityArray:                 {since: "1.19.0", byDefault: true},
+	CapabilityJSON:                  {since: "2.2.0", byDefault: true},
+	CapabilityExternalCatalog:       {since: "2.3.0", byDefault: false},
+	CapabilityAutoBuckets:           {since: "2.5.7", byDefault: false},
+	CapabilityExpressionPartition:   {since: "3.0.0", byDefault: false},
+	CapabilityOrderBy:               {since: "3.0.0", byDefault: false},
+	CapabilityAutoIncrement:         {since: "3.0.0", byDefault: false},
+	CapabilityMapStruct:             {since: "3.1.0", byDefault: false},
+	CapabilityGeneratedColumn:       {since: "3.1.0", byDefault: false},
+	CapabilityRandomDistribution:    {since: "3.1.0", byDefault: false},
+	CapabilityListPartition:         {since: "3.1.0", byDefault: false},
+	CapabilityFilesFunction:         {since: "3.1.0", byDefault: false},
+	CapabilityIcebergCatalog:        {since: "2.4.0", byDefault: false},
+	CapabilityDeltaLakeCatalog:      {since: "2.5.0", byDefault: false},
+	CapabilityJDBCCatalog:           {since: "3.0.0", byDefault: false},
+	CapabilityBrokerlessLoad:        {since: "2.5.0", byDefault: true},
+	CapabilityAsyncMaterializedView: {since: "2.4.0", byDefault: true},
+	CapabilityLongVarchar:           {since: "2.1.0", byDefault: false},
+}
+
+// Supports the target starrocks supports the feature
+func (config *Config) Supports(feature Capability) bool {
+	if len(config.StarRocksVersion) == 0 {
+		if feature == CapabilityDecimalV3 {
+			// `use_decimal_v3` is required without the version
+			return config.UseDecimalV3
+		}
+		return capabilities[feature].byDefault
+	}
+	return common.CompareVersion(config.StarRocksVersion, capabilities[feature].since) >= 0
+}
+
+// TargetName the target starrocks in the messages of the features not supported
+func (config *Config) TargetName() string {
+	if len(config.StarRocksVersion) == 0 {
+		return "StarRocks without `[target].starrocks_version`"
+	}
+	return "StarRocks " + config.StarRocksVersion
+}
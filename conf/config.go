@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/golang/glog"
+	funk "github.com/thoas/go-funk"
 	"github.com/unknwon/goconfig"
 )
 
@@ -17,16 +18,22 @@ import (
 type Config struct {
 
 	// database
-	DBHost         string
-	DBPort         int64
-	DBUser         string
-	DBPassword     string
-	DBType         common.DBSourceType
-	DBAuthType     common.DBSourceAuthType
+	DBHost        string
+	DBPort        int64
+	DBUser        string
+	DBPassword    string
+	DBType        common.DBSourceType
+	DBAuthType    common.DBSourceAuthType
+	DBServiceName string
+	// directory of the metadata files of `iceberg` and `delta` tables
+	DBPath         string
 	UseDecimalV3   bool
 	BENum          int64
 	ReplicationNum int64
-	TableRules     []*TableRule
+	SampleRows     int64
+	// version of the target starrocks cluster, empty for the default capabilities
+	StarRocksVersion string
+	TableRules       []*TableRule
 
 	// output
 	OutputDir string
@@ -35,6 +42,60 @@ type Config struct {
 	ConfigPath string
 }
 
+// string_mapping of table rules
+const (
+	StringMappingString  = "string"
+	StringMappingVarchar = "varchar"
+)
+
+// distribution_mode of table rules
+const (
+	DistributionModeHash   = "hash"
+	DistributionModeRandom = "random"
+	DistributionModeAuto   = "auto"
+)
+
+// table_model of table rules
+const (
+	TableModelPrimary   = "primary"
+	TableModelUnique    = "unique"
+	TableModelDuplicate = "duplicate"
+	TableModelAggregate = "aggregate"
+)
+
+// aggregation functions of the value columns of aggregate tables
+var aggregations = []string{"SUM", "MAX", "MIN", "REPLACE", "REPLACE_IF_NOT_NULL", "HLL_UNION", "BITMAP_UNION", "PERCENTILE_UNION"}
+
+// built-in transforms of column rules
+const (
+	TransformHash      = "hash"
+	TransformMask      = "mask"
+	TransformMaskPhone = "mask_phone"
+	TransformMaskEmail = "mask_email"
+)
+
+// ColumnRule overrides of the columns matching the name or the pattern
+type ColumnRule struct {
+	Name      string
+	Pattern   string
+	Type      string
+	FlinkType string
+	Exclude   bool
+	// built-in transforms or flink sql expressions applied before written into starrocks
+	Transform string
+	// aggregation function of the value columns of aggregate tables
+	Agg string
+}
+
+// IsBuiltinTransform hashes or masks into strings
+func (columnRule *ColumnRule) IsBuiltinTransform() bool {
+	switch columnRule.Transform {
+	case TransformHash, TransformMask, TransformMaskPhone, TransformMaskEmail:
+		return true
+	}
+	return false
+}
+
 type TableRule struct {
 	Seq                string
 	DatabasePattern    string
@@ -43,13 +104,52 @@ type TableRule struct {
 	PartitionKey       string
 	Partitions         string
 	DuplicateKeys      string
+	OrderBy            string
 	DistributedBy      string
 	Buckets            int64
+	DistributionMode   string
+	TableModel         string
+	StringMapping      string
 	FromShardingSrc    bool
 	Properties         map[string]string
 	ExternalProperties map[string]string
+	LoadProperties     map[string]string
+	LoadBroker         string
 	FlinkSinkProps     map[string]string
 	FlinkSourceProps   map[string]string
+	ColumnRules        []*ColumnRule
+}
+
+// MatchColumnRule rule of the column name, exact names take precedence over patterns
+func (rule *TableRule) MatchColumnRule(columnName string) *ColumnRule {
+	var matchedColumnRule *ColumnRule
+	for _, columnRule := range rule.ColumnRules {
+		if len(columnRule.Name) > 0 && columnRule.Name == columnName {
+			return columnRule
+		}
+		if matchedColumnRule == nil && len(columnRule.Pattern) > 0 && common.RegMatchString(columnRule.Pattern, columnName) {
+			// first matched pattern
+			matchedColumnRule = columnRule
+		}
+	}
+	return matchedColumnRule
+}
+
+// Aggregation aggregation function of the value column of aggregate tables, empty for the key columns
+func (rule *TableRule) Aggregation(columnName string) string {
+	if rule.TableModel != TableModelAggregate {
+		return ""
+	}
+	if columnRule := rule.MatchColumnRule(columnName); columnRule != nil {
+		return columnRule.Agg
+	}
+	return ""
+}
+
+// ExcludesColumn the column is not migrated
+func (rule *TableRule) ExcludesColumn(columnName string) bool {
+	columnRule := rule.MatchColumnRule(columnName)
+	return columnRule != nil && columnRule.Exclude
 }
 
 // Load configurations
@@ -69,17 +169,30 @@ func (config *Config) readProps() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	if config.DBHost, err = file.GetValue("db", "host"); err != nil {
-		return nil, err
-	}
-	if config.DBPort, err = file.Int64("db", "port"); err != nil {
-		return nil, err
-	}
-	if config.DBUser, err = file.GetValue("db", "user"); err != nil {
+	config.DBType = common.DBSourceMySQL
+	dbType, _ := file.GetValue("db", "type")
+	config.DBType, err = common.ParseDBSourceType(dbType)
+	if err != nil {
 		return nil, err
 	}
-	if config.DBPassword, err = file.GetValue("db", "password"); err != nil {
-		return nil, err
+	if config.DBType == common.DBSourceIceberg || config.DBType == common.DBSourceDelta {
+		// tables are read from the files without connections
+		if config.DBPath, err = file.GetValue("db", "path"); err != nil {
+			return nil, fmt.Errorf("config [db].path not found")
+		}
+	} else {
+		if config.DBHost, err = file.GetValue("db", "host"); err != nil {
+			return nil, err
+		}
+		if config.DBPort, err = file.Int64("db", "port"); err != nil {
+			return nil, err
+		}
+		if config.DBUser, err = file.GetValue("db", "user"); err != nil {
+			return nil, err
+		}
+		if config.DBPassword, err = file.GetValue("db", "password"); err != nil {
+			return nil, err
+		}
 	}
 	if config.OutputDir, err = file.GetValue("other", "output_dir"); err != nil {
 		return nil, err
@@ -87,9 +200,21 @@ func (config *Config) readProps() (*Config, error) {
 	if config.BENum, err = file.Int64("other", "be_num"); err != nil {
 		return nil, err
 	}
-	if config.UseDecimalV3, err = file.Bool("other", "use_decimal_v3"); err != nil {
-		return nil, err
+	config.StarRocksVersion, _ = file.GetValue("target", "starrocks_version")
+	useDecimalV3, err := file.Bool("other", "use_decimal_v3")
+	if len(config.StarRocksVersion) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		config.UseDecimalV3 = useDecimalV3
+	} else {
+		// decided by the version of the target
+		config.UseDecimalV3 = config.Supports(CapabilityDecimalV3)
+		if err == nil && useDecimalV3 != config.UseDecimalV3 {
+			return nil, fmt.Errorf("config [other].use_decimal_v3 = %t conflicts with [target].starrocks_version = %s", useDecimalV3, config.StarRocksVersion)
+		}
 	}
+	config.SampleRows, _ = file.Int64("other", "sample_rows")
 	config.TableRules = []*TableRule{}
 	// parse table rules
 	config.ReplicationNum = int64(3)
@@ -97,13 +222,6 @@ func (config *Config) readProps() (*Config, error) {
 		config.ReplicationNum = config.BENum
 	}
 
-	config.DBType = common.DBSourceMySQL
-	dbType, _ := file.GetValue("db", "type")
-	config.DBType, err = common.ParseDBSourceType(dbType)
-	if err != nil {
-		return nil, err
-	}
-
 	for _, sec := range file.GetSectionList() {
 		if strings.Index(sec, "table-rule.") == 0 {
 			rule := &TableRule{
@@ -112,6 +230,7 @@ func (config *Config) readProps() (*Config, error) {
 				FlinkSourceProps:   map[string]string{},
 				Properties:         map[string]string{},
 				ExternalProperties: map[string]string{},
+				LoadProperties:     map[string]string{},
 			}
 			if rule.DatabasePattern, err = file.GetValue(sec, "database"); err != nil {
 				return nil, fmt.Errorf("config [%s].database not found", sec)
@@ -130,8 +249,23 @@ func (config *Config) readProps() (*Config, error) {
 			rule.PartitionKey, _ = file.GetValue(sec, "partition_key")
 			rule.Partitions, _ = file.GetValue(sec, "partitions")
 			rule.DuplicateKeys, _ = file.GetValue(sec, "duplicate_keys")
+			rule.OrderBy, _ = file.GetValue(sec, "order_by")
 			rule.DistributedBy, _ = file.GetValue(sec, "distributed_by")
 			rule.Buckets, _ = file.Int64(sec, "bucket_num")
+			rule.DistributionMode = file.MustValue(sec, "distribution_mode", DistributionModeHash)
+			if rule.DistributionMode != DistributionModeHash && rule.DistributionMode != DistributionModeRandom && rule.DistributionMode != DistributionModeAuto {
+				return nil, fmt.Errorf("config [%s].distribution_mode should be `%s`, `%s` or `%s`", sec, DistributionModeHash, DistributionModeRandom, DistributionModeAuto)
+			}
+			rule.TableModel, _ = file.GetValue(sec, "table_model")
+			if len(rule.TableModel) > 0 && rule.TableModel != TableModelPrimary && rule.TableModel != TableModelUnique &&
+				rule.TableModel != TableModelDuplicate && rule.TableModel != TableModelAggregate {
+				return nil, fmt.Errorf("config [%s].table_model should be `%s`, `%s`, `%s` or `%s`", sec, TableModelPrimary, TableModelUnique, TableModelDuplicate, TableModelAggregate)
+			}
+			rule.LoadBroker, _ = file.GetValue(sec, "load.broker")
+			rule.StringMapping = file.MustValue(sec, "string_mapping", StringMappingString)
+			if rule.StringMapping != StringMappingString && rule.StringMapping != StringMappingVarchar {
+				return nil, fmt.Errorf("config [%s].string_mapping should be `%s` or `%s`", sec, StringMappingString, StringMappingVarchar)
+			}
 			secKeyVals, err := file.GetSection(sec)
 			if err != nil {
 				return nil, err
@@ -145,6 +279,10 @@ func (config *Config) readProps() (*Config, error) {
 					rule.ExternalProperties[strings.Replace(key, "external.properties.", "", -1)] = val
 					continue
 				}
+				if strings.Index(key, "load.properties.") == 0 {
+					rule.LoadProperties[strings.Replace(key, "load.properties.", "", -1)] = val
+					continue
+				}
 				if strings.Index(key, "flink.starrocks.") == 0 {
 					rule.FlinkSinkProps[strings.Replace(key, "flink.starrocks.", "", -1)] = val
 					continue
@@ -154,12 +292,24 @@ func (config *Config) readProps() (*Config, error) {
 					continue
 				}
 			}
+			if rule.ColumnRules, err = parseColumnRules(file, sec); err != nil {
+				return nil, err
+			}
+			for _, columnRule := range rule.ColumnRules {
+				if len(columnRule.Agg) > 0 && rule.TableModel != TableModelAggregate {
+					return nil, fmt.Errorf("config [%s] aggregation functions of the columns require `table_model = %s`", sec, TableModelAggregate)
+				}
+			}
 			rule.Properties["replication_num"] = strconv.FormatInt(config.ReplicationNum, 10)
 			rule.FromShardingSrc = true
 			config.TableRules = append(config.TableRules, rule)
 		}
 	}
 
+	if config.DBType == common.DBSourceOracle {
+		config.DBServiceName, _ = file.GetValue("db", "service_name")
+	}
+
 	if config.DBType == common.DBSourceHive {
 		config.DBAuthType = common.DBSourceAuthNone
 		dbAuthType, _ := file.GetValue("db", "authentication")
@@ -173,3 +323,63 @@ func (config *Config) readProps() (*Config, error) {
 
 	return config, nil
 }
+
+// parseColumnRules `column.<name>.<attr>` and `column_pattern.<regex>.<attr>` in the order of the config file
+func parseColumnRules(file *goconfig.ConfigFile, sec string) ([]*ColumnRule, error) {
+	columnRules := []*ColumnRule{}
+	columnRuleMap := map[string]*ColumnRule{}
+	for _, key := range file.GetKeyList(sec) {
+		prefix := ""
+		if strings.Index(key, "column.") == 0 {
+			prefix = "column."
+		} else if strings.Index(key, "column_pattern.") == 0 {
+			prefix = "column_pattern."
+		} else {
+			continue
+		}
+		idx := strings.LastIndex(key, ".")
+		if idx <= len(prefix) {
+			return nil, fmt.Errorf("config [%s].%s should be `%s<column>.<attribute>`", sec, key, prefix)
+		}
+		target := key[len(prefix):idx]
+		columnRule, ok := columnRuleMap[prefix+target]
+		if !ok {
+			columnRule = &ColumnRule{}
+			if prefix == "column." {
+				columnRule.Name = target
+			} else {
+				columnRule.Pattern = target
+			}
+			columnRuleMap[prefix+target] = columnRule
+			columnRules = append(columnRules, columnRule)
+		}
+		val, _ := file.GetValue(sec, key)
+		switch key[idx+1:] {
+		case "type":
+			columnRule.Type = val
+			break
+		case "flink_type":
+			columnRule.FlinkType = val
+			break
+		case "transform":
+			columnRule.Transform = val
+			break
+		case "agg":
+			columnRule.Agg = strings.ToUpper(val)
+			if !funk.ContainsString(aggregations, columnRule.Agg) {
+				return nil, fmt.Errorf("config [%s].%s should be one of %s", sec, key, strings.Join(aggregations, ", "))
+			}
+			break
+		case "exclude":
+			exclude, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("config [%s].%s should be a boolean", sec, key)
+			}
+			columnRule.Exclude = exclude
+			break
+		default:
+			return nil, fmt.Errorf("config [%s].%s is not supported", sec, key)
+		}
+	}
+	return columnRules, nil
+}
@@ -1,13 +1,19 @@
 package convert
 
 import (
+	"fmt"
+	"net/url"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
 	"starrocks-migrate-tool/source"
+	"strings"
 
 	funk "github.com/thoas/go-funk"
 )
 
+// value of the hive partitions of null values
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
 // Converter Converter interface
 type IConverter interface {
 	Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IConverter
@@ -15,12 +21,45 @@ type IConverter interface {
 	ResultFilePrefix() string
 }
 
+// IReporter reports named files besides the converted DDL
+type IReporter interface {
+	Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IReporter
+	ToReports() (map[string]string, error)
+}
+
 // Converter service struct
 type Converter struct {
 	config     *conf.Config
 	dbProvider source.IDBSourceProvider
 }
 
+// targetTableName name of the target table, prefixed with the schema if the source combines them
+func (c *Converter) targetTableName(matchedTableRule *conf.TableRule, table *model.Table) string {
+	if !matchedTableRule.FromShardingSrc && !c.dbProvider.CombineSchemaName() {
+		return table.TABLE_NAME
+	}
+	return table.GetSchemaPrefixedTableName()
+}
+
+// randomDistributed tables without keys are distributed randomly if the rule and the target version allow
+func (c *Converter) randomDistributed(matchedTableRule *conf.TableRule, keys []string) bool {
+	return len(keys) == 0 && len(matchedTableRule.DistributedBy) == 0 && (matchedTableRule.DistributionMode == conf.DistributionModeRandom || matchedTableRule.DistributionMode == conf.DistributionModeAuto) &&
+		c.config.Supports(conf.CapabilityRandomDistribution)
+}
+
+// distribution the distribution clause supported by the target version, returns false if the buckets are sized by the cluster
+func (c *Converter) distribution(matchedTableRule *conf.TableRule, keys []string, disKeys string, buckets int64) (string, bool) {
+	clause := fmt.Sprintf("DISTRIBUTED BY HASH(%s)", disKeys)
+	if c.randomDistributed(matchedTableRule, keys) || len(disKeys) == 0 {
+		// none of the columns can be hashed
+		clause = "DISTRIBUTED BY RANDOM"
+	}
+	if matchedTableRule.DistributionMode == conf.DistributionModeAuto && matchedTableRule.Buckets <= 0 && c.config.Supports(conf.CapabilityAutoBuckets) {
+		return clause + "\n", false
+	}
+	return clause + fmt.Sprintf(" BUCKETS %d\n", buckets), true
+}
+
 func (c *Converter) reorderTableColumns(keys []*model.KeyColumnUsage, columns []*model.Column) (newKeyList []string, reorderedColumns []*model.Column) {
 	keyList := []string{}
 	uniqueKeyGroups := map[string][]string{}
@@ -46,14 +85,39 @@ func (c *Converter) reorderTableColumns(keys []*model.KeyColumnUsage, columns []
 		return keyList, columns
 	}
 
-	// reorder tableColumns.Columns
-	uniqCols := funk.Map(keyList, func(key string) *model.Column {
-		return funk.Find(columns, func(col *model.Column) bool {
+	return keyList, c.keysFirst(keyList, columns)
+}
+
+// hivePartitionSpec names and values of `dt=2021-01-01/hour=01`, values are unescaped by hive
+func hivePartitionSpec(partition string) ([]string, []string) {
+	names := []string{}
+	values := []string{}
+	for _, part := range strings.Split(partition, "/") {
+		idx := strings.Index(part, "=")
+		if idx <= 0 {
+			continue
+		}
+		value, err := url.PathUnescape(part[idx+1:])
+		if err != nil {
+			value = part[idx+1:]
+		}
+		names = append(names, part[:idx])
+		values = append(values, value)
+	}
+	return names, values
+}
+
+// keysFirst reorders the columns with the keys first in the order of the keys
+func (c *Converter) keysFirst(keys []string, columns []*model.Column) []*model.Column {
+	keyCols := []*model.Column{}
+	for _, key := range keys {
+		if col := funk.Find(columns, func(col *model.Column) bool {
 			return col.COLUMN_NAME == key
-		}).(*model.Column)
-	}).([]*model.Column)
-	noneUniqCols := funk.Filter(columns, func(col *model.Column) bool {
-		return !funk.Contains(keyList, col.COLUMN_NAME)
-	}).([]*model.Column)
-	return keyList, append(uniqCols, noneUniqCols...)
+		}); col != nil {
+			keyCols = append(keyCols, col.(*model.Column))
+		}
+	}
+	return append(keyCols, funk.Filter(columns, func(col *model.Column) bool {
+		return !funk.ContainsString(keys, col.COLUMN_NAME)
+	}).([]*model.Column)...)
 }
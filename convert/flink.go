@@ -2,8 +2,10 @@ package convert
 
 import (
 	"fmt"
+	"regexp"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
 	"starrocks-migrate-tool/source"
 	"strconv"
 	"strings"
@@ -11,6 +13,11 @@ import (
 	funk "github.com/thoas/go-funk"
 )
 
+// flinkProjector converts the source values the connectors can not emit as the target type
+type flinkProjector interface {
+	FlinkProjection(column *model.Column) string
+}
+
 type Flink struct {
 	Converter
 }
@@ -31,7 +38,7 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 	catalog := "default_catalog"
 	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
 		mysqlCDCServerId := int64(-1)
-		if c.config.DBType == common.DBSourceMySQL {
+		if c.config.DBType == common.DBSourceMySQL || c.config.DBType == common.DBSourceMariaDB || c.config.DBType == common.DBSourcePolarDBX {
 			if _, ok := matchedTableRule.FlinkSourceProps["server-id"]; ok {
 				mysqlCDCServerId, _ = strconv.ParseInt(matchedTableRule.FlinkSourceProps["server-id"], 10, 64)
 			}
@@ -54,7 +61,9 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 			}
 			srcDDL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`%s`.`%s` (\n", catalog, databaseName, shemaPrefixedTableName+"_src")
 			sinkDDL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`%s`.`%s` (\n", catalog, databaseName, shemaPrefixedTableName+"_sink")
-			columnStrList := []string{}
+			srcColumnStrList := []string{}
+			sinkColumnStrList := []string{}
+			selectList := []string{}
 			primaryKeys := []string{}
 			if len(tableColumns.PrimaryKCU) > 0 {
 				primaryKeys, tableColumns.Columns = c.reorderTableColumns(tableColumns.PrimaryKCU, tableColumns.Columns)
@@ -62,16 +71,35 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 				// unique keys as primary keys
 				primaryKeys, tableColumns.Columns = c.reorderTableColumns(tableColumns.UniqueKCU, tableColumns.Columns)
 			}
-			// 1. concat columns
+			// 1. concat columns, the source tables keep the source types
+			srcRule := *matchedTableRule
+			srcRule.ColumnRules = nil
 			for _, column := range tableColumns.Columns {
-				columnStr, err := c.dbProvider.FormatFlinkColumnDef(tableColumns.Table, column)
+				if column.IsGenerated() {
+					// computed by the target
+					continue
+				}
+				srcColumnStr, err := c.dbProvider.FormatFlinkColumnDef(&srcRule, tableColumns.Table, column)
 				if err != nil {
 					return ddlList, ruledDDLMap, err
 				}
-				columnStrList = append(columnStrList, columnStr)
+				sinkColumnStr, err := c.dbProvider.FormatFlinkColumnDef(matchedTableRule, tableColumns.Table, column)
+				if err != nil {
+					return ddlList, ruledDDLMap, err
+				}
+				srcColumnStrList = append(srcColumnStrList, srcColumnStr)
+				sinkColumnStrList = append(sinkColumnStrList, sinkColumnStr)
+				columnRule := matchedTableRule.MatchColumnRule(column.COLUMN_NAME)
+				projection := c.projection(columnRule, column.COLUMN_NAME, srcColumnStr, sinkColumnStr)
+				if projector, ok := c.dbProvider.(flinkProjector); ok && (columnRule == nil || len(columnRule.Transform) == 0) {
+					if expression := projector.FlinkProjection(column); len(expression) > 0 {
+						projection = fmt.Sprintf("%s AS `%s`", expression, column.COLUMN_NAME)
+					}
+				}
+				selectList = append(selectList, projection)
 			}
-			srcDDL += strings.Join(columnStrList, ",\n")
-			sinkDDL += strings.Join(columnStrList, ",\n")
+			srcDDL += strings.Join(srcColumnStrList, ",\n")
+			sinkDDL += strings.Join(sinkColumnStrList, ",\n")
 
 			// 2. concat keys
 			keysList := ""
@@ -80,15 +108,25 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 					return fmt.Sprintf("`%s`", key)
 				}).([]string), ", ")
 				srcDDL += fmt.Sprintf(",\n  PRIMARY KEY(%s)\n NOT ENFORCED", keysList)
-				sinkDDL += fmt.Sprintf(",\n  PRIMARY KEY(%s)\n NOT ENFORCED", keysList)
+				if matchedTableRule.TableModel != conf.TableModelDuplicate && matchedTableRule.TableModel != conf.TableModelAggregate {
+					// upserts of primary or unique key tables
+					sinkDDL += fmt.Sprintf(",\n  PRIMARY KEY(%s)\n NOT ENFORCED", keysList)
+				}
 			}
 			srcDDL += "\n) with (\n"
 			sinkDDL += "\n) with (\n"
 			// 3. build source properties
-			sourceProps := matchedTableRule.FlinkSourceProps
-			userSetKeys := funk.Keys(sourceProps).([]string)
+			sourceProps := map[string]string{}
+			for k, v := range matchedTableRule.FlinkSourceProps {
+				sourceProps[k] = v
+			}
+			userSetKeys := funk.Keys(matchedTableRule.FlinkSourceProps).([]string)
 			sourceProps["connector"] = c.dbProvider.GetFlinkConnectorName()
-			if c.config.DBType != common.DBSourceTiDB {
+			databaseNameKey, tableNameKey := "database-name", "table-name"
+			if c.config.DBType == common.DBSourceMongoDB {
+				// `hosts` of mongodb-cdc are set by the source
+				databaseNameKey, tableNameKey = "database", "collection"
+			} else if c.config.DBType != common.DBSourceTiDB {
 				sourceProps["hostname"] = c.config.DBHost
 				sourceProps["port"] = strconv.FormatInt(c.config.DBPort, 10)
 				sourceProps["username"] = c.config.DBUser
@@ -100,20 +138,26 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 				mysqlCDCServerId++
 			}
 			if matchedTableRule.FromShardingSrc {
-				sourceProps["database-name"] = c.trimRegex(matchedTableRule.DatabasePattern)
-				sourceProps["table-name"] = c.trimRegex(matchedTableRule.TablePattern)
+				sourceProps[databaseNameKey] = c.trimRegex(matchedTableRule.DatabasePattern)
+				sourceProps[tableNameKey] = c.trimRegex(matchedTableRule.TablePattern)
 				if c.dbProvider.CombineSchemaName() {
 					sourceProps["schema-name"] = c.trimRegex(matchedTableRule.SchemaPattern)
 				}
 			} else {
-				sourceProps["database-name"] = tableColumns.Table.TABLE_CATALOG
-				sourceProps["table-name"] = tableColumns.Table.TABLE_NAME
+				sourceProps[databaseNameKey] = tableColumns.Table.TABLE_CATALOG
+				sourceProps[tableNameKey] = tableColumns.Table.TABLE_NAME
+				if len(tableColumns.Table.ChildTableNames) > 0 {
+					// changes of partitions are captured with their own table names
+					sourceProps[tableNameKey] = fmt.Sprintf("(%s)", strings.Join(funk.Map(append([]string{tableColumns.Table.TABLE_NAME}, tableColumns.Table.ChildTableNames...), func(name string) string {
+						return regexp.QuoteMeta(name)
+					}).([]string), "|"))
+				}
 				if c.dbProvider.CombineSchemaName() {
 					sourceProps["schema-name"] = tableColumns.Table.TABLE_SCHEMA
 				}
 			}
-			if c.dbProvider.GetFlinkSpecialProps(matchedTableRule) != nil {
-				for k, v := range c.dbProvider.GetFlinkSpecialProps(matchedTableRule) {
+			if specialProps := c.dbProvider.GetFlinkSpecialProps(matchedTableRule, tableColumns.Table); specialProps != nil {
+				for k, v := range specialProps {
 					if funk.ContainsString(userSetKeys, k) {
 						continue
 					}
@@ -130,7 +174,10 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 			}
 			ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], srcDDL)
 			// 4. build sink properties
-			sinkProps := matchedTableRule.FlinkSinkProps
+			sinkProps := map[string]string{}
+			for k, v := range matchedTableRule.FlinkSinkProps {
+				sinkProps[k] = v
+			}
 			sinkProps["connector"] = "starrocks"
 			sinkProps["database-name"] = tableColumns.Table.TABLE_CATALOG
 			sinkProps["table-name"] = shemaPrefixedTableName
@@ -145,9 +192,11 @@ func (c *Flink) ToCreateDDL() ([]string, map[string][]string, error) {
 			// 5. add insert into
 			sinkTableName := shemaPrefixedTableName + "_sink"
 			srcTableName := shemaPrefixedTableName + "_src"
-			insertInto := fmt.Sprintf("INSERT INTO `%s`.`%s`.`%s` SELECT * FROM `%s`.`%s`.`%s`", catalog, tableColumns.Table.TABLE_CATALOG, sinkTableName, catalog, tableColumns.Table.TABLE_CATALOG, srcTableName)
+			// only the kept columns are selected
+			projection := strings.Join(selectList, ", ")
+			insertInto := fmt.Sprintf("INSERT INTO `%s`.`%s`.`%s` SELECT %s FROM `%s`.`%s`.`%s`", catalog, tableColumns.Table.TABLE_CATALOG, sinkTableName, projection, catalog, tableColumns.Table.TABLE_CATALOG, srcTableName)
 			if c.config.DBType == common.DBSourceHive {
-				insertInto = fmt.Sprintf("INSERT INTO `%s`.`%s`.`%s` SELECT * FROM `%s`.`%s`", catalog, tableColumns.Table.TABLE_CATALOG, sinkTableName, tableColumns.Table.TABLE_CATALOG, shemaPrefixedTableName)
+				insertInto = fmt.Sprintf("INSERT INTO `%s`.`%s`.`%s` SELECT %s FROM `%s`.`%s`", catalog, tableColumns.Table.TABLE_CATALOG, sinkTableName, projection, tableColumns.Table.TABLE_CATALOG, shemaPrefixedTableName)
 			}
 			ddlList = append(ddlList, insertInto)
 			ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], insertInto)
@@ -165,3 +214,35 @@ func (c *Flink) trimRegex(regex string) string {
 	}
 	return regex
 }
+
+// projection the transformed column, or the column casted to the overridden sink type
+func (c *Flink) projection(columnRule *conf.ColumnRule, columnName, srcColumnStr, sinkColumnStr string) string {
+	quotedName := fmt.Sprintf("`%s`", columnName)
+	if columnRule != nil && len(columnRule.Transform) > 0 {
+		expression := columnRule.Transform
+		switch columnRule.Transform {
+		case conf.TransformHash:
+			expression = fmt.Sprintf("SHA2(CAST(%s AS STRING), 256)", quotedName)
+			break
+		case conf.TransformMask:
+			expression = fmt.Sprintf("REGEXP_REPLACE(CAST(%s AS STRING), '.', '*')", quotedName)
+			break
+		case conf.TransformMaskPhone:
+			// keep the first 3 and the last 4 characters
+			expression = fmt.Sprintf("REGEXP_REPLACE(CAST(%s AS STRING), '^(.{3}).*(.{4})$', '$1****$2')", quotedName)
+			break
+		case conf.TransformMaskEmail:
+			// keep the first character and the domain
+			expression = fmt.Sprintf("REGEXP_REPLACE(CAST(%s AS STRING), '^(.).*(@.*)$', '$1***$2')", quotedName)
+			break
+		}
+		return fmt.Sprintf("%s AS %s", expression, quotedName)
+	}
+	if srcColumnStr != sinkColumnStr {
+		// `  `name` TYPE NULL` -> TYPE
+		sinkType := strings.TrimPrefix(sinkColumnStr, "  "+quotedName+" ")
+		sinkType = strings.TrimSuffix(strings.TrimSuffix(sinkType, " NOT NULL"), " NULL")
+		return fmt.Sprintf("CAST(%s AS %s) AS %s", quotedName, sinkType, quotedName)
+	}
+	return quotedName
+}
@@ -0,0 +1,31 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/conf"
+	"testing"
+)
+
+func TestFlink_projection(t *testing.T) {
+	tests := []struct {
+		name       string
+		columnRule *conf.ColumnRule
+		columnName string
+		srcStr     string
+		sinkStr    string
+		want       string
+	}{
+		{"kept", nil, "id", "  `id` BIGINT NOT NULL", "  `id` BIGINT NOT NULL", "`id`"},
+		{"hash", &conf.ColumnRule{Transform: conf.TransformHash}, "phone", "  `phone` BIGINT NULL", "  `phone` STRING NULL", "SHA2(CAST(`phone` AS STRING), 256) AS `phone`"},
+		{"mask email", &conf.ColumnRule{Transform: conf.TransformMaskEmail}, "email", "  `email` STRING NULL", "  `email` STRING NULL", "REGEXP_REPLACE(CAST(`email` AS STRING), '^(.).*(@.*)$', '$1***$2') AS `email`"},
+		{"expression", &conf.ColumnRule{Transform: "UPPER(name)"}, "name", "  `name` STRING NULL", "  `name` STRING NULL", "UPPER(name) AS `name`"},
+		{"overridden type", &conf.ColumnRule{Type: "DECIMAL(20, 4)"}, "amount", "  `amount` DOUBLE NOT NULL", "  `amount` DECIMAL(20, 4) NOT NULL", "CAST(`amount` AS DECIMAL(20, 4)) AS `amount`"},
+	}
+	c := &Flink{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.projection(tt.columnRule, tt.columnName, tt.srcStr, tt.sinkStr); got != tt.want {
+				t.Errorf("projection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
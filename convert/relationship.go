@@ -0,0 +1,251 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"starrocks-migrate-tool/source"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+)
+
+var colocateGroupNameReg = regexp.MustCompile(`[^0-9a-zA-Z_]`)
+
+// the colocation groups are reported only, the generated ddl of the tables are not colocated
+const colocateGroupsNote = "suggestions only, the generated create table ddl does not apply the distribution keys, the buckets nor `colocate_with` of the groups, " +
+	"alter the tables or edit the ddl before creating the tables to colocate them"
+
+type Relationship struct {
+	Name              string   `json:"name"`
+	Table             string   `json:"table"`
+	Columns           []string `json:"columns"`
+	ReferencedTable   string   `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
+}
+
+type ColocateGroup struct {
+	Name          string              `json:"name"`
+	Buckets       int64               `json:"buckets"`
+	DistributedBy map[string][]string `json:"distributedBy"`
+}
+
+type relationshipTable struct {
+	name         string
+	rule         *conf.TableRule
+	tableColumns *common.TableColumns
+	keys         []string
+	columns      []*model.Column
+}
+
+// StarRocksRelationship reports the foreign keys between the migrated tables
+// and the colocation groups suggested for the tables joined by them
+type StarRocksRelationship struct {
+	StarRocks
+}
+
+func (c *StarRocksRelationship) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IReporter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *StarRocksRelationship) ToReports() (map[string]string, error) {
+	reports := map[string]string{}
+	tables := c.relationshipTables()
+	relationships := c.relationships(tables)
+	if len(relationships) == 0 {
+		return reports, nil
+	}
+	groups := c.colocateGroups(tables, relationships)
+	content, err := json.MarshalIndent(map[string]interface{}{
+		"relationships":           relationships,
+		"suggestedColocateGroups": groups,
+		"note":                    colocateGroupsNote,
+	}, "", "  ")
+	if err != nil {
+		return reports, err
+	}
+	reports["relationships.json"] = string(content) + "\n"
+	reports["relationships.dot"] = c.toDot(relationships, groups)
+	return reports, nil
+}
+
+func (c *StarRocksRelationship) relationshipTables() []*relationshipTable {
+	tables := []*relationshipTable{}
+	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
+		for _, tableColumns := range tableColumnsList {
+			keys, columns := c.tableKeys(matchedTableRule, tableColumns)
+			tables = append(tables, &relationshipTable{
+				name:         fmt.Sprintf("%s.%s", tableColumns.Table.TABLE_CATALOG, c.targetTableName(matchedTableRule, tableColumns.Table)),
+				rule:         matchedTableRule,
+				tableColumns: tableColumns,
+				keys:         keys,
+				columns:      columns,
+			})
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].name < tables[j].name
+	})
+	return tables
+}
+
+func (c *StarRocksRelationship) relationships(tables []*relationshipTable) []*Relationship {
+	relationships := []*Relationship{}
+	for _, table := range tables {
+		foreignKeys := map[string][]*model.KeyColumnUsage{}
+		for _, kcu := range table.tableColumns.ForeignKCU {
+			foreignKeys[kcu.CONSTRAINT_NAME] = append(foreignKeys[kcu.CONSTRAINT_NAME], kcu)
+		}
+		for name, kcuList := range foreignKeys {
+			sort.Slice(kcuList, func(i, j int) bool {
+				return kcuList[i].ORDINAL_POSITION < kcuList[j].ORDINAL_POSITION
+			})
+			first := kcuList[0]
+			referencedTable := funk.Find(tables, func(referenced *relationshipTable) bool {
+				// catalogs of mysql are the schemas
+				return (referenced.tableColumns.Table.TABLE_CATALOG == first.TABLE_CATALOG || referenced.tableColumns.Table.TABLE_CATALOG == first.REFERENCED_TABLE_SCHEMA) &&
+					referenced.tableColumns.Table.TABLE_SCHEMA == first.REFERENCED_TABLE_SCHEMA &&
+					referenced.tableColumns.Table.TABLE_NAME == first.REFERENCED_TABLE_NAME
+			})
+			if referencedTable == nil {
+				// referenced tables not migrated
+				continue
+			}
+			relationships = append(relationships, &Relationship{
+				Name:            name,
+				Table:           table.name,
+				ReferencedTable: referencedTable.(*relationshipTable).name,
+				Columns: funk.Map(kcuList, func(kcu *model.KeyColumnUsage) string {
+					return kcu.COLUMN_NAME
+				}).([]string),
+				ReferencedColumns: funk.Map(kcuList, func(kcu *model.KeyColumnUsage) string {
+					return kcu.REFERENCED_COLUMN_NAME
+				}).([]string),
+			})
+		}
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		if relationships[i].Table != relationships[j].Table {
+			return relationships[i].Table < relationships[j].Table
+		}
+		return relationships[i].Name < relationships[j].Name
+	})
+	return relationships
+}
+
+// colocateGroups suggests the groups of the tables joined by foreign keys on the distribution keys of the referenced tables
+func (c *StarRocksRelationship) colocateGroups(tables []*relationshipTable, relationships []*Relationship) []*ColocateGroup {
+	tableMap := map[string]*relationshipTable{}
+	for _, table := range tables {
+		tableMap[table.name] = table
+	}
+	distributions := map[string][]string{}
+	distributionOf := func(table *relationshipTable) []string {
+		if distribution, ok := distributions[table.name]; ok {
+			return distribution
+		}
+		return c.distributedColumns(table.keys, table.columns, c.tableBuckets(table.rule, table.tableColumns.Table))
+	}
+	parents := map[string]string{}
+	var find func(name string) string
+	find = func(name string) string {
+		if parent, ok := parents[name]; ok && parent != name {
+			parents[name] = find(parent)
+			return parents[name]
+		}
+		return name
+	}
+	for _, relationship := range relationships {
+		table, referencedTable := tableMap[relationship.Table], tableMap[relationship.ReferencedTable]
+		if table == referencedTable || len(table.rule.DistributedBy) > 0 || len(referencedTable.rule.DistributedBy) > 0 ||
+			c.randomDistributed(table.rule, table.keys) || c.randomDistributed(referencedTable.rule, referencedTable.keys) {
+			// colocation requires hash distributions
+			continue
+		}
+		// the child is distributed by the columns referencing the distribution keys of the parent
+		referencedDistribution := distributionOf(referencedTable)
+		if len(referencedDistribution) != len(relationship.ReferencedColumns) {
+			continue
+		}
+		distribution := []string{}
+		for _, key := range referencedDistribution {
+			idx := funk.IndexOfString(relationship.ReferencedColumns, key)
+			if idx < 0 || !c.sameColumnType(table, relationship.Columns[idx], referencedTable, key) {
+				break
+			}
+			distribution = append(distribution, relationship.Columns[idx])
+		}
+		if len(distribution) != len(referencedDistribution) {
+			continue
+		}
+		if len(table.keys) > 0 && len(funk.Subtract(distribution, table.keys).([]string)) > 0 {
+			// distribution keys of primary key tables are a subset of the keys
+			continue
+		}
+		if assigned, ok := distributions[table.name]; ok && !funk.Equal(assigned, distribution) {
+			continue
+		}
+		distributions[table.name] = distribution
+		distributions[referencedTable.name] = referencedDistribution
+		parents[find(table.name)] = find(referencedTable.name)
+	}
+	groupMap := map[string]*ColocateGroup{}
+	groups := []*ColocateGroup{}
+	for _, table := range tables {
+		if _, ok := distributions[table.name]; !ok {
+			continue
+		}
+		root := find(table.name)
+		group, ok := groupMap[root]
+		if !ok {
+			group = &ColocateGroup{
+				Name:          "cg_" + colocateGroupNameReg.ReplaceAllString(root, "_"),
+				DistributedBy: map[string][]string{},
+			}
+			groupMap[root] = group
+			groups = append(groups, group)
+		}
+		group.DistributedBy[table.name] = distributions[table.name]
+		if buckets := c.tableBuckets(table.rule, table.tableColumns.Table); buckets > group.Buckets {
+			group.Buckets = buckets
+		}
+	}
+	return groups
+}
+
+func (c *StarRocksRelationship) sameColumnType(table *relationshipTable, columnName string, referencedTable *relationshipTable, referencedColumnName string) bool {
+	column := funk.Find(table.columns, func(col *model.Column) bool {
+		return col.COLUMN_NAME == columnName
+	})
+	referencedColumn := funk.Find(referencedTable.columns, func(col *model.Column) bool {
+		return col.COLUMN_NAME == referencedColumnName
+	})
+	if column == nil || referencedColumn == nil {
+		return false
+	}
+	return column.(*model.Column).DATA_TYPE == referencedColumn.(*model.Column).DATA_TYPE
+}
+
+func (c *StarRocksRelationship) toDot(relationships []*Relationship, groups []*ColocateGroup) string {
+	dot := "digraph relationships {\n  rankdir=LR;\n  node [shape=box];\n"
+	for _, relationship := range relationships {
+		dot += fmt.Sprintf("  \"%s\" -> \"%s\" [label=\"%s\\n(%s) -> (%s)\"];\n", relationship.Table, relationship.ReferencedTable, relationship.Name,
+			strings.Join(relationship.Columns, ", "), strings.Join(relationship.ReferencedColumns, ", "))
+	}
+	for _, group := range groups {
+		names := funk.Keys(group.DistributedBy).([]string)
+		sort.Strings(names)
+		dot += fmt.Sprintf("  subgraph \"cluster_%s\" {\n    label=\"suggested colocate_with = %s, buckets = %d\";\n", group.Name, group.Name, group.Buckets)
+		for _, name := range names {
+			dot += fmt.Sprintf("    \"%s\";\n", name)
+		}
+		dot += "  }\n"
+	}
+	return dot + "}\n"
+}
@@ -0,0 +1,78 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+	"testing"
+)
+
+type fakeProvider struct {
+	ruledTablesMap map[*conf.TableRule][]*common.TableColumns
+}
+
+func (c *fakeProvider) CombineSchemaName() bool       { return false }
+func (c *fakeProvider) GetFlinkConnectorName() string { return "" }
+func (c *fakeProvider) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	return nil
+}
+func (c *fakeProvider) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns {
+	return c.ruledTablesMap
+}
+func (c *fakeProvider) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	return "", nil
+}
+func (c *fakeProvider) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	return "", nil
+}
+func (c *fakeProvider) ResultConventers() int { return common.ConvertToStarRocks }
+
+func newFakeTable(name string, columns []string, keys []string, foreignKeys map[string][]string) *common.TableColumns {
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "db", TABLE_NAME: name}
+	tableColumns := &common.TableColumns{Table: &model.Table{ModelBase: base}}
+	for _, column := range columns {
+		tableColumns.Columns = append(tableColumns.Columns, &model.Column{ModelBase: base, COLUMN_NAME: column, DATA_TYPE: "bigint", IS_NULLABLE: "NO"})
+	}
+	for idx, key := range keys {
+		tableColumns.PrimaryKCU = append(tableColumns.PrimaryKCU, &model.KeyColumnUsage{ModelBase: base, COLUMN_NAME: key, CONSTRAINT_NAME: "PRIMARY", ORDINAL_POSITION: uint64(idx + 1)})
+	}
+	for constraintName, references := range foreignKeys {
+		tableColumns.ForeignKCU = append(tableColumns.ForeignKCU, &model.KeyColumnUsage{
+			ModelBase: base, COLUMN_NAME: references[0], CONSTRAINT_NAME: constraintName, ORDINAL_POSITION: 1,
+			REFERENCED_TABLE_SCHEMA: "db", REFERENCED_TABLE_NAME: references[1], REFERENCED_COLUMN_NAME: references[2],
+		})
+	}
+	return tableColumns
+}
+
+func TestStarRocksRelationship_ToReports(t *testing.T) {
+	rule := &conf.TableRule{Seq: "1", Properties: map[string]string{}}
+	provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {
+		newFakeTable("users", []string{"id", "name"}, []string{"id"}, nil),
+		// distributed by `user_id` with the duplicate keys
+		newFakeTable("events", []string{"user_id", "ts"}, nil, map[string][]string{"fk_events_user": {"user_id", "users", "id"}}),
+		// `user_id` is not a part of the primary key
+		newFakeTable("orders", []string{"id", "user_id"}, []string{"id"}, map[string][]string{"fk_orders_user": {"user_id", "users", "id"}}),
+		// referenced table is not migrated
+		newFakeTable("logs", []string{"id"}, nil, map[string][]string{"fk_logs_hosts": {"id", "hosts", "id"}}),
+	}}}
+	reports, err := new(StarRocksRelationship).Construct(&conf.Config{BENum: 3}, provider).ToReports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := reports["relationships.json"]
+	for _, want := range []string{`"name": "fk_events_user"`, `"name": "fk_orders_user"`, `"name": "cg_db_users"`, `"db.events": [`, `"suggestedColocateGroups"`, `"note": "suggestions only`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("relationships.json does not contain %s:\n%s", want, report)
+		}
+	}
+	for _, unwanted := range []string{`fk_logs_hosts`, `"db.orders": [`} {
+		if strings.Contains(report, unwanted) {
+			t.Errorf("relationships.json contains %s:\n%s", unwanted, report)
+		}
+	}
+	if !strings.Contains(reports["relationships.dot"], `"db.events" -> "db.users"`) || !strings.Contains(reports["relationships.dot"], `suggested colocate_with = cg_db_users`) {
+		t.Errorf("relationships.dot = %s", reports["relationships.dot"])
+	}
+}
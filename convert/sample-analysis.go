@@ -0,0 +1,232 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"starrocks-migrate-tool/source"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+type sampler interface {
+	Sample(db, schema, table string, limit int) ([]map[string]interface{}, error)
+}
+
+type TypeSuggestion struct {
+	Table         string `json:"table"`
+	Column        string `json:"column"`
+	DataType      string `json:"dataType"`
+	SuggestedType string `json:"suggestedType"`
+	Reason        string `json:"reason"`
+	Samples       int    `json:"samples"`
+}
+
+// SampleAnalysis samples the tables and suggests types refined by the real data
+type SampleAnalysis struct {
+	StarRocks
+}
+
+func (c *SampleAnalysis) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IReporter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *SampleAnalysis) ToReports() (map[string]string, error) {
+	reports := map[string]string{}
+	dbSampler, ok := c.dbProvider.(sampler)
+	if !ok || c.config.SampleRows <= 0 {
+		return reports, nil
+	}
+	suggestions := []*TypeSuggestion{}
+	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
+		if matchedTableRule.FromShardingSrc {
+			// names of the merged tables do not exist in the source
+			continue
+		}
+		for _, tableColumns := range tableColumnsList {
+			table := tableColumns.Table
+			rows, err := dbSampler.Sample(table.TABLE_CATALOG, table.TABLE_SCHEMA, table.TABLE_NAME, int(c.config.SampleRows))
+			if err != nil {
+				glog.Warningf("failed to sample table [%s.%s]: %v", table.TABLE_CATALOG, table.GetSchemaPrefixedTableName(), err)
+				continue
+			}
+			tableName := fmt.Sprintf("%s.%s", table.TABLE_CATALOG, c.targetTableName(matchedTableRule, table))
+			for _, column := range tableColumns.Columns {
+				values := []string{}
+				for _, row := range rows {
+					if value, ok := c.sampleString(row[column.COLUMN_NAME]); ok {
+						values = append(values, value)
+					}
+				}
+				if suggestion := c.suggestType(column, values); suggestion != nil {
+					suggestion.Table = tableName
+					suggestions = append(suggestions, suggestion)
+				}
+			}
+		}
+	}
+	if len(suggestions) == 0 {
+		return reports, nil
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Table < suggestions[j].Table
+	})
+	content, err := json.MarshalIndent(suggestions, "", "  ")
+	if err != nil {
+		return reports, err
+	}
+	reports["sample-analysis.json"] = string(content) + "\n"
+	return reports, nil
+}
+
+// sampleString the textual sampled value, false for nulls and temporal values
+func (c *SampleAnalysis) sampleString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil, time.Time:
+		return "", false
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	}
+	return fmt.Sprint(value), true
+}
+
+// suggestType the refined type of the column if all the non-null samples agree
+func (c *SampleAnalysis) suggestType(column *model.Column, values []string) *TypeSuggestion {
+	if len(values) == 0 || column.IsGenerated() {
+		return nil
+	}
+	dataType := strings.ToLower(column.DATA_TYPE)
+	suggestion := &TypeSuggestion{Column: column.COLUMN_NAME, DataType: column.DATA_TYPE, Samples: len(values)}
+	switch {
+	case strings.Contains(dataType, "json"):
+		return nil
+	case strings.Contains(dataType, "char") || strings.Contains(dataType, "text") || strings.Contains(dataType, "clob") || dataType == "string":
+		if c.all(values, c.isJSON) {
+			suggestion.SuggestedType, suggestion.Reason = "JSON", "all the samples are json objects or arrays"
+		} else if c.all(values, c.isInteger) {
+			suggestion.SuggestedType, suggestion.Reason = "BIGINT", "all the samples are integers"
+		} else if c.all(values, c.isDate) {
+			suggestion.SuggestedType, suggestion.Reason = "DATE", "all the samples are dates"
+		} else if c.all(values, c.isDatetime) {
+			suggestion.SuggestedType, suggestion.Reason = "DATETIME", "all the samples are datetimes"
+		} else if maxBytes := c.maxBytes(values); column.CHARACTER_MAXIMUM_LENGTH <= 0 && maxBytes <= 255 {
+			// unbounded text holding short strings
+			suggestion.SuggestedType, suggestion.Reason = fmt.Sprintf("VARCHAR(%d)", c.roundUp(maxBytes*2)), fmt.Sprintf("the longest sample is %d bytes", maxBytes)
+		}
+		break
+	case dataType == "decimal" || dataType == "numeric" || dataType == "number":
+		precision, scale := int(column.NUMERIC_PRECISION), int(column.NUMERIC_SCALE)
+		maxPrecision := 38
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
+			maxPrecision = 27
+		}
+		if precision == 0 {
+			// unconstrained decimals are converted with the max precision and no scale
+			precision, scale = maxPrecision, 0
+		}
+		integerDigits, sampleScale := c.decimalDigits(values)
+		if integerDigits <= precision-scale && sampleScale <= scale {
+			return nil
+		}
+		if sampleScale < scale {
+			sampleScale = scale
+		}
+		suggestion.SuggestedType = "DOUBLE"
+		if integerDigits+sampleScale <= maxPrecision {
+			suggestedPrecision := integerDigits + sampleScale
+			if suggestedPrecision < precision {
+				suggestedPrecision = precision
+			}
+			suggestion.SuggestedType = fmt.Sprintf("DECIMAL(%d, %d)", suggestedPrecision, sampleScale)
+		}
+		suggestion.Reason = fmt.Sprintf("samples exceed the declared precision (%d, %d)", precision, scale)
+		break
+	}
+	if len(suggestion.SuggestedType) == 0 {
+		return nil
+	}
+	return suggestion
+}
+
+func (c *SampleAnalysis) all(values []string, predicate func(string) bool) bool {
+	for _, value := range values {
+		if !predicate(strings.TrimSpace(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *SampleAnalysis) isJSON(value string) bool {
+	return (strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")) && json.Valid([]byte(value))
+}
+
+// isInteger integers without the leading zeros of the codes nor the plus signs of the phone numbers
+func (c *SampleAnalysis) isInteger(value string) bool {
+	digits := strings.TrimPrefix(value, "-")
+	if strings.HasPrefix(value, "+") || (len(digits) > 1 && strings.HasPrefix(digits, "0")) {
+		return false
+	}
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+func (c *SampleAnalysis) isDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+func (c *SampleAnalysis) isDatetime(value string) bool {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04:05.999999", time.RFC3339, time.RFC3339Nano} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *SampleAnalysis) maxBytes(values []string) int {
+	maxBytes := 0
+	for _, value := range values {
+		if len(value) > maxBytes {
+			maxBytes = len(value)
+		}
+	}
+	return maxBytes
+}
+
+// roundUp the power of 2 not less than n
+func (c *SampleAnalysis) roundUp(n int) int {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// decimalDigits the max digits before and after the decimal point of the samples
+func (c *SampleAnalysis) decimalDigits(values []string) (int, int) {
+	integerDigits, scale := 0, 0
+	for _, value := range values {
+		value = strings.TrimLeft(strings.TrimSpace(value), "+-")
+		parts := strings.SplitN(value, ".", 2)
+		if digits := len(strings.TrimLeft(parts[0], "0")); digits > integerDigits {
+			integerDigits = digits
+		}
+		if len(parts) == 2 {
+			if digits := len(strings.TrimRight(parts[1], "0")); digits > scale {
+				scale = digits
+			}
+		}
+	}
+	return integerDigits, scale
+}
@@ -0,0 +1,38 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestSampleAnalysis_suggestType(t *testing.T) {
+	tests := []struct {
+		name   string
+		column *model.Column
+		values []string
+		want   string
+	}{
+		{"json text", &model.Column{DATA_TYPE: "text"}, []string{`{"a": 1}`, `[1, 2]`}, "JSON"},
+		{"integers in varchar", &model.Column{DATA_TYPE: "varchar", CHARACTER_MAXIMUM_LENGTH: 32}, []string{"12", "-3", "0"}, "BIGINT"},
+		{"codes in varchar", &model.Column{DATA_TYPE: "varchar", CHARACTER_MAXIMUM_LENGTH: 32}, []string{"12", "007"}, ""},
+		{"phone numbers in varchar", &model.Column{DATA_TYPE: "varchar", CHARACTER_MAXIMUM_LENGTH: 32}, []string{"+8613800000000", "12"}, ""},
+		{"dates in varchar", &model.Column{DATA_TYPE: "varchar", CHARACTER_MAXIMUM_LENGTH: 32}, []string{"2022-01-02", "2022-12-31"}, "DATE"},
+		{"short text", &model.Column{DATA_TYPE: "longtext"}, []string{"hello", "world!"}, "VARCHAR(16)"},
+		{"mixed varchar", &model.Column{DATA_TYPE: "varchar", CHARACTER_MAXIMUM_LENGTH: 32}, []string{"12", "abc"}, ""},
+		{"fitting decimal", &model.Column{DATA_TYPE: "decimal", NUMERIC_PRECISION: 10, NUMERIC_SCALE: 2}, []string{"12345678.90"}, ""},
+		{"unconstrained numeric", &model.Column{DATA_TYPE: "numeric"}, []string{"1.25", "100"}, "DECIMAL(38, 2)"},
+	}
+	c := new(SampleAnalysis).Construct(&conf.Config{UseDecimalV3: true}, nil).(*SampleAnalysis)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if suggestion := c.suggestType(tt.column, tt.values); suggestion != nil {
+				got = suggestion.SuggestedType
+			}
+			if got != tt.want {
+				t.Errorf("suggestType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
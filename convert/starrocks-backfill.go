@@ -0,0 +1,154 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"starrocks-migrate-tool/source"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+)
+
+// `VALUES [("2021-01-01"), ("2021-02-01"))` and `VALUES IN ("a", "b")` of the partitions
+var olapRangeReg = regexp.MustCompile(`(?is)^VALUES\s*\[\s*\((.*)\)\s*,\s*\((.*)\)\s*\)$`)
+var olapListReg = regexp.MustCompile(`(?is)^VALUES\s+IN\s*\((.*)\)$`)
+
+// StarRocksBackfill loads the hive, starrocks and doris tables into starrocks partition by partition,
+// reading them by the catalogs or the external tables
+type StarRocksBackfill struct {
+	StarRocks
+}
+
+func (c *StarRocksBackfill) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IConverter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *StarRocksBackfill) ResultFilePrefix() string {
+	return "starrocks-backfill"
+}
+
+func (c *StarRocksBackfill) ToCreateDDL() ([]string, map[string][]string, error) {
+	ddlList := []string{}
+	ruledDDLMap := map[string][]string{}
+	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
+		for _, tableColumns := range tableColumnsList {
+			if _, ok := ruledDDLMap[matchedTableRule.Seq]; !ok {
+				ruledDDLMap[matchedTableRule.Seq] = []string{}
+			}
+			table := tableColumns.Table
+			columns := funk.Filter(tableColumns.Columns, func(col *model.Column) bool {
+				return !col.IsGenerated()
+			}).([]*model.Column)
+			columnNames := strings.Join(funk.Map(columns, func(col *model.Column) string {
+				return fmt.Sprintf("`%s`", col.COLUMN_NAME)
+			}).([]string), ", ")
+			projections := strings.Join(funk.Map(columns, func(col *model.Column) string {
+				return c.projection(matchedTableRule.MatchColumnRule(col.COLUMN_NAME), col.COLUMN_NAME)
+			}).([]string), ", ")
+			insert := fmt.Sprintf("INSERT INTO `%s`.`%s` (%s)\nSELECT %s FROM %s", table.TABLE_CATALOG, c.targetTableName(matchedTableRule, table), columnNames, projections, c.sourceTableName(matchedTableRule, table))
+			conditions := c.hiveConditions(table)
+			if table.Olap != nil {
+				conditions = c.olapConditions(table.Olap)
+			}
+			statements := []string{}
+			if len(conditions) == 0 {
+				statements = append(statements, insert)
+			}
+			for _, condition := range conditions {
+				statements = append(statements, fmt.Sprintf("%s\nWHERE %s", insert, condition))
+			}
+			ddlList = append(ddlList, statements...)
+			ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], statements...)
+		}
+	}
+	return ddlList, ruledDDLMap, nil
+}
+
+// hiveConditions the values of each partition of hive tables
+func (c *StarRocksBackfill) hiveConditions(table *model.Table) []string {
+	partitionConditions := []string{}
+	for _, partition := range table.Partitions {
+		names, values := hivePartitionSpec(partition)
+		conditions := []string{}
+		for idx, name := range names {
+			if values[idx] == hiveDefaultPartition {
+				conditions = append(conditions, fmt.Sprintf("`%s` IS NULL", name))
+				continue
+			}
+			conditions = append(conditions, fmt.Sprintf("`%s` = '%s'", name, strings.Replace(values[idx], "'", "\\'", -1)))
+		}
+		partitionConditions = append(partitionConditions, strings.Join(conditions, " AND "))
+	}
+	return partitionConditions
+}
+
+// olapConditions the range or the values of each partition of starrocks and doris tables partitioned by a column,
+// none if any of them is not representable and the table is copied at once
+func (c *StarRocksBackfill) olapConditions(olap *model.OlapTable) []string {
+	conditions := []string{}
+	if (olap.PartitionType != "RANGE" && olap.PartitionType != "LIST") || len(olap.PartitionColumns) != 1 || strings.Contains(olap.PartitionColumns[0], "(") {
+		return conditions
+	}
+	column := fmt.Sprintf("`%s`", olap.PartitionColumns[0])
+	for _, partition := range olap.Partitions {
+		if matches := olapRangeReg.FindStringSubmatch(partition.Values); olap.PartitionType == "RANGE" && len(matches) > 0 {
+			condition := fmt.Sprintf("%s >= %s", column, matches[1])
+			if !strings.EqualFold(matches[2], "MAXVALUE") {
+				condition += fmt.Sprintf(" AND %s < %s", column, matches[2])
+			}
+			conditions = append(conditions, condition)
+			continue
+		}
+		if matches := olapListReg.FindStringSubmatch(partition.Values); olap.PartitionType == "LIST" && len(matches) > 0 && !strings.Contains(matches[1], "(") {
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, matches[1]))
+			continue
+		}
+		return []string{}
+	}
+	return conditions
+}
+
+// sourceTableName the table of the catalog, or the external table created by StarRocksExternal
+func (c *StarRocksBackfill) sourceTableName(matchedTableRule *conf.TableRule, table *model.Table) string {
+	if c.config.DBType == common.DBSourceStarRocks || c.config.DBType == common.DBSourceDoris {
+		if c.config.Supports(conf.CapabilityJDBCCatalog) {
+			return fmt.Sprintf("`%s`.`%s`.`%s`", jdbcCatalogName, table.TABLE_SCHEMA, table.TABLE_NAME)
+		}
+		return fmt.Sprintf("`mysql_external_%s`.`%s`", table.TABLE_CATALOG, c.targetTableName(matchedTableRule, table))
+	}
+	if c.config.Supports(conf.CapabilityExternalCatalog) {
+		return fmt.Sprintf("`%s`.`%s`.`%s`", hiveCatalogName, table.TABLE_SCHEMA, table.TABLE_NAME)
+	}
+	return fmt.Sprintf("`hive_external_%s`.`%s`", table.TABLE_CATALOG, c.targetTableName(matchedTableRule, table))
+}
+
+// projection the transforms of the column rules in starrocks sql, expressions are expected to be valid in starrocks as well
+func (c *StarRocksBackfill) projection(columnRule *conf.ColumnRule, columnName string) string {
+	quotedName := fmt.Sprintf("`%s`", columnName)
+	if columnRule == nil || len(columnRule.Transform) == 0 {
+		return quotedName
+	}
+	expression := columnRule.Transform
+	switch columnRule.Transform {
+	case conf.TransformHash:
+		expression = fmt.Sprintf("sha2(CAST(%s AS STRING), 256)", quotedName)
+		break
+	case conf.TransformMask:
+		expression = fmt.Sprintf("regexp_replace(CAST(%s AS STRING), '.', '*')", quotedName)
+		break
+	case conf.TransformMaskPhone:
+		// keep the first 3 and the last 4 characters
+		expression = fmt.Sprintf("regexp_replace(CAST(%s AS STRING), '^(.{3}).*(.{4})$', '\\\\1****\\\\2')", quotedName)
+		break
+	case conf.TransformMaskEmail:
+		// keep the first character and the domain
+		expression = fmt.Sprintf("regexp_replace(CAST(%s AS STRING), '^(.).*(@.*)$', '\\\\1***\\\\2')", quotedName)
+		break
+	}
+	return expression
+}
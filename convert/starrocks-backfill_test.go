@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+
+	funk "github.com/thoas/go-funk"
+)
+
+func newHivePartitionedTable() *common.TableColumns {
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "db", TABLE_NAME: "events"}
+	return &common.TableColumns{
+		Table: &model.Table{ModelBase: base, Partitions: []string{"dt=2021-01-02/hour=01", "dt=2021-01-01/hour=00", "dt=2021-01-01/hour=01", "dt=__HIVE_DEFAULT_PARTITION__/hour=00"}},
+		Columns: []*model.Column{
+			{ModelBase: base, COLUMN_NAME: "id", DATA_TYPE: "bigint"},
+			{ModelBase: base, COLUMN_NAME: "email", DATA_TYPE: "string"},
+			{ModelBase: base, COLUMN_NAME: "dt", DATA_TYPE: "date", IsInPartitionKey: true},
+			{ModelBase: base, COLUMN_NAME: "hour", DATA_TYPE: "string", IsInPartitionKey: true},
+		},
+	}
+}
+
+func TestStarRocks_hivePartitions(t *testing.T) {
+	tableColumns := newHivePartitionedTable()
+	starRocks := &StarRocks{Converter{config: &conf.Config{}}}
+	want := "PARTITION BY RANGE (`dt`) (\n" +
+		"  PARTITION p_2021_01_01 VALUES LESS THAN (\"2021-01-02\"),\n" +
+		"  PARTITION p_2021_01_02 VALUES LESS THAN MAXVALUE\n)\n"
+	if got := starRocks.hivePartitions(tableColumns.Table, tableColumns.Columns); got != want {
+		t.Errorf("hivePartitions() = %v, want %v", got, want)
+	}
+	// list partitions of strings since 3.1
+	tableColumns.Columns[2].DATA_TYPE = "string"
+	if got := starRocks.hivePartitions(tableColumns.Table, tableColumns.Columns); got != "" {
+		t.Errorf("hivePartitions() = %v, want none", got)
+	}
+	starRocks.config.StarRocksVersion = "3.1.0"
+	want = "PARTITION BY LIST (`dt`) (\n" +
+		"  PARTITION p_2021_01_01 VALUES IN (\"2021-01-01\"),\n" +
+		"  PARTITION p_2021_01_02 VALUES IN (\"2021-01-02\")\n)\n"
+	if got := starRocks.hivePartitions(tableColumns.Table, tableColumns.Columns); got != want {
+		t.Errorf("hivePartitions() = %v, want %v", got, want)
+	}
+}
+
+func TestStarRocksBackfill_ToCreateDDL(t *testing.T) {
+	rule := &conf.TableRule{Seq: "1", ColumnRules: []*conf.ColumnRule{{Name: "email", Transform: conf.TransformMaskEmail}}}
+	provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {newHivePartitionedTable()}}}
+	ddlList, _, err := new(StarRocksBackfill).Construct(&conf.Config{StarRocksVersion: "3.0.0"}, provider).ToCreateDDL()
+	if err != nil {
+		t.Fatalf("ToCreateDDL() error = %v", err)
+	}
+	insert := "INSERT INTO `db`.`events` (`id`, `email`, `dt`, `hour`)\n" +
+		"SELECT `id`, regexp_replace(CAST(`email` AS STRING), '^(.).*(@.*)$', '\\\\1***\\\\2'), `dt`, `hour` FROM `hive_catalog`.`db`.`events`\n"
+	want := []string{
+		insert + "WHERE `dt` = '2021-01-02' AND `hour` = '01'",
+		insert + "WHERE `dt` = '2021-01-01' AND `hour` = '00'",
+		insert + "WHERE `dt` = '2021-01-01' AND `hour` = '01'",
+		insert + "WHERE `dt` IS NULL AND `hour` = '00'",
+	}
+	if !funk.Equal(ddlList, want) {
+		t.Errorf("ToCreateDDL() = %v, want %v", ddlList, want)
+	}
+}
+
+func TestStarRocksBackfill_olapConditions(t *testing.T) {
+	backfill := new(StarRocksBackfill).Construct(&conf.Config{DBType: common.DBSourceDoris}, &fakeProvider{}).(*StarRocksBackfill)
+	tests := []struct {
+		name string
+		olap *model.OlapTable
+		want []string
+	}{
+		{"range", &model.OlapTable{PartitionType: "RANGE", PartitionColumns: []string{"dt"}, Partitions: []*model.OlapPartition{
+			{Name: "p1", Values: "VALUES [(\"2021-01-01\"), (\"2021-02-01\"))"},
+			{Name: "p2", Values: "VALUES [(\"2021-02-01\"), (MAXVALUE))"},
+		}}, []string{"`dt` >= \"2021-01-01\" AND `dt` < \"2021-02-01\"", "`dt` >= \"2021-02-01\""}},
+		{"list", &model.OlapTable{PartitionType: "LIST", PartitionColumns: []string{"city"}, Partitions: []*model.OlapPartition{
+			{Name: "p1", Values: "VALUES IN (\"beijing\",\"shanghai\")"},
+		}}, []string{"`city` IN (\"beijing\",\"shanghai\")"}},
+		{"multiple columns", &model.OlapTable{PartitionType: "LIST", PartitionColumns: []string{"city", "dt"}, Partitions: []*model.OlapPartition{
+			{Name: "p1", Values: "VALUES IN ((\"beijing\", \"2021-01-01\"))"},
+		}}, []string{}},
+		{"expression", &model.OlapTable{PartitionType: "EXPRESSION", PartitionColumns: []string{"date_trunc('day', `dt`)"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backfill.olapConditions(tt.olap); !funk.Equal(got, tt.want) {
+				t.Errorf("olapConditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/source"
+)
+
+type DefaultWarning struct {
+	Table   string  `json:"table"`
+	Column  string  `json:"column"`
+	Default *string `json:"default"`
+	Extra   string  `json:"extra"`
+	Reason  string  `json:"reason"`
+}
+
+// StarRocksDefaults reports the defaults, the auto increments and the generations not representable in starrocks,
+// recorded on the columns while their starrocks definitions are formatted
+type StarRocksDefaults struct {
+	StarRocks
+}
+
+func (c *StarRocksDefaults) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IReporter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *StarRocksDefaults) ToReports() (map[string]string, error) {
+	reports := map[string]string{}
+	warnings := []*DefaultWarning{}
+	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
+		for _, tableColumns := range tableColumnsList {
+			tableName := fmt.Sprintf("%s.%s", tableColumns.Table.TABLE_CATALOG, c.targetTableName(matchedTableRule, tableColumns.Table))
+			for _, column := range tableColumns.Columns {
+				// formatted again so the report does not depend on the converters run before
+				if _, err := c.dbProvider.FormatStarRocksColumnDef(matchedTableRule, tableColumns.Table, column); err != nil {
+					return reports, err
+				}
+				for _, reason := range column.DefaultWarnings {
+					warnings = append(warnings, &DefaultWarning{
+						Table:   tableName,
+						Column:  column.COLUMN_NAME,
+						Default: column.COLUMN_DEFAULT,
+						Extra:   column.EXTRA,
+						Reason:  reason,
+					})
+				}
+			}
+		}
+	}
+	if len(warnings) == 0 {
+		return reports, nil
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Table < warnings[j].Table
+	})
+	content, err := json.MarshalIndent(warnings, "", "  ")
+	if err != nil {
+		return reports, err
+	}
+	reports["unsupported-defaults.json"] = string(content) + "\n"
+	return reports, nil
+}
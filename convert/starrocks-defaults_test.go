@@ -0,0 +1,30 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"strings"
+	"testing"
+)
+
+func TestStarRocksDefaults_ToReports(t *testing.T) {
+	rule := &conf.TableRule{Seq: "1"}
+	tableColumns := newFakeTable("orders", []string{"id", "updated_at"}, []string{"id"}, nil)
+	tableColumns.Columns[1].AddDefaultWarning("`on update CURRENT_TIMESTAMP` is not supported")
+	tableColumns.Columns[1].AddDefaultWarning("default expression `now()` is not supported")
+	tableColumns.Columns[1].AddDefaultWarning("default expression `now()` is not supported")
+	provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {tableColumns}}}
+	reports, err := new(StarRocksDefaults).Construct(&conf.Config{}, provider).ToReports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := reports["unsupported-defaults.json"]
+	for _, want := range []string{`"table": "db.orders"`, `"column": "updated_at"`, "`on update CURRENT_TIMESTAMP` is not supported", "`now()` is not supported"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("unsupported-defaults.json does not contain %s:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, `"column": "id"`) || strings.Count(report, `"column": "updated_at"`) != 2 {
+		t.Errorf("unsupported-defaults.json = %s", report)
+	}
+}
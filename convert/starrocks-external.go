@@ -3,6 +3,7 @@ package convert
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/source"
@@ -11,6 +12,15 @@ import (
 	funk "github.com/thoas/go-funk"
 )
 
+// names of the hive resource and the catalogs
+const (
+	hiveExternalResourceName = "hive_external_resource"
+	hiveCatalogName          = "hive_catalog"
+	icebergCatalogName       = "iceberg_catalog"
+	deltaCatalogName         = "delta_catalog"
+	jdbcCatalogName          = "jdbc_catalog"
+)
+
 type StarRocksExternal struct {
 	Converter
 }
@@ -29,8 +39,24 @@ func (c *StarRocksExternal) ToCreateDDL() ([]string, map[string][]string, error)
 	ddlList := []string{}
 	ruledDDLMap := map[string][]string{}
 
-	defaultHiveResourceName := "hive_external_resource"
-
+	if c.config.DBType == common.DBSourceIceberg || c.config.DBType == common.DBSourceDelta {
+		// tables of the files are only mapped by the catalogs
+		ddl := c.lakeCatalog()
+		ddlList = append(ddlList, ddl)
+		for matchedTableRule := range c.dbProvider.GetRuledTablesMap() {
+			ruledDDLMap[matchedTableRule.Seq] = []string{ddl}
+		}
+		return ddlList, ruledDDLMap, nil
+	}
+	if (c.config.DBType == common.DBSourceStarRocks || c.config.DBType == common.DBSourceDoris) && c.config.Supports(conf.CapabilityJDBCCatalog) {
+		// tables of the cluster are read by the mysql protocol
+		ddl := c.jdbcCatalog()
+		ddlList = append(ddlList, ddl)
+		for matchedTableRule := range c.dbProvider.GetRuledTablesMap() {
+			ruledDDLMap[matchedTableRule.Seq] = []string{ddl}
+		}
+		return ddlList, ruledDDLMap, nil
+	}
 	if c.config.DBType == common.DBSourceHive {
 		dbProvider := c.dbProvider.(*source.HiveSource)
 		metaURI, err := dbProvider.GetMetaStoreURI()
@@ -38,14 +64,23 @@ func (c *StarRocksExternal) ToCreateDDL() ([]string, map[string][]string, error)
 			return ddlList, ruledDDLMap, errors.New(err.Error())
 		}
 		metaPort := metaURI[strings.LastIndex(metaURI, ":")+1:]
-		ddlList = append(ddlList, fmt.Sprintf("CREATE EXTERNAL RESOURCE \"%s\"\nPROPERTIES (\n  \"type\" = \"hive\",\n  \"hive.metastore.uris\" = \"thrift://%s:%s\"\n)", defaultHiveResourceName, c.config.DBHost, metaPort))
+		if c.config.Supports(conf.CapabilityExternalCatalog) {
+			// tables of the catalog are mapped by starrocks without external tables
+			ddl := fmt.Sprintf("CREATE EXTERNAL CATALOG `%s`\nPROPERTIES (\n  \"type\" = \"hive\",\n  \"hive.metastore.uris\" = \"thrift://%s:%s\"\n)", hiveCatalogName, c.config.DBHost, metaPort)
+			ddlList = append(ddlList, ddl)
+			for matchedTableRule := range c.dbProvider.GetRuledTablesMap() {
+				ruledDDLMap[matchedTableRule.Seq] = []string{ddl}
+			}
+			return ddlList, ruledDDLMap, nil
+		}
+		ddlList = append(ddlList, fmt.Sprintf("CREATE EXTERNAL RESOURCE \"%s\"\nPROPERTIES (\n  \"type\" = \"hive\",\n  \"hive.metastore.uris\" = \"thrift://%s:%s\"\n)", hiveExternalResourceName, c.config.DBHost, metaPort))
 	}
 
 	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
 		for _, tableColumns := range tableColumnsList {
 			engine := ""
 			switch c.config.DBType {
-			case common.DBSourceMySQL, common.DBSourceTiDB:
+			case common.DBSourceMySQL, common.DBSourceTiDB, common.DBSourceMariaDB, common.DBSourceOceanBase, common.DBSourcePolarDBX, common.DBSourceStarRocks, common.DBSourceDoris:
 				engine = "mysql"
 				matchedTableRule.ExternalProperties["host"] = c.config.DBHost
 				matchedTableRule.ExternalProperties["port"] = fmt.Sprintf("%d", c.config.DBPort)
@@ -58,7 +93,7 @@ func (c *StarRocksExternal) ToCreateDDL() ([]string, map[string][]string, error)
 				engine = "hive"
 				matchedTableRule.ExternalProperties["database"] = tableColumns.Table.TABLE_CATALOG
 				matchedTableRule.ExternalProperties["table"] = tableColumns.Table.TABLE_NAME
-				matchedTableRule.ExternalProperties["resource"] = defaultHiveResourceName
+				matchedTableRule.ExternalProperties["resource"] = hiveExternalResourceName
 				break
 			}
 			partitionKey := ""
@@ -86,10 +121,18 @@ func (c *StarRocksExternal) ToCreateDDL() ([]string, map[string][]string, error)
 			_, tableColumns.Columns = c.reorderTableColumns(tableColumns.UniqueKCU, tableColumns.Columns)
 			// 1. concat columns
 			for _, column := range tableColumns.Columns {
-				columnStr, err := c.dbProvider.FormatStarRocksColumnDef(tableColumns.Table, column)
+				columnStr, err := c.dbProvider.FormatStarRocksColumnDef(matchedTableRule, tableColumns.Table, column)
 				if err != nil {
 					return ddlList, ruledDDLMap, err
 				}
+				// values of external tables are generated by the source
+				columnStr = strings.Replace(columnStr, " AUTO_INCREMENT ", " ", 1)
+				if tableColumns.Table.Olap != nil {
+					// and aggregated by the source
+					if agg := tableColumns.Table.Olap.Aggregations[column.COLUMN_NAME]; len(agg) > 0 {
+						columnStr = strings.Replace(columnStr, " "+agg+" ", " ", 1)
+					}
+				}
 				columnStrList = append(columnStrList, columnStr)
 				if column.DATA_TYPE != "date" && column.DATA_TYPE != "datetime" && column.DATA_TYPE != "timestamp" {
 					continue
@@ -116,3 +159,88 @@ func (c *StarRocksExternal) ToCreateDDL() ([]string, map[string][]string, error)
 	}
 	return ddlList, ruledDDLMap, nil
 }
+
+// lakeCatalog the iceberg catalog of the warehouse of the tables, or the delta lake catalog of the metastore registering them,
+// `external.properties.xxx` of the rules are added to the properties
+func (c *StarRocksExternal) lakeCatalog() string {
+	rules := funk.Keys(c.dbProvider.GetRuledTablesMap()).([]*conf.TableRule)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Seq < rules[j].Seq
+	})
+	properties := map[string]string{}
+	name, capability := deltaCatalogName, conf.CapabilityDeltaLakeCatalog
+	review := ""
+	if c.config.DBType == common.DBSourceIceberg {
+		name, capability = icebergCatalogName, conf.CapabilityIcebergCatalog
+		properties["type"] = "iceberg"
+		properties["iceberg.catalog.type"] = "hadoop"
+		warehouses := []string{}
+		for _, rule := range rules {
+			for _, tableColumns := range c.dbProvider.GetRuledTablesMap()[rule] {
+				// tables are located at `<warehouse>/<database>/<table>`
+				warehouse := strings.TrimSuffix(strings.TrimSuffix(tableColumns.Table.Location, "/"), "/"+tableColumns.Table.TABLE_SCHEMA+"/"+tableColumns.Table.TABLE_NAME)
+				if len(tableColumns.Table.Location) > 0 && !funk.ContainsString(warehouses, warehouse) {
+					warehouses = append(warehouses, warehouse)
+				}
+			}
+		}
+		if len(warehouses) > 0 {
+			properties["iceberg.catalog.warehouse"] = warehouses[0]
+		}
+		if len(warehouses) > 1 {
+			review += fmt.Sprintf("-- MANUAL REVIEW REQUIRED: tables are located in the warehouses %s\n", strings.Join(warehouses, ", "))
+		}
+	} else {
+		properties["type"] = "deltalake"
+		properties["hive.metastore.type"] = "hive"
+	}
+	c.mergeExternalProperties(properties)
+	if properties["type"] == "deltalake" && properties["hive.metastore.type"] == "hive" && len(properties["hive.metastore.uris"]) == 0 {
+		review += "-- MANUAL REVIEW REQUIRED: set `external.properties.hive.metastore.uris` of the metastore registering the tables\n"
+	}
+	if !c.config.Supports(capability) {
+		review += fmt.Sprintf("-- MANUAL REVIEW REQUIRED: the catalog is not supported by %s\n", c.config.TargetName())
+	}
+	return c.toCatalogDDL(review, name, properties)
+}
+
+// jdbcCatalog the jdbc catalog of the starrocks or doris cluster, the mysql connector is set by `external.properties.driver_url`
+func (c *StarRocksExternal) jdbcCatalog() string {
+	properties := map[string]string{
+		"type":         "jdbc",
+		"user":         c.config.DBUser,
+		"password":     c.config.DBPassword,
+		"jdbc_uri":     fmt.Sprintf("jdbc:mysql://%s:%d", c.config.DBHost, c.config.DBPort),
+		"driver_class": "com.mysql.cj.jdbc.Driver",
+	}
+	c.mergeExternalProperties(properties)
+	review := ""
+	if len(properties["driver_url"]) == 0 {
+		review += "-- MANUAL REVIEW REQUIRED: set `external.properties.driver_url` of the MySQL connector jar\n"
+	}
+	return c.toCatalogDDL(review, jdbcCatalogName, properties)
+}
+
+// mergeExternalProperties adds `external.properties.xxx` of the rules in the order of the rules
+func (c *StarRocksExternal) mergeExternalProperties(properties map[string]string) {
+	rules := funk.Keys(c.dbProvider.GetRuledTablesMap()).([]*conf.TableRule)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Seq < rules[j].Seq
+	})
+	for _, rule := range rules {
+		for key, val := range rule.ExternalProperties {
+			properties[key] = val
+		}
+	}
+}
+
+// toCatalogDDL CREATE EXTERNAL CATALOG of the properties sorted by the keys, following the review comments
+func (c *StarRocksExternal) toCatalogDDL(review, name string, properties map[string]string) string {
+	keys := funk.Keys(properties).([]string)
+	sort.Strings(keys)
+	propsArr := []string{}
+	for _, key := range keys {
+		propsArr = append(propsArr, fmt.Sprintf("  \"%s\" = \"%s\"", key, properties[key]))
+	}
+	return fmt.Sprintf("%sCREATE EXTERNAL CATALOG `%s`\nPROPERTIES (\n%s\n)", review, name, strings.Join(propsArr, ",\n"))
+}
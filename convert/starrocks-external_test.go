@@ -0,0 +1,49 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestStarRocksExternal_lakeCatalog(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *conf.Config
+		want   string
+	}{
+		{
+			name:   "iceberg",
+			config: &conf.Config{DBType: common.DBSourceIceberg, StarRocksVersion: "3.0.0"},
+			want: "CREATE EXTERNAL CATALOG `iceberg_catalog`\nPROPERTIES (\n" +
+				"  \"aws.s3.region\" = \"us-west-2\",\n" +
+				"  \"iceberg.catalog.type\" = \"hadoop\",\n" +
+				"  \"iceberg.catalog.warehouse\" = \"s3://warehouse\",\n" +
+				"  \"type\" = \"iceberg\"\n)",
+		},
+		{
+			name:   "delta without metastore",
+			config: &conf.Config{DBType: common.DBSourceDelta, StarRocksVersion: "2.4.0"},
+			want: "-- MANUAL REVIEW REQUIRED: set `external.properties.hive.metastore.uris` of the metastore registering the tables\n" +
+				"-- MANUAL REVIEW REQUIRED: the catalog is not supported by StarRocks 2.4.0\n" +
+				"CREATE EXTERNAL CATALOG `delta_catalog`\nPROPERTIES (\n" +
+				"  \"aws.s3.region\" = \"us-west-2\",\n" +
+				"  \"hive.metastore.type\" = \"hive\",\n" +
+				"  \"type\" = \"deltalake\"\n)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "db", TABLE_NAME: "orders"}
+			rule := &conf.TableRule{Seq: "1", ExternalProperties: map[string]string{"aws.s3.region": "us-west-2"}}
+			provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {
+				{Table: &model.Table{ModelBase: base, Location: "s3://warehouse/db/orders"}},
+			}}}
+			external := new(StarRocksExternal).Construct(tt.config, provider).(*StarRocksExternal)
+			if got := external.lakeCatalog(); got != tt.want {
+				t.Errorf("lakeCatalog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package convert
+
+import (
+	"fmt"
+	"sort"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"starrocks-migrate-tool/source"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+)
+
+// file formats of the hive tables
+const (
+	fileFormatORC     = "orc"
+	fileFormatParquet = "parquet"
+	fileFormatCSV     = "csv"
+)
+
+// StarRocksLoad loads the warehouse files of the hive tables partition by partition,
+// by INSERT INTO ... SELECT ... FROM FILES() of orc and parquet files since 3.1, or Broker Load otherwise,
+// Broker Load without the brokers since 2.5
+type StarRocksLoad struct {
+	StarRocksBackfill
+}
+
+func (c *StarRocksLoad) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IConverter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *StarRocksLoad) ResultFilePrefix() string {
+	return "starrocks-load"
+}
+
+func (c *StarRocksLoad) ToCreateDDL() ([]string, map[string][]string, error) {
+	ddlList := []string{}
+	ruledDDLMap := map[string][]string{}
+	for matchedTableRule, tableColumnsList := range c.dbProvider.GetRuledTablesMap() {
+		for _, tableColumns := range tableColumnsList {
+			if _, ok := ruledDDLMap[matchedTableRule.Seq]; !ok {
+				ruledDDLMap[matchedTableRule.Seq] = []string{}
+			}
+			table := tableColumns.Table
+			columns := funk.Filter(tableColumns.Columns, func(col *model.Column) bool {
+				return !col.IsGenerated()
+			}).([]*model.Column)
+			format := c.fileFormat(table)
+			filesSupported := c.config.Supports(conf.CapabilityFilesFunction) && format != fileFormatCSV
+			statements := []string{}
+			if len(format) == 0 || len(table.Location) == 0 {
+				statements = append(statements, c.toReviewComment(table, fmt.Sprintf("files of %s %s at `%s` can not be read by StarRocks", table.InputFormat, table.SerDe, table.Location)))
+			} else if !filesSupported && funk.Find(columns, func(col *model.Column) bool {
+				columnRule := matchedTableRule.MatchColumnRule(col.COLUMN_NAME)
+				return columnRule != nil && len(columnRule.Transform) > 0 && !columnRule.IsBuiltinTransform()
+			}) != nil {
+				statements = append(statements, c.toReviewComment(table, "transforms of flink sql expressions are not applied by Broker Load"))
+			} else if !filesSupported && len(matchedTableRule.LoadBroker) == 0 && !c.config.Supports(conf.CapabilityBrokerlessLoad) {
+				statements = append(statements, c.toReviewComment(table, fmt.Sprintf("brokers are required by Broker Load of %s, set `load.broker` of the rule", c.config.TargetName())))
+			} else {
+				partitions := table.Partitions
+				if len(partitions) == 0 {
+					partitions = []string{""}
+				}
+				for _, partition := range partitions {
+					if filesSupported {
+						statements = append(statements, c.toFilesInsert(matchedTableRule, table, columns, format, partition))
+					} else {
+						statements = append(statements, c.toBrokerLoad(matchedTableRule, table, columns, format, partition))
+					}
+				}
+			}
+			ddlList = append(ddlList, statements...)
+			ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], statements...)
+		}
+	}
+	return ddlList, ruledDDLMap, nil
+}
+
+// fileFormat orc, parquet or csv of the input format and the serde, empty if not supported
+func (c *StarRocksLoad) fileFormat(table *model.Table) string {
+	if strings.Contains(table.InputFormat, "OrcInputFormat") {
+		return fileFormatORC
+	}
+	if strings.Contains(table.InputFormat, "ParquetInputFormat") {
+		return fileFormatParquet
+	}
+	if strings.Contains(table.InputFormat, "TextInputFormat") && strings.Contains(table.SerDe, "LazySimpleSerDe") {
+		return fileFormatCSV
+	}
+	return ""
+}
+
+// filePath files of the partition under the location of the table
+func (c *StarRocksLoad) filePath(table *model.Table, partition string) string {
+	location := strings.TrimSuffix(table.Location, "/")
+	if len(partition) > 0 {
+		location += "/" + partition
+	}
+	return location + "/*"
+}
+
+// toFilesInsert the values of the partition columns are not in the files
+func (c *StarRocksLoad) toFilesInsert(matchedTableRule *conf.TableRule, table *model.Table, columns []*model.Column, format, partition string) string {
+	names, values := hivePartitionSpec(partition)
+	columnNames := []string{}
+	projections := []string{}
+	for _, col := range columns {
+		columnNames = append(columnNames, fmt.Sprintf("`%s`", col.COLUMN_NAME))
+		if idx := funk.IndexOfString(names, col.COLUMN_NAME); idx >= 0 {
+			value := "NULL"
+			if values[idx] != hiveDefaultPartition {
+				value = fmt.Sprintf("'%s'", strings.Replace(values[idx], "'", "\\'", -1))
+			}
+			projections = append(projections, fmt.Sprintf("%s AS `%s`", value, col.COLUMN_NAME))
+			continue
+		}
+		if col.IsInPartitionKey {
+			// partition columns of the tables without partitions
+			projections = append(projections, fmt.Sprintf("NULL AS `%s`", col.COLUMN_NAME))
+			continue
+		}
+		projections = append(projections, c.projection(matchedTableRule.MatchColumnRule(col.COLUMN_NAME), col.COLUMN_NAME))
+	}
+	properties := map[string]string{}
+	for key, val := range matchedTableRule.LoadProperties {
+		properties[key] = val
+	}
+	properties["path"] = c.filePath(table, partition)
+	properties["format"] = format
+	return fmt.Sprintf("INSERT INTO `%s`.`%s` (%s)\nSELECT %s FROM FILES(\n%s\n)", table.TABLE_CATALOG, c.targetTableName(matchedTableRule, table),
+		strings.Join(columnNames, ", "), strings.Join(projections, ", "), c.toProperties(properties))
+}
+
+// toBrokerLoad the values of the partition columns are extracted from the path
+func (c *StarRocksLoad) toBrokerLoad(matchedTableRule *conf.TableRule, table *model.Table, columns []*model.Column, format, partition string) string {
+	targetTableName := c.targetTableName(matchedTableRule, table)
+	label := partitionNameReg.ReplaceAllString(fmt.Sprintf("load_%s_%s", targetTableName, partition), "_")
+	fileColumns := []string{}
+	pathColumns := []string{}
+	derivedColumns := []string{}
+	for _, col := range columns {
+		if col.IsInPartitionKey {
+			pathColumns = append(pathColumns, fmt.Sprintf("`%s`", col.COLUMN_NAME))
+			continue
+		}
+		columnRule := matchedTableRule.MatchColumnRule(col.COLUMN_NAME)
+		if columnRule != nil && columnRule.IsBuiltinTransform() {
+			// transformed from a temporary column
+			fileColumns = append(fileColumns, fmt.Sprintf("`tmp_%s`", col.COLUMN_NAME))
+			derivedColumns = append(derivedColumns, fmt.Sprintf("`%s` = %s", col.COLUMN_NAME, c.projection(columnRule, "tmp_"+col.COLUMN_NAME)))
+			continue
+		}
+		fileColumns = append(fileColumns, fmt.Sprintf("`%s`", col.COLUMN_NAME))
+	}
+	dataDesc := fmt.Sprintf("  DATA INFILE(\"%s\")\n  INTO TABLE `%s`\n", c.filePath(table, partition), targetTableName)
+	if format == fileFormatCSV {
+		delimiter := table.FieldDelimiter
+		if len(delimiter) == 0 {
+			// default delimiter of hive
+			delimiter = "\\x01"
+		}
+		dataDesc += fmt.Sprintf("  COLUMNS TERMINATED BY \"%s\"\n", strings.Replace(delimiter, "\"", "\\\"", -1))
+	}
+	dataDesc += fmt.Sprintf("  FORMAT AS \"%s\"\n  (%s)\n", format, strings.Join(fileColumns, ", "))
+	if len(pathColumns) > 0 {
+		dataDesc += fmt.Sprintf("  COLUMNS FROM PATH AS (%s)\n", strings.Join(pathColumns, ", "))
+	}
+	if len(derivedColumns) > 0 {
+		dataDesc += fmt.Sprintf("  SET (%s)\n", strings.Join(derivedColumns, ", "))
+	}
+	broker := "WITH BROKER"
+	if len(matchedTableRule.LoadBroker) > 0 {
+		broker += fmt.Sprintf(" \"%s\"", matchedTableRule.LoadBroker)
+	}
+	load := fmt.Sprintf("LOAD LABEL `%s`.`%s`\n(\n%s)\n%s", table.TABLE_CATALOG, label, dataDesc, broker)
+	if len(matchedTableRule.LoadProperties) > 0 {
+		load += fmt.Sprintf("\n(\n%s\n)", c.toProperties(matchedTableRule.LoadProperties))
+	}
+	return load
+}
+
+func (c *StarRocksLoad) toProperties(properties map[string]string) string {
+	keys := funk.Keys(properties).([]string)
+	sort.Strings(keys)
+	return strings.Join(funk.Map(keys, func(key string) string {
+		return fmt.Sprintf("  \"%s\" = \"%s\"", key, properties[key])
+	}).([]string), ",\n")
+}
+
+func (c *StarRocksLoad) toReviewComment(table *model.Table, reason string) string {
+	return fmt.Sprintf("-- MANUAL REVIEW REQUIRED: table `%s`.`%s` can not be loaded from the files\n--   %s\n", table.TABLE_CATALOG, table.TABLE_NAME, reason)
+}
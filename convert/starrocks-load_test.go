@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"strings"
+	"testing"
+
+	funk "github.com/thoas/go-funk"
+)
+
+func TestStarRocksLoad_ToCreateDDL(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		broker      string
+		inputFormat string
+		serDe       string
+		want        string
+	}{
+		{
+			name:        "files of parquet",
+			version:     "3.1.0",
+			inputFormat: "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat",
+			serDe:       "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe",
+			want: "INSERT INTO `db`.`events` (`id`, `email`, `dt`, `hour`)\n" +
+				"SELECT `id`, regexp_replace(CAST(`email` AS STRING), '^(.).*(@.*)$', '\\\\1***\\\\2'), '2021-01-01' AS `dt`, '00' AS `hour` FROM FILES(\n" +
+				"  \"aws.s3.region\" = \"us-west-2\",\n" +
+				"  \"format\" = \"parquet\",\n" +
+				"  \"path\" = \"s3://warehouse/events/dt=2021-01-01/hour=00/*\"\n)",
+		},
+		{
+			name:        "broker load of text",
+			version:     "3.1.0",
+			inputFormat: "org.apache.hadoop.mapred.TextInputFormat",
+			serDe:       "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe",
+			want: "LOAD LABEL `db`.`load_events_dt_2021_01_01_hour_00`\n(\n" +
+				"  DATA INFILE(\"s3://warehouse/events/dt=2021-01-01/hour=00/*\")\n" +
+				"  INTO TABLE `events`\n" +
+				"  COLUMNS TERMINATED BY \"\\x01\"\n" +
+				"  FORMAT AS \"csv\"\n" +
+				"  (`id`, `tmp_email`)\n" +
+				"  COLUMNS FROM PATH AS (`dt`, `hour`)\n" +
+				"  SET (`email` = regexp_replace(CAST(`tmp_email` AS STRING), '^(.).*(@.*)$', '\\\\1***\\\\2'))\n)\n" +
+				"WITH BROKER\n(\n  \"aws.s3.region\" = \"us-west-2\"\n)",
+		},
+		{
+			name:        "broker load with the broker",
+			version:     "2.3.0",
+			broker:      "hdfs_broker",
+			inputFormat: "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat",
+			serDe:       "org.apache.hadoop.hive.ql.io.orc.OrcSerde",
+			want: "LOAD LABEL `db`.`load_events_dt_2021_01_01_hour_00`\n(\n" +
+				"  DATA INFILE(\"s3://warehouse/events/dt=2021-01-01/hour=00/*\")\n" +
+				"  INTO TABLE `events`\n" +
+				"  FORMAT AS \"orc\"\n" +
+				"  (`id`, `tmp_email`)\n" +
+				"  COLUMNS FROM PATH AS (`dt`, `hour`)\n" +
+				"  SET (`email` = regexp_replace(CAST(`tmp_email` AS STRING), '^(.).*(@.*)$', '\\\\1***\\\\2'))\n)\n" +
+				"WITH BROKER \"hdfs_broker\"\n(\n  \"aws.s3.region\" = \"us-west-2\"\n)",
+		},
+		{
+			name:        "broker load without the broker",
+			version:     "2.3.0",
+			inputFormat: "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat",
+			serDe:       "org.apache.hadoop.hive.ql.io.orc.OrcSerde",
+			want: "-- MANUAL REVIEW REQUIRED: table `db`.`events` can not be loaded from the files\n" +
+				"--   brokers are required by Broker Load of StarRocks 2.3.0, set `load.broker` of the rule\n",
+		},
+		{
+			name:        "unsupported format",
+			inputFormat: "org.apache.hadoop.hive.ql.io.avro.AvroContainerInputFormat",
+			serDe:       "org.apache.hadoop.hive.serde2.avro.AvroSerDe",
+			want:        "-- MANUAL REVIEW REQUIRED: table `db`.`events` can not be loaded from the files\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tableColumns := newHivePartitionedTable()
+			tableColumns.Table.Location = "s3://warehouse/events/"
+			tableColumns.Table.InputFormat = tt.inputFormat
+			tableColumns.Table.SerDe = tt.serDe
+			rule := &conf.TableRule{Seq: "1", LoadProperties: map[string]string{"aws.s3.region": "us-west-2"}, LoadBroker: tt.broker,
+				ColumnRules: []*conf.ColumnRule{{Name: "email", Transform: conf.TransformMaskEmail}}}
+			provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {tableColumns}}}
+			ddlList, _, err := new(StarRocksLoad).Construct(&conf.Config{StarRocksVersion: tt.version}, provider).ToCreateDDL()
+			if err != nil {
+				t.Fatalf("ToCreateDDL() error = %v", err)
+			}
+			if funk.Find(ddlList, func(ddl string) bool { return strings.HasPrefix(ddl, tt.want) }) == nil {
+				t.Errorf("ToCreateDDL() = %v, want %v", ddlList, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package convert
+
+import (
+	"fmt"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"starrocks-migrate-tool/source"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+)
+
+type StarRocksMaterializedView struct {
+	StarRocks
+}
+
+func (c *StarRocksMaterializedView) Construct(config *conf.Config, dbProvider source.IDBSourceProvider) IConverter {
+	c.dbProvider = dbProvider
+	c.config = config
+	return c
+}
+
+func (c *StarRocksMaterializedView) ResultFilePrefix() string {
+	return "starrocks-mv-create"
+}
+
+func (c *StarRocksMaterializedView) ToCreateDDL() ([]string, map[string][]string, error) {
+	ddlList := []string{}
+	ruledDDLMap := map[string][]string{}
+	dbProvider, ok := c.dbProvider.(*source.ClickHouseSource)
+	if !ok {
+		return ddlList, ruledDDLMap, nil
+	}
+	for matchedTableRule, views := range dbProvider.GetRuledViewsMap() {
+		if _, ok := ruledDDLMap[matchedTableRule.Seq]; !ok {
+			ruledDDLMap[matchedTableRule.Seq] = []string{}
+		}
+		for _, view := range views {
+			ddl := ""
+			reasons := view.ReviewReasons
+			if !c.config.Supports(conf.CapabilityAsyncMaterializedView) {
+				// only the synchronous materialized views of a single table before 2.4
+				reasons = append(append([]string{}, reasons...), fmt.Sprintf("asynchronous materialized views are not supported by %s", c.config.TargetName()))
+			} else if len(c.viewDistributedBy(matchedTableRule, view)) == 0 && !c.config.Supports(conf.CapabilityRandomDistribution) {
+				reasons = append(append([]string{}, reasons...), fmt.Sprintf("none of the output columns can be a distribution key of %s", c.config.TargetName()))
+			}
+			if len(reasons) > 0 {
+				ddl = c.toReviewComment(view, reasons)
+			} else {
+				ddl = c.toCreateViewDDL(matchedTableRule, view)
+			}
+			ddlList = append(ddlList, ddl)
+			ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], ddl)
+		}
+	}
+	return ddlList, ruledDDLMap, nil
+}
+
+func (c *StarRocksMaterializedView) toCreateViewDDL(matchedTableRule *conf.TableRule, view *model.MaterializedView) string {
+	createViewDDL := fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS `%s`.`%s`\n", view.TABLE_CATALOG, view.TABLE_NAME)
+	// 1. concat comment
+	createViewDDL += fmt.Sprintf("COMMENT \"%s\"\n", view.TABLE_COMMENT)
+
+	// 2. concat distributed buckets
+	disKeys := c.viewDistributedBy(matchedTableRule, view)
+	if len(disKeys) > 0 {
+		buckets := c.calculateBuckets(int64(view.DataLength))
+		if matchedTableRule.Buckets > 0 {
+			buckets = matchedTableRule.Buckets
+		}
+		distribution, _ := c.distribution(matchedTableRule, view.SortingKeys, disKeys, buckets)
+		createViewDDL += distribution
+	}
+
+	// 3. concat refresh and query
+	createViewDDL += fmt.Sprintf("REFRESH ASYNC\nAS\n%s", view.Definition)
+	return createViewDDL
+}
+
+// viewDistributedBy the sorting keys of the inner table, or the first keyable output column
+// if the target can not distribute randomly, empty if none
+func (c *StarRocksMaterializedView) viewDistributedBy(matchedTableRule *conf.TableRule, view *model.MaterializedView) string {
+	if len(matchedTableRule.DistributedBy) > 0 {
+		return matchedTableRule.DistributedBy
+	}
+	keys := view.SortingKeys
+	if len(keys) == 0 && !c.config.Supports(conf.CapabilityRandomDistribution) {
+		for _, col := range view.Columns {
+			if c.keyable(col) {
+				keys = []string{col.COLUMN_NAME}
+				break
+			}
+		}
+	}
+	return strings.Join(funk.Map(keys, func(key string) string {
+		return fmt.Sprintf("`%s`", key)
+	}).([]string), ", ")
+}
+
+func (c *StarRocksMaterializedView) toReviewComment(view *model.MaterializedView, reasons []string) string {
+	comment := fmt.Sprintf("-- MANUAL REVIEW REQUIRED: materialized view `%s`.`%s` can not be translated automatically\n", view.TABLE_CATALOG, view.TABLE_NAME)
+	for _, reason := range reasons {
+		comment += fmt.Sprintf("--   %s\n", reason)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(view.CREATE_TABLE_QUERY), "\n") {
+		comment += fmt.Sprintf("-- %s\n", line)
+	}
+	return strings.TrimSuffix(comment, "\n")
+}
@@ -0,0 +1,38 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+	"testing"
+)
+
+func TestStarRocksMaterializedView_toCreateViewDDL(t *testing.T) {
+	columns := []*model.Column{{COLUMN_NAME: "ratio", DATA_TYPE: "Float64"}, {COLUMN_NAME: "d", DATA_TYPE: "Date"}}
+	tests := []struct {
+		name    string
+		version string
+		view    *model.MaterializedView
+		want    string
+	}{
+		{"sorting keys", "3.0.0", &model.MaterializedView{SortingKeys: []string{"k"}, Columns: columns}, "DISTRIBUTED BY HASH(`k`) BUCKETS"},
+		{"random", "3.1.0", &model.MaterializedView{Columns: columns}, "COMMENT \"\"\nREFRESH ASYNC"},
+		{"first keyable column", "3.0.0", &model.MaterializedView{Columns: columns}, "DISTRIBUTED BY HASH(`d`) BUCKETS"},
+		{"not keyable", "3.0.0", &model.MaterializedView{Columns: columns[:1]}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(StarRocksMaterializedView).Construct(&conf.Config{StarRocksVersion: tt.version}, nil).(*StarRocksMaterializedView)
+			rule := &conf.TableRule{}
+			if len(tt.want) == 0 {
+				if disKeys := c.viewDistributedBy(rule, tt.view); len(disKeys) > 0 {
+					t.Errorf("viewDistributedBy() = %v, want none", disKeys)
+				}
+				return
+			}
+			if got := c.toCreateViewDDL(rule, tt.view); !strings.Contains(got, tt.want) {
+				t.Errorf("toCreateViewDDL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package convert
+
+import (
+	"fmt"
+	"sort"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	funk "github.com/thoas/go-funk"
+)
+
+// properties of starrocks kept from the source, the others of doris and older versions are dropped
+var olapProperties = []string{
+	"replication_num", "storage_medium", "storage_cooldown_time", "storage_cooldown_ttl", "colocate_with", "bloom_filter_columns", "compression",
+	"enable_persistent_index", "persistent_index_type", "partition_live_number", "partition_ttl", "write_quorum", "replicated_storage", "fast_schema_evolution",
+	"dynamic_partition.enable", "dynamic_partition.time_unit", "dynamic_partition.time_zone", "dynamic_partition.start", "dynamic_partition.end",
+	"dynamic_partition.prefix", "dynamic_partition.buckets", "dynamic_partition.history_partition_num", "dynamic_partition.start_day_of_week",
+	"dynamic_partition.start_day_of_month", "dynamic_partition.replication_num",
+}
+
+// toOlapCreateDDL CREATE TABLE reproducing the starrocks or doris table, upgraded to the target version
+func (c *StarRocks) toOlapCreateDDL(matchedTableRule *conf.TableRule, tableColumns *common.TableColumns, databaseName, tableName string) (string, error) {
+	table := tableColumns.Table
+	olap := table.Olap
+	reasons := []string{}
+	createTableDDL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`%s` (\n", databaseName, tableName)
+	// 1. concat columns and indexes
+	columnStrList := []string{}
+	for _, column := range tableColumns.Columns {
+		columnStr, err := c.dbProvider.FormatStarRocksColumnDef(matchedTableRule, table, column)
+		if err != nil {
+			return "", err
+		}
+		if len(columnStr) == 0 {
+			continue
+		}
+		columnStrList = append(columnStrList, columnStr)
+	}
+	for _, index := range olap.Indexes {
+		if !strings.Contains(strings.ToUpper(index), "USING BITMAP") {
+			reasons = append(reasons, fmt.Sprintf("index `%s` is not supported", index))
+			continue
+		}
+		columnStrList = append(columnStrList, "  "+index)
+	}
+	createTableDDL += strings.Join(columnStrList, ",\n") + "\n) ENGINE=olap\n"
+
+	// 2. concat keys
+	tableModel := c.olapTableModel(olap, tableColumns.Columns)
+	keys := olap.Keys
+	if len(keys) == 0 {
+		keys = c.duplicateKeys(matchedTableRule, tableColumns.Columns)
+	}
+	if len(keys) == 0 {
+		if review := c.keylessReview(tableModel, databaseName, tableName); len(review) > 0 {
+			return review, nil
+		}
+	}
+	keysList := c.quoteColumns(keys)
+	switch {
+	case len(keys) == 0:
+		// duplicate key tables sorted by the default columns and distributed randomly
+		break
+	case tableModel == conf.TableModelPrimary:
+		createTableDDL += fmt.Sprintf("PRIMARY KEY(%s)\n", keysList)
+		break
+	case tableModel == conf.TableModelUnique:
+		createTableDDL += fmt.Sprintf("UNIQUE KEY(%s)\n", keysList)
+		break
+	case tableModel == conf.TableModelAggregate:
+		createTableDDL += fmt.Sprintf("AGGREGATE KEY(%s)\n", keysList)
+		break
+	default:
+		createTableDDL += fmt.Sprintf("DUPLICATE KEY(%s)\n", keysList)
+		break
+	}
+	if len(olap.Properties["function_column.sequence_col"]) > 0 {
+		reasons = append(reasons, "the sequence column is not supported, rows of the same keys are replaced in the order of the loads")
+	}
+	// 3. concat comment
+	createTableDDL += fmt.Sprintf("COMMENT \"%s\"\n", strings.Replace(table.TABLE_COMMENT, "\"", "\\\"", -1))
+
+	// 4. concat partitions
+	partitions, reason := c.olapPartitions(olap)
+	if len(reason) > 0 {
+		reasons = append(reasons, reason)
+	}
+	createTableDDL += partitions
+
+	// 5. concat distributed buckets
+	disKeys := c.quoteColumns(olap.DistributedBy)
+	if len(matchedTableRule.DistributedBy) > 0 {
+		disKeys = matchedTableRule.DistributedBy
+	}
+	buckets := olap.Buckets
+	if matchedTableRule.Buckets > 0 {
+		buckets = matchedTableRule.Buckets
+	}
+	distribution := fmt.Sprintf("DISTRIBUTED BY HASH(%s)", disKeys)
+	if len(disKeys) == 0 {
+		if c.config.Supports(conf.CapabilityRandomDistribution) {
+			distribution = "DISTRIBUTED BY RANDOM"
+		} else {
+			distribution = fmt.Sprintf("DISTRIBUTED BY HASH(%s)", c.quoteColumns(c.distributedColumns(keys, tableColumns.Columns, c.tableBuckets(matchedTableRule, table))))
+		}
+	}
+	if buckets <= 0 && !c.config.Supports(conf.CapabilityAutoBuckets) {
+		buckets = c.tableBuckets(matchedTableRule, table)
+	}
+	if buckets > 0 {
+		distribution += fmt.Sprintf(" BUCKETS %d", buckets)
+	}
+	createTableDDL += distribution + "\n"
+
+	// 6. concat rollups
+	if len(olap.Rollups) > 0 && tableModel == conf.TableModelPrimary {
+		reasons = append(reasons, "rollups are not supported by primary key tables")
+	} else if len(olap.Rollups) > 0 {
+		rollups := funk.Map(olap.Rollups, func(rollup *model.OlapRollup) string {
+			return fmt.Sprintf("  %s (%s)", rollup.Name, c.quoteColumns(rollup.Columns))
+		}).([]string)
+		createTableDDL += fmt.Sprintf("ROLLUP (\n%s\n)\n", strings.Join(rollups, ",\n"))
+	}
+	orderBy := c.quoteColumns(olap.OrderBy)
+	if len(matchedTableRule.OrderBy) > 0 {
+		orderBy = matchedTableRule.OrderBy
+	}
+	if len(orderBy) > 0 && c.config.Supports(conf.CapabilityOrderBy) {
+		createTableDDL += fmt.Sprintf("ORDER BY(%s)\n", orderBy)
+	}
+
+	// 7. concat properties
+	properties := c.olapProperties(table)
+	for key, val := range matchedTableRule.Properties {
+		properties[key] = val
+	}
+	propKeys := funk.Keys(properties).([]string)
+	sort.Strings(propKeys)
+	propsArr := []string{}
+	for _, key := range propKeys {
+		propsArr = append(propsArr, fmt.Sprintf("  \"%s\" = \"%s\"", key, properties[key]))
+	}
+	createTableDDL += fmt.Sprintf("PROPERTIES (\n%s\n)", strings.Join(propsArr, ",\n"))
+	if len(reasons) == 0 {
+		return createTableDDL, nil
+	}
+	review := fmt.Sprintf("-- MANUAL REVIEW REQUIRED: table `%s`.`%s` is not reproduced faithfully\n", databaseName, tableName)
+	for _, reason := range reasons {
+		review += fmt.Sprintf("--   %s\n", reason)
+	}
+	return review + createTableDDL, nil
+}
+
+// olapTableModel the model of the source, unique key tables merged on write by doris are primary key tables,
+// primary key tables are unique key tables of the targets not supporting them
+func (c *StarRocks) olapTableModel(olap *model.OlapTable, columns []*model.Column) string {
+	switch olap.KeysType {
+	case "PRIMARY":
+		if !c.config.Supports(conf.CapabilityPrimaryKey) {
+			return conf.TableModelUnique
+		}
+		return conf.TableModelPrimary
+	case "UNIQUE":
+		nullableKeys := funk.Filter(columns, func(col *model.Column) bool {
+			return col.IS_NULLABLE == "YES" && funk.ContainsString(olap.Keys, col.COLUMN_NAME)
+		}).([]*model.Column)
+		if olap.Properties["enable_unique_key_merge_on_write"] == "true" && len(nullableKeys) == 0 && c.config.Supports(conf.CapabilityPrimaryKey) {
+			return conf.TableModelPrimary
+		}
+		return conf.TableModelUnique
+	case "AGGREGATE":
+		return conf.TableModelAggregate
+	}
+	return conf.TableModelDuplicate
+}
+
+// olapPartitions the partitions of the source, or the reason why they are not supported by the target
+func (c *StarRocks) olapPartitions(olap *model.OlapTable) (string, string) {
+	expressions := strings.Join(funk.Map(olap.PartitionColumns, func(column string) string {
+		if strings.Contains(column, "(") {
+			return column
+		}
+		return fmt.Sprintf("`%s`", column)
+	}).([]string), ", ")
+	switch olap.PartitionType {
+	case "RANGE", "LIST":
+		if olap.PartitionType == "LIST" && !c.config.Supports(conf.CapabilityListPartition) {
+			return "", fmt.Sprintf("list partitions are not supported by %s", c.config.TargetName())
+		}
+		partitions := funk.Map(olap.Partitions, func(partition *model.OlapPartition) string {
+			return fmt.Sprintf("  PARTITION %s %s", partition.Name, partition.Values)
+		}).([]string)
+		if len(partitions) == 0 {
+			// created by the dynamic partitions
+			return fmt.Sprintf("PARTITION BY %s (%s) ()\n", olap.PartitionType, expressions), ""
+		}
+		return fmt.Sprintf("PARTITION BY %s (%s) (\n%s\n)\n", olap.PartitionType, expressions, strings.Join(partitions, ",\n")), ""
+	case "EXPRESSION":
+		if !c.config.Supports(conf.CapabilityExpressionPartition) {
+			return "", fmt.Sprintf("partitions created by the loaded data are not supported by %s", c.config.TargetName())
+		}
+		if len(olap.PartitionColumns) == 1 && strings.Contains(expressions, "(") {
+			return fmt.Sprintf("PARTITION BY %s\n", expressions), ""
+		}
+		return fmt.Sprintf("PARTITION BY (%s)\n", expressions), ""
+	}
+	return "", ""
+}
+
+// olapProperties the properties of the source supported by starrocks, the replicas of the doris tags are summed up
+func (c *StarRocks) olapProperties(table *model.Table) map[string]string {
+	properties := map[string]string{}
+	for key, val := range table.Olap.Properties {
+		switch key {
+		case "replication_allocation", "dynamic_partition.replication_allocation":
+			// `tag.location.default: 3`
+			replicas := int64(0)
+			for _, allocation := range strings.Split(val, ",") {
+				num, _ := strconv.ParseInt(strings.TrimSpace(allocation[strings.LastIndex(allocation, ":")+1:]), 10, 64)
+				replicas += num
+			}
+			properties[strings.Replace(key, "replication_allocation", "replication_num", 1)] = strconv.FormatInt(replicas, 10)
+			break
+		default:
+			if !funk.ContainsString(olapProperties, key) {
+				glog.Warningf("property [%s] of table [%s.%s] is not supported, dropped", key, table.TABLE_SCHEMA, table.TABLE_NAME)
+				continue
+			}
+			properties[key] = val
+			break
+		}
+	}
+	return properties
+}
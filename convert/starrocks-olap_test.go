@@ -0,0 +1,113 @@
+package convert
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+	"testing"
+)
+
+func newOlapTable(olap *model.OlapTable) *common.TableColumns {
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "db", TABLE_NAME: "orders"}
+	return &common.TableColumns{
+		Table: &model.Table{ModelBase: base, Olap: olap},
+		Columns: []*model.Column{
+			{ModelBase: base, COLUMN_NAME: "dt", DATA_TYPE: "date", COLUMN_TYPE: "DATE", IS_NULLABLE: "NO"},
+			{ModelBase: base, COLUMN_NAME: "id", DATA_TYPE: "bigint", COLUMN_TYPE: "BIGINT", IS_NULLABLE: "NO"},
+			{ModelBase: base, COLUMN_NAME: "amount", DATA_TYPE: "decimal", COLUMN_TYPE: "DECIMAL(38, 4)", IS_NULLABLE: "YES"},
+		},
+	}
+}
+
+func TestStarRocks_toOlapCreateDDL(t *testing.T) {
+	rangePartitions := []*model.OlapPartition{
+		{Name: "p1", Values: "VALUES [(\"2021-01-01\"), (\"2021-02-01\"))"},
+		{Name: "p2", Values: "VALUES [(\"2021-02-01\"), (MAXVALUE))"},
+	}
+	tests := []struct {
+		name    string
+		version string
+		olap    *model.OlapTable
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "aggregate table",
+			version: "3.1.0",
+			olap: &model.OlapTable{
+				KeysType: "AGGREGATE", Keys: []string{"dt", "id"}, Aggregations: map[string]string{"amount": "SUM"},
+				PartitionType: "RANGE", PartitionColumns: []string{"dt"}, Partitions: rangePartitions,
+				DistributedBy: []string{"id"}, Buckets: 8, Indexes: []string{"INDEX idx_id (`id`) USING BITMAP COMMENT ''"},
+				Rollups:    []*model.OlapRollup{{Name: "r_dt", Columns: []string{"dt", "amount"}}},
+				Properties: map[string]string{"storage_format": "DEFAULT", "colocate_with": "g1"},
+			},
+			want: []string{
+				"  INDEX idx_id (`id`) USING BITMAP COMMENT ''\n) ENGINE=olap\nAGGREGATE KEY(`dt`, `id`)\n",
+				"PARTITION BY RANGE (`dt`) (\n  PARTITION p1 VALUES [(\"2021-01-01\"), (\"2021-02-01\")),\n  PARTITION p2 VALUES [(\"2021-02-01\"), (MAXVALUE))\n)\n",
+				"DISTRIBUTED BY HASH(`id`) BUCKETS 8\nROLLUP (\n  r_dt (`dt`, `amount`)\n)\n",
+				"PROPERTIES (\n  \"colocate_with\" = \"g1\",\n  \"replication_num\" = \"3\"\n)",
+			},
+			notWant: []string{"MANUAL REVIEW REQUIRED", "storage_format"},
+		},
+		{
+			name:    "doris unique table merged on write",
+			version: "3.1.0",
+			olap: &model.OlapTable{
+				KeysType: "UNIQUE", Keys: []string{"dt", "id"}, PartitionType: "EXPRESSION", PartitionColumns: []string{"date_trunc('day', `dt`)"},
+				Indexes:    []string{"INDEX idx_id (`id`) USING INVERTED"},
+				Properties: map[string]string{"enable_unique_key_merge_on_write": "true", "replication_allocation": "tag.location.default: 1, tag.location.hot: 2"},
+			},
+			want: []string{
+				"-- MANUAL REVIEW REQUIRED: table `db`.`orders` is not reproduced faithfully\n--   index `INDEX idx_id (`id`) USING INVERTED` is not supported\n",
+				"PRIMARY KEY(`dt`, `id`)\n", "PARTITION BY date_trunc('day', `dt`)\nDISTRIBUTED BY RANDOM\n",
+			},
+		},
+		{
+			name:    "older targets",
+			version: "2.5.0",
+			olap: &model.OlapTable{
+				KeysType: "DUPLICATE", Keys: []string{"dt"}, PartitionType: "LIST", PartitionColumns: []string{"id"},
+				Partitions: []*model.OlapPartition{{Name: "p1", Values: "VALUES IN (\"1\",\"2\")"}},
+			},
+			want: []string{
+				"--   list partitions are not supported by StarRocks 2.5.0\n", "DUPLICATE KEY(`dt`)\nCOMMENT \"\"\nDISTRIBUTED BY HASH(`dt`) BUCKETS ",
+			},
+			notWant: []string{"PARTITION BY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			starRocks := &StarRocks{Converter{config: &conf.Config{StarRocksVersion: tt.version}, dbProvider: &fakeProvider{}}}
+			rule := &conf.TableRule{Properties: map[string]string{"replication_num": "3"}}
+			got, err := starRocks.toOlapCreateDDL(rule, newOlapTable(tt.olap), "db", "orders")
+			if err != nil {
+				t.Fatalf("toOlapCreateDDL() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("toOlapCreateDDL() = %v, want %v", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("toOlapCreateDDL() = %v, not want %v", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestStarRocks_olapProperties(t *testing.T) {
+	starRocks := &StarRocks{Converter{config: &conf.Config{}}}
+	table := &model.Table{Olap: &model.OlapTable{Properties: map[string]string{
+		"replication_allocation":                   "tag.location.default: 1, tag.location.hot: 2",
+		"dynamic_partition.replication_allocation": "tag.location.default: 3",
+		"dynamic_partition.time_unit":              "DAY",
+		"light_schema_change":                      "true",
+	}}}
+	got := starRocks.olapProperties(table)
+	if len(got) != 3 || got["replication_num"] != "3" || got["dynamic_partition.replication_num"] != "3" || got["dynamic_partition.time_unit"] != "DAY" {
+		t.Errorf("olapProperties() = %v", got)
+	}
+}
@@ -3,6 +3,8 @@ package convert
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
@@ -11,9 +13,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang/glog"
 	funk "github.com/thoas/go-funk"
 )
 
+var partitionNameReg = regexp.MustCompile(`[^0-9a-zA-Z_]`)
+
+// dates and timestamps of the sources as `timestamp(6) with time zone` of oracle and `timestamp without time zone` of postgresql
+var temporalTypeReg = regexp.MustCompile(`(?i)^(date|datetime|timestamp)(\(\d+\))?( with(out)?( local)? time zone)?$`)
+var numericTypeReg = regexp.MustCompile(`(?i)\b(u?int(8|16|32|64|128|256)?|tinyint|smallint|mediumint|integer|bigint|largeint|decimal(32|64|128|256)?|numeric|number|float(32|64)?|double|real|money|smallmoney)\b`)
+
 type StarRocks struct {
 	Converter
 }
@@ -48,85 +57,115 @@ func (c *StarRocks) ToCreateDDL() ([]string, map[string][]string, error) {
 			if !funk.ContainsString(ruledDDLMap[matchedTableRule.Seq], ddl) {
 				ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], ddl)
 			}
-			shemaPrefixedTableName := tableColumns.Table.GetSchemaPrefixedTableName()
-			if !matchedTableRule.FromShardingSrc && !c.dbProvider.CombineSchemaName() {
-				shemaPrefixedTableName = tableColumns.Table.TABLE_NAME
+			shemaPrefixedTableName := c.targetTableName(matchedTableRule, tableColumns.Table)
+			if tableColumns.Table.Olap != nil {
+				// tables of starrocks and doris are reproduced by their definitions
+				createTableDDL, err := c.toOlapCreateDDL(matchedTableRule, tableColumns, databaseName, shemaPrefixedTableName)
+				if err != nil {
+					return ddlList, ruledDDLMap, err
+				}
+				ddlList = append(ddlList, createTableDDL)
+				ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], createTableDDL)
+				continue
 			}
 			createTableDDL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`%s` (\n", databaseName, shemaPrefixedTableName)
 			columnStrList := []string{}
-			keys := []string{}
-			if len(tableColumns.PrimaryKCU) > 0 {
-				keys, tableColumns.Columns = c.reorderTableColumns(tableColumns.PrimaryKCU, tableColumns.Columns)
-			} else if len(tableColumns.UniqueKCU) > 0 {
-				// unique keys as primary keys
-				keys, tableColumns.Columns = c.reorderTableColumns(tableColumns.UniqueKCU, tableColumns.Columns)
-			}
+			c.sampleCardinality(matchedTableRule, tableColumns)
+			keys, columns := c.tableKeys(matchedTableRule, tableColumns)
+			tableColumns.Columns = columns
 			// 1. concat columns
 			for _, column := range tableColumns.Columns {
-				columnStr, err := c.dbProvider.FormatStarRocksColumnDef(tableColumns.Table, column)
+				columnStr, err := c.dbProvider.FormatStarRocksColumnDef(matchedTableRule, tableColumns.Table, column)
 				if err != nil {
 					return ddlList, ruledDDLMap, err
 				}
+				if len(columnStr) == 0 {
+					// excluded by the source
+					continue
+				}
 				columnStrList = append(columnStrList, columnStr)
-				if column.DATA_TYPE != "date" && column.DATA_TYPE != "datetime" && column.DATA_TYPE != "timestamp" {
+				if column.IsGenerated() {
+					continue
+				}
+				if !temporalTypeReg.MatchString(column.DATA_TYPE) {
 					continue
 				}
-				if len(partitionKey) == 0 {
+				if len(partitionKey) == 0 || (column.IsInPartitionKey && len(matchedTableRule.PartitionKey) == 0) {
 					partitionKey = column.COLUMN_NAME
 				}
 			}
 			createTableDDL += strings.Join(columnStrList, ",\n") + fmt.Sprintf("\n) ENGINE=olap\n")
 
 			// 2. concat keys
-			keysList := ""
-			if len(keys) > 0 {
-				keysList = strings.Join(funk.Map(keys, func(key string) string {
-					return fmt.Sprintf("`%s`", key)
-				}).([]string), ", ")
-				if c.config.DBType == common.DBSourceHive || (c.config.DBType == common.DBSourceClickHouse && tableColumns.Table.ENGINE == "MergeTree") {
-					createTableDDL += fmt.Sprintf("DUPLICATE KEY(%s)\n", keysList)
-				} else if c.config.DBType == common.DBSourceClickHouse && tableColumns.Table.ENGINE == "SummingMergeTree" {
-					createTableDDL += fmt.Sprintf("AGGREGATE KEY(%s)\n", keysList)
-				} else {
-					createTableDDL += fmt.Sprintf("PRIMARY KEY(%s)\n", keysList)
-				}
-			} else {
-				dupKeys := funk.Map(tableColumns.Columns, func(col *model.Column) string {
-					return fmt.Sprintf("`%s`", col.COLUMN_NAME)
-				}).([]string)
-				if len(dupKeys) > 3 {
-					dupKeys = dupKeys[:3]
-				}
-				keysList = strings.Join(dupKeys, ", ")
-				if len(matchedTableRule.DuplicateKeys) > 0 {
-					createTableDDL += fmt.Sprintf("DUPLICATE KEY(%s)\n", matchedTableRule.DuplicateKeys)
-				} else {
-					createTableDDL += fmt.Sprintf("DUPLICATE KEY(%s)\n", keysList)
+			tableModel := c.tableModel(matchedTableRule, tableColumns.Table, keys)
+			if err := c.validateTableModel(matchedTableRule, keys, tableColumns.Columns); err != nil {
+				return ddlList, ruledDDLMap, fmt.Errorf("Table [%s.%s]: %s", databaseName, shemaPrefixedTableName, err.Error())
+			}
+			sortKeys := keys
+			if len(keys) == 0 {
+				sortKeys = c.duplicateKeys(matchedTableRule, tableColumns.Columns)
+			}
+			if len(sortKeys) == 0 {
+				if review := c.keylessReview(tableModel, databaseName, shemaPrefixedTableName); len(review) > 0 {
+					ddlList = append(ddlList, review)
+					ruledDDLMap[matchedTableRule.Seq] = append(ruledDDLMap[matchedTableRule.Seq], review)
+					continue
 				}
 			}
+			keysList := c.quoteColumns(sortKeys)
+			orderBy := ""
+			switch {
+			case len(sortKeys) == 0:
+				// duplicate key tables sorted by the default columns and distributed randomly
+				break
+			case tableModel == conf.TableModelPrimary:
+				createTableDDL += fmt.Sprintf("PRIMARY KEY(%s)\n", keysList)
+				orderBy = c.orderBy(matchedTableRule, keys, tableColumns.Columns)
+				break
+			case tableModel == conf.TableModelUnique:
+				createTableDDL += fmt.Sprintf("UNIQUE KEY(%s)\n", keysList)
+				break
+			case tableModel == conf.TableModelAggregate:
+				createTableDDL += fmt.Sprintf("AGGREGATE KEY(%s)\n", keysList)
+				break
+			default:
+				createTableDDL += fmt.Sprintf("DUPLICATE KEY(%s)\n", keysList)
+				break
+			}
 			// 3. concat comment
 			createTableDDL += fmt.Sprintf("COMMENT \"%s\"\n", tableColumns.Table.TABLE_COMMENT)
 
 			// 4. concat partitions
-			partitionSize, dynamicProperties, partitions := c.calculatePartitions(int64(tableColumns.Table.DATA_LENGTH), tableColumns.Table.CREATE_TIME)
-			if len(keys) == 0 && len(partitionKey) > 0 && len(partitions) > 0 {
+			_, dynamicProperties, partitions := c.calculatePartitions(int64(tableColumns.Table.DATA_LENGTH), tableColumns.Table.CREATE_TIME)
+			hivePartitions := c.hivePartitions(tableColumns.Table, tableColumns.Columns)
+			if tableModel == conf.TableModelDuplicate && len(matchedTableRule.Partitions) == 0 && len(hivePartitions) > 0 {
+				// partitions of the source
+				createTableDDL += hivePartitions
+				dynamicProperties = map[string]string{}
+			} else if len(keys) == 0 && len(partitionKey) > 0 && len(partitions) > 0 {
 				// only duplicate keys got partitions
 				if len(matchedTableRule.Partitions) > 0 {
-					partitions = matchedTableRule.Partitions
+					createTableDDL += fmt.Sprintf("PARTITION BY RANGE (%s) (\n%s\n)\n", partitionKey, matchedTableRule.Partitions)
+				} else if _, ok := matchedTableRule.Properties["dynamic_partition.time_unit"]; !ok && c.config.Supports(conf.CapabilityExpressionPartition) {
+					// partitions are created by the loaded data
+					createTableDDL += fmt.Sprintf("PARTITION BY date_trunc('%s', %s)\n", strings.ToLower(dynamicProperties["dynamic_partition.time_unit"]), partitionKey)
+					dynamicProperties = map[string]string{}
+				} else {
+					createTableDDL += fmt.Sprintf("PARTITION BY RANGE (%s) (\n%s\n)\n", partitionKey, partitions)
 				}
-				createTableDDL += fmt.Sprintf("PARTITION BY RANGE (%s) (\n%s\n)\n", partitionKey, partitions)
 			}
 
 			// 5. concat distributed buckets
-			disKeys := keysList
+			buckets := c.tableBuckets(matchedTableRule, tableColumns.Table)
+			disKeys := c.quoteColumns(c.distributedColumns(keys, tableColumns.Columns, buckets))
 			if len(matchedTableRule.DistributedBy) > 0 {
 				disKeys = matchedTableRule.DistributedBy
 			}
-			buckets := c.calculateBuckets(partitionSize)
-			if matchedTableRule.Buckets > 0 {
-				buckets = matchedTableRule.Buckets
+			distribution, fixedBuckets := c.distribution(matchedTableRule, keys, disKeys, buckets)
+			createTableDDL += distribution
+			if len(orderBy) > 0 {
+				createTableDDL += fmt.Sprintf("ORDER BY(%s)\n", orderBy)
 			}
-			createTableDDL += fmt.Sprintf("DISTRIBUTED BY HASH(%s) BUCKETS %d\n", disKeys, buckets)
 
 			// 6. concat properties
 			properties := matchedTableRule.Properties
@@ -135,7 +174,9 @@ func (c *StarRocks) ToCreateDDL() ([]string, map[string][]string, error) {
 					for k, v := range dynamicProperties {
 						properties[k] = v
 					}
-					properties["dynamic_partition.buckets"] = strconv.FormatInt(buckets, 10)
+					if fixedBuckets {
+						properties["dynamic_partition.buckets"] = strconv.FormatInt(buckets, 10)
+					}
 				}
 			}
 			propsArr := []string{}
@@ -150,6 +191,351 @@ func (c *StarRocks) ToCreateDDL() ([]string, map[string][]string, error) {
 	return ddlList, ruledDDLMap, nil
 }
 
+// tableKeys returns the keys and the columns reordered with keys first and generated columns last,
+// duplicate keys lead the columns of tables without keys
+func (c *StarRocks) tableKeys(matchedTableRule *conf.TableRule, tableColumns *common.TableColumns) ([]string, []*model.Column) {
+	keys := []string{}
+	columns := tableColumns.Columns
+	switch matchedTableRule.TableModel {
+	case conf.TableModelDuplicate:
+		// keys of the source are not kept
+		break
+	case conf.TableModelAggregate:
+		// columns without aggregation functions are the keys
+		keys = funk.Map(funk.Filter(columns, func(col *model.Column) bool {
+			return !col.IsGenerated() && len(matchedTableRule.Aggregation(col.COLUMN_NAME)) == 0
+		}), func(col *model.Column) string {
+			return col.COLUMN_NAME
+		}).([]string)
+		columns = c.keysFirst(keys, columns)
+		break
+	default:
+		if len(tableColumns.PrimaryKCU) > 0 {
+			keys, columns = c.reorderTableColumns(tableColumns.PrimaryKCU, columns)
+		} else if len(tableColumns.UniqueKCU) > 0 {
+			// unique keys as primary keys
+			keys, columns = c.reorderTableColumns(tableColumns.UniqueKCU, columns)
+		}
+		break
+	}
+	if len(keys) == 0 {
+		columns = c.keysFirst(c.duplicateKeys(matchedTableRule, columns), columns)
+	}
+	// generated columns follow the ordinary ones
+	return keys, append(funk.Filter(columns, func(col *model.Column) bool {
+		return !col.IsGenerated()
+	}).([]*model.Column), funk.Filter(columns, func(col *model.Column) bool {
+		return col.IsGenerated()
+	}).([]*model.Column)...)
+}
+
+// keylessReview the review of the tables none of the columns of can be a key,
+// empty if the duplicate key tables without the keys are distributed randomly by the target
+func (c *StarRocks) keylessReview(tableModel, databaseName, tableName string) string {
+	if tableModel == conf.TableModelDuplicate && c.config.Supports(conf.CapabilityRandomDistribution) {
+		return ""
+	}
+	return fmt.Sprintf("-- MANUAL REVIEW REQUIRED: table `%s`.`%s` can not be created automatically\n--   none of the columns can be a sort key or a distribution key of the %s table of %s\n",
+		databaseName, tableName, tableModel, c.config.TargetName())
+}
+
+// tableModel the model of the rule, or primary key tables for the sources with keys
+func (c *StarRocks) tableModel(matchedTableRule *conf.TableRule, table *model.Table, keys []string) string {
+	tableModel := matchedTableRule.TableModel
+	if len(tableModel) == 0 {
+		if len(keys) == 0 || c.config.DBType == common.DBSourceHive || (c.config.DBType == common.DBSourceClickHouse && table.ENGINE == "MergeTree") {
+			return conf.TableModelDuplicate
+		}
+		if c.config.DBType == common.DBSourceClickHouse && table.ENGINE == "SummingMergeTree" {
+			return conf.TableModelAggregate
+		}
+		tableModel = conf.TableModelPrimary
+	}
+	if tableModel == conf.TableModelPrimary && !c.config.Supports(conf.CapabilityPrimaryKey) {
+		return conf.TableModelUnique
+	}
+	return tableModel
+}
+
+// validateTableModel the keys and the value columns are legal for the model of the rule
+func (c *StarRocks) validateTableModel(matchedTableRule *conf.TableRule, keys []string, columns []*model.Column) error {
+	switch matchedTableRule.TableModel {
+	case conf.TableModelPrimary, conf.TableModelUnique:
+		if len(keys) == 0 {
+			return fmt.Errorf("primary keys or unique indexes are required by `table_model = %s`", matchedTableRule.TableModel)
+		}
+		break
+	case conf.TableModelAggregate:
+		if len(keys) == 0 {
+			return fmt.Errorf("columns without `agg` are the keys of `table_model = %s`, none is found", matchedTableRule.TableModel)
+		}
+		for _, col := range columns {
+			if col.IsGenerated() {
+				continue
+			}
+			agg := matchedTableRule.Aggregation(col.COLUMN_NAME)
+			if len(agg) == 0 && !c.keyable(col) {
+				return fmt.Errorf("column `%s` of %s can not be a key, set its `agg`", col.COLUMN_NAME, col.DATA_TYPE)
+			}
+			if len(agg) > 0 && !c.aggregatable(matchedTableRule.MatchColumnRule(col.COLUMN_NAME), col, agg) {
+				return fmt.Errorf("%s is not applicable to column `%s` of %s", agg, col.COLUMN_NAME, col.DATA_TYPE)
+			}
+		}
+		break
+	case conf.TableModelDuplicate:
+		for _, key := range c.duplicateKeys(matchedTableRule, columns) {
+			if !funk.Some(columns, func(col *model.Column) bool {
+				return col.COLUMN_NAME == key
+			}) {
+				return fmt.Errorf("duplicate key `%s` is not found", key)
+			}
+		}
+		break
+	}
+	return nil
+}
+
+// aggregatable the aggregation function accepts the type of the column, or the type overridden by the column rule
+func (c *StarRocks) aggregatable(columnRule *conf.ColumnRule, col *model.Column, agg string) bool {
+	dataType := strings.ToUpper(col.DATA_TYPE)
+	if len(columnRule.Type) > 0 {
+		dataType = strings.ToUpper(columnRule.Type)
+	}
+	switch agg {
+	case "SUM":
+		return numericTypeReg.MatchString(dataType)
+	case "MAX", "MIN":
+		return !strings.Contains(dataType, "JSON") && !strings.Contains(dataType, "ARRAY") && !strings.Contains(dataType, "MAP") && !strings.Contains(dataType, "STRUCT")
+	case "HLL_UNION":
+		return strings.HasPrefix(dataType, "HLL")
+	case "BITMAP_UNION":
+		return strings.HasPrefix(dataType, "BITMAP")
+	case "PERCENTILE_UNION":
+		return strings.HasPrefix(dataType, "PERCENTILE")
+	}
+	return true
+}
+
+// duplicateKeys the duplicate keys of the rule, or the sort keys
+func (c *StarRocks) duplicateKeys(matchedTableRule *conf.TableRule, columns []*model.Column) []string {
+	if len(matchedTableRule.DuplicateKeys) == 0 {
+		return c.sortKeys(columns)
+	}
+	return funk.Map(strings.Split(matchedTableRule.DuplicateKeys, ","), func(key string) string {
+		return strings.Trim(strings.TrimSpace(key), "`")
+	}).([]string)
+}
+
+// orderBy sort keys of primary key tables differing from the keys, by the rule or the indexes of the source
+func (c *StarRocks) orderBy(matchedTableRule *conf.TableRule, keys []string, columns []*model.Column) string {
+	if !c.config.Supports(conf.CapabilityOrderBy) {
+		return ""
+	}
+	if len(matchedTableRule.OrderBy) > 0 {
+		return matchedTableRule.OrderBy
+	}
+	sortKeyColumns := funk.Filter(columns, func(col *model.Column) bool {
+		return col.SortKeyPosition > 0 && c.keyable(col)
+	}).([]*model.Column)
+	sort.SliceStable(sortKeyColumns, func(i, j int) bool {
+		return sortKeyColumns[i].SortKeyPosition < sortKeyColumns[j].SortKeyPosition
+	})
+	if len(sortKeyColumns) == 0 {
+		sortKeyColumns = funk.Filter(columns, func(col *model.Column) bool {
+			return col.IsInSortingKey && c.keyable(col)
+		}).([]*model.Column)
+	}
+	orderBy := funk.Map(sortKeyColumns, func(col *model.Column) string {
+		return col.COLUMN_NAME
+	}).([]string)
+	if len(orderBy) == 0 || funk.Equal(orderBy, keys) {
+		return ""
+	}
+	return c.quoteColumns(orderBy)
+}
+
+// keyable columns can be sort keys or distribution keys
+func (c *StarRocks) keyable(col *model.Column) bool {
+	if col.IsGenerated() {
+		return false
+	}
+	dataType := strings.ToLower(col.DATA_TYPE)
+	for _, unsupported := range []string{"json", "float", "double", "real", "array", "map", "struct", "tuple"} {
+		if strings.Contains(dataType, unsupported) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKeys the duplicate keys of tables without keys, the columns of the lowest cardinality,
+// or the first keyable columns without statistics, none if no column can be a key
+func (c *StarRocks) sortKeys(columns []*model.Column) []string {
+	candidates := funk.Filter(columns, func(col *model.Column) bool {
+		return c.keyable(col) && col.Cardinality > 1
+	}).([]*model.Column)
+	sortKeys := []string{}
+	if len(candidates) == 0 {
+		for _, col := range columns {
+			if len(sortKeys) == 3 {
+				break
+			}
+			if !c.keyable(col) {
+				// moved behind the keys by keysFirst
+				continue
+			}
+			sortKeys = append(sortKeys, col.COLUMN_NAME)
+		}
+		return sortKeys
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Cardinality < candidates[j].Cardinality
+	})
+	for _, col := range candidates {
+		if len(sortKeys) == 3 {
+			break
+		}
+		sortKeys = append(sortKeys, col.COLUMN_NAME)
+	}
+	return sortKeys
+}
+
+// distributedColumns the keys, or the columns of the highest cardinality of tables without keys,
+// low cardinality columns are combined to avoid skewed tablets
+func (c *StarRocks) distributedColumns(keys []string, columns []*model.Column, buckets int64) []string {
+	if len(keys) > 0 {
+		return keys
+	}
+	candidates := funk.Filter(columns, func(col *model.Column) bool {
+		return c.keyable(col) && col.Cardinality > 0
+	}).([]*model.Column)
+	if len(candidates) == 0 {
+		return c.sortKeys(columns)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Cardinality > candidates[j].Cardinality
+	})
+	distributed := []string{}
+	cardinality := uint64(1)
+	for _, col := range candidates {
+		distributed = append(distributed, col.COLUMN_NAME)
+		cardinality *= col.Cardinality
+		if cardinality >= uint64(buckets)*100 || len(distributed) == 3 {
+			break
+		}
+	}
+	return distributed
+}
+
+// sampleCardinality estimates the cardinality of the columns by the sampled rows for sources without statistics
+func (c *StarRocks) sampleCardinality(matchedTableRule *conf.TableRule, tableColumns *common.TableColumns) {
+	dbSampler, ok := c.dbProvider.(sampler)
+	if !ok || c.config.SampleRows <= 0 || matchedTableRule.FromShardingSrc || funk.Some(tableColumns.Columns, func(col *model.Column) bool {
+		return col.Cardinality > 0
+	}) {
+		return
+	}
+	table := tableColumns.Table
+	rows, err := dbSampler.Sample(table.TABLE_CATALOG, table.TABLE_SCHEMA, table.TABLE_NAME, int(c.config.SampleRows))
+	if err != nil || len(rows) == 0 {
+		glog.Warningf("failed to sample table [%s.%s]: %v", table.TABLE_CATALOG, table.GetSchemaPrefixedTableName(), err)
+		return
+	}
+	for _, col := range tableColumns.Columns {
+		distinct := map[string]bool{}
+		for _, row := range rows {
+			if value, ok := row[col.COLUMN_NAME]; ok && value != nil {
+				distinct[fmt.Sprintf("%v", value)] = true
+			}
+		}
+		col.Cardinality = uint64(len(distinct))
+		if len(distinct) == len(rows) && table.TABLE_ROWS > col.Cardinality {
+			// unique in the samples
+			col.Cardinality = table.TABLE_ROWS
+		}
+	}
+}
+
+func (c *StarRocks) quoteColumns(columns []string) string {
+	return strings.Join(funk.Map(columns, func(column string) string {
+		return fmt.Sprintf("`%s`", column)
+	}).([]string), ", ")
+}
+
+// tableBuckets buckets by the bytes and the rows of a partition
+func (c *StarRocks) tableBuckets(matchedTableRule *conf.TableRule, table *model.Table) int64 {
+	if matchedTableRule.Buckets > 0 {
+		return matchedTableRule.Buckets
+	}
+	partitionSize, _, _ := c.calculatePartitions(int64(table.DATA_LENGTH), table.CREATE_TIME)
+	if len(table.Partitions) > 0 {
+		// size of a partition of the source
+		partitionSize = int64(table.DATA_LENGTH) / int64(len(table.Partitions))
+	}
+	buckets := c.calculateBuckets(partitionSize)
+	partitionRows := int64(table.TABLE_ROWS)
+	if table.DATA_LENGTH > 0 {
+		// rows of a partition in proportion to its size
+		partitionRows = int64(float64(table.TABLE_ROWS) * float64(partitionSize) / float64(table.DATA_LENGTH))
+	}
+	if rowBuckets := c.calculateRowBuckets(partitionRows); rowBuckets > buckets {
+		return rowBuckets
+	}
+	return buckets
+}
+
+// hivePartitions PARTITION BY the first partition column of hive tables, ranges of dates and timestamps
+// till the next partition, or lists of the other values
+func (c *StarRocks) hivePartitions(table *model.Table, columns []*model.Column) string {
+	partitionColumn := funk.Find(columns, func(col *model.Column) bool {
+		return col.IsInPartitionKey
+	})
+	if partitionColumn == nil || len(table.Partitions) == 0 {
+		return ""
+	}
+	column := partitionColumn.(*model.Column)
+	values := []string{}
+	for _, partition := range table.Partitions {
+		names, partitionValues := hivePartitionSpec(partition)
+		if len(names) == 0 || names[0] != column.COLUMN_NAME || partitionValues[0] == hiveDefaultPartition || funk.ContainsString(values, partitionValues[0]) {
+			continue
+		}
+		values = append(values, partitionValues[0])
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	sort.Strings(values)
+	partitionNames := map[string]bool{}
+	partitionName := func(value string) string {
+		name := "p_" + partitionNameReg.ReplaceAllString(value, "_")
+		for idx := 1; partitionNames[name]; idx++ {
+			name = fmt.Sprintf("p_%s_%d", partitionNameReg.ReplaceAllString(value, "_"), idx)
+		}
+		partitionNames[name] = true
+		return name
+	}
+	partitions := []string{}
+	switch column.DATA_TYPE {
+	case "date", "timestamp":
+		for idx, value := range values {
+			upper := "MAXVALUE"
+			if idx+1 < len(values) {
+				upper = fmt.Sprintf("(\"%s\")", values[idx+1])
+			}
+			partitions = append(partitions, fmt.Sprintf("  PARTITION %s VALUES LESS THAN %s", partitionName(value), upper))
+		}
+		return fmt.Sprintf("PARTITION BY RANGE (`%s`) (\n%s\n)\n", column.COLUMN_NAME, strings.Join(partitions, ",\n"))
+	}
+	if !c.config.Supports(conf.CapabilityListPartition) {
+		return ""
+	}
+	for _, value := range values {
+		partitions = append(partitions, fmt.Sprintf("  PARTITION %s VALUES IN (\"%s\")", partitionName(value), strings.Replace(value, "\"", "\\\"", -1)))
+	}
+	return fmt.Sprintf("PARTITION BY LIST (`%s`) (\n%s\n)\n", column.COLUMN_NAME, strings.Join(partitions, ",\n"))
+}
+
 func (c *StarRocks) calculatePartitions(tableSize int64, tableCreatedTime time.Time) (partitionSize int64, dProps map[string]string, partitions string) {
 	dynamicProperties := map[string]string{}
 	setDProps := func(interval string) {
@@ -195,3 +581,14 @@ func (c *StarRocks) calculateBuckets(partitionSize int64) int64 {
 	}
 	return int64(math.Ceil(float64(partitionSize)/float64(common.GIGA_BYTES)/float64(c.config.BENum))) * c.config.BENum
 }
+
+func (c *StarRocks) calculateRowBuckets(partitionRows int64) int64 {
+	if partitionRows < common.ROWS_PER_BUCKET {
+		return 1
+	}
+	buckets := int64(math.Ceil(float64(partitionRows) / float64(common.ROWS_PER_BUCKET)))
+	if buckets < c.config.BENum {
+		return buckets
+	}
+	return int64(math.Ceil(float64(buckets)/float64(c.config.BENum))) * c.config.BENum
+}
@@ -0,0 +1,319 @@
+package convert
+
+import (
+	"fmt"
+	"sort"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+	"testing"
+	"time"
+
+	funk "github.com/thoas/go-funk"
+)
+
+func TestStarRocks_cardinalityKeys(t *testing.T) {
+	newColumns := func(dataType string, cardinalities ...uint64) []*model.Column {
+		columns := []*model.Column{}
+		for idx, cardinality := range cardinalities {
+			columns = append(columns, &model.Column{COLUMN_NAME: string(rune('a' + idx)), DATA_TYPE: dataType, Cardinality: cardinality})
+		}
+		return columns
+	}
+	tests := []struct {
+		name            string
+		columns         []*model.Column
+		buckets         int64
+		wantSortKeys    []string
+		wantDistributed []string
+	}{
+		{
+			name:            "without statistics",
+			columns:         newColumns("int", 0, 0, 0, 0),
+			buckets:         10,
+			wantSortKeys:    []string{"a", "b", "c"},
+			wantDistributed: []string{"a", "b", "c"},
+		},
+		{
+			name:            "high cardinality column",
+			columns:         newColumns("int", 5, 1000000, 20, 1),
+			buckets:         10,
+			wantSortKeys:    []string{"a", "c", "b"},
+			wantDistributed: []string{"b"},
+		},
+		{
+			name:            "combined low cardinality columns",
+			columns:         newColumns("int", 5, 50, 20),
+			buckets:         10,
+			wantSortKeys:    []string{"a", "c", "b"},
+			wantDistributed: []string{"b", "c"},
+		},
+		{
+			name:            "unsupported key types",
+			columns:         append(newColumns("double", 5, 1000000), &model.Column{COLUMN_NAME: "id", DATA_TYPE: "int"}, &model.Column{COLUMN_NAME: "payload", DATA_TYPE: "json"}),
+			buckets:         10,
+			wantSortKeys:    []string{"id"},
+			wantDistributed: []string{"id"},
+		},
+		{
+			name:            "no keyable columns",
+			columns:         newColumns("json", 0, 0),
+			buckets:         10,
+			wantSortKeys:    []string{},
+			wantDistributed: []string{},
+		},
+	}
+	starRocks := &StarRocks{Converter{config: &conf.Config{BENum: 3}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := starRocks.sortKeys(tt.columns); !funk.Equal(got, tt.wantSortKeys) {
+				t.Errorf("sortKeys() = %v, want %v", got, tt.wantSortKeys)
+			}
+			if got := starRocks.distributedColumns(nil, tt.columns, tt.buckets); !funk.Equal(got, tt.wantDistributed) {
+				t.Errorf("distributedColumns() = %v, want %v", got, tt.wantDistributed)
+			}
+		})
+	}
+}
+
+func TestStarRocks_calculateRowBuckets(t *testing.T) {
+	starRocks := &StarRocks{Converter{config: &conf.Config{BENum: 3}}}
+	for rows, want := range map[int64]int64{0: 1, 60 * 1000 * 1000: 2, 120 * 1000 * 1000: 3, 160 * 1000 * 1000: 6} {
+		if got := starRocks.calculateRowBuckets(rows); got != want {
+			t.Errorf("calculateRowBuckets(%d) = %d, want %d", rows, got, want)
+		}
+	}
+}
+
+func TestStarRocks_distribution(t *testing.T) {
+	tests := []struct {
+		name             string
+		version          string
+		rule             *conf.TableRule
+		keys             []string
+		want             string
+		wantFixedBuckets bool
+	}{
+		{
+			name:             "hash",
+			version:          "3.2.0",
+			rule:             &conf.TableRule{DistributionMode: conf.DistributionModeHash},
+			want:             "DISTRIBUTED BY HASH(`a`) BUCKETS 6\n",
+			wantFixedBuckets: true,
+		},
+		{
+			name:             "random",
+			version:          "3.1.0",
+			rule:             &conf.TableRule{DistributionMode: conf.DistributionModeRandom},
+			want:             "DISTRIBUTED BY RANDOM BUCKETS 6\n",
+			wantFixedBuckets: true,
+		},
+		{
+			name:             "random with keys",
+			version:          "3.1.0",
+			rule:             &conf.TableRule{DistributionMode: conf.DistributionModeRandom},
+			keys:             []string{"a"},
+			want:             "DISTRIBUTED BY HASH(`a`) BUCKETS 6\n",
+			wantFixedBuckets: true,
+		},
+		{
+			name:             "random before 3.1",
+			version:          "3.0.9",
+			rule:             &conf.TableRule{DistributionMode: conf.DistributionModeRandom},
+			want:             "DISTRIBUTED BY HASH(`a`) BUCKETS 6\n",
+			wantFixedBuckets: true,
+		},
+		{
+			name:    "auto",
+			version: "3.1.0",
+			rule:    &conf.TableRule{DistributionMode: conf.DistributionModeAuto},
+			want:    "DISTRIBUTED BY RANDOM\n",
+		},
+		{
+			name:    "auto buckets with keys",
+			version: "2.5.7",
+			rule:    &conf.TableRule{DistributionMode: conf.DistributionModeAuto},
+			keys:    []string{"a"},
+			want:    "DISTRIBUTED BY HASH(`a`)\n",
+		},
+		{
+			name:             "auto without version",
+			rule:             &conf.TableRule{DistributionMode: conf.DistributionModeAuto},
+			want:             "DISTRIBUTED BY HASH(`a`) BUCKETS 6\n",
+			wantFixedBuckets: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			starRocks := &StarRocks{Converter{config: &conf.Config{BENum: 3, StarRocksVersion: tt.version}}}
+			got, fixedBuckets := starRocks.distribution(tt.rule, tt.keys, "`a`", 6)
+			if got != tt.want || fixedBuckets != tt.wantFixedBuckets {
+				t.Errorf("distribution() = %q, %v, want %q, %v", got, fixedBuckets, tt.want, tt.wantFixedBuckets)
+			}
+		})
+	}
+}
+
+func TestStarRocks_orderBy(t *testing.T) {
+	columns := []*model.Column{
+		{COLUMN_NAME: "id", DATA_TYPE: "bigint"},
+		{COLUMN_NAME: "created_at", DATA_TYPE: "datetime", SortKeyPosition: 1},
+		{COLUMN_NAME: "region", DATA_TYPE: "varchar", SortKeyPosition: 2},
+	}
+	tests := []struct {
+		name    string
+		version string
+		rule    *conf.TableRule
+		keys    []string
+		want    string
+	}{
+		{name: "before 3.0", version: "2.5.0", rule: &conf.TableRule{}, keys: []string{"id"}, want: ""},
+		{name: "clustered index", version: "3.0.0", rule: &conf.TableRule{}, keys: []string{"id"}, want: "`created_at`, `region`"},
+		{name: "same as the keys", version: "3.0.0", rule: &conf.TableRule{}, keys: []string{"created_at", "region"}, want: ""},
+		{name: "rule", version: "3.0.0", rule: &conf.TableRule{OrderBy: "region"}, keys: []string{"id"}, want: "region"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			starRocks := &StarRocks{Converter{config: &conf.Config{StarRocksVersion: tt.version}}}
+			if got := starRocks.orderBy(tt.rule, tt.keys, columns); got != tt.want {
+				t.Errorf("orderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStarRocks_tableModel(t *testing.T) {
+	aggregate := &conf.TableRule{TableModel: conf.TableModelAggregate, ColumnRules: []*conf.ColumnRule{
+		{Name: "amount", Agg: "SUM"},
+		{Name: "visitors", Type: "HLL", Agg: "HLL_UNION"},
+	}}
+	tests := []struct {
+		name     string
+		rule     *conf.TableRule
+		columns  map[string]string
+		keys     []string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:     "aggregate",
+			rule:     aggregate,
+			columns:  map[string]string{"amount": "decimal", "visitors": "varchar", "dt": "date", "site": "varchar"},
+			keys:     []string{"id"},
+			wantKeys: []string{"dt", "site"},
+		},
+		{
+			name:    "aggregate with a float key",
+			rule:    aggregate,
+			columns: map[string]string{"amount": "decimal", "visitors": "varchar", "ratio": "double"},
+			wantErr: true,
+		},
+		{
+			name:    "sum of strings",
+			rule:    aggregate,
+			columns: map[string]string{"amount": "varchar", "visitors": "varchar", "dt": "date"},
+			wantErr: true,
+		},
+		{
+			name:    "primary without keys",
+			rule:    &conf.TableRule{TableModel: conf.TableModelPrimary},
+			columns: map[string]string{"dt": "date"},
+			wantErr: true,
+		},
+		{
+			name:     "duplicate ignores the keys",
+			rule:     &conf.TableRule{TableModel: conf.TableModelDuplicate, DuplicateKeys: "`site`"},
+			columns:  map[string]string{"dt": "date", "site": "varchar"},
+			keys:     []string{"dt"},
+			wantKeys: []string{},
+		},
+	}
+	starRocks := &StarRocks{Converter{config: &conf.Config{}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := funk.Keys(tt.columns).([]string)
+			sort.Strings(names)
+			tableColumns := &common.TableColumns{Table: &model.Table{}}
+			for _, name := range names {
+				tableColumns.Columns = append(tableColumns.Columns, &model.Column{COLUMN_NAME: name, DATA_TYPE: tt.columns[name]})
+			}
+			for _, key := range tt.keys {
+				tableColumns.PrimaryKCU = append(tableColumns.PrimaryKCU, &model.KeyColumnUsage{COLUMN_NAME: key, CONSTRAINT_NAME: "PRIMARY"})
+			}
+			keys, columns := starRocks.tableKeys(tt.rule, tableColumns)
+			err := starRocks.validateTableModel(tt.rule, keys, columns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTableModel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !funk.Equal(keys, tt.wantKeys) {
+				t.Errorf("tableKeys() = %v, want %v", keys, tt.wantKeys)
+			}
+			if !tt.wantErr && len(keys) > 0 && columns[0].COLUMN_NAME != keys[0] {
+				t.Errorf("tableKeys() columns start with %v, want %v", columns[0].COLUMN_NAME, keys[0])
+			}
+		})
+	}
+}
+
+func TestStarRocks_ToCreateDDLKeys(t *testing.T) {
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "db", TABLE_NAME: "events"}
+	tableColumns := &common.TableColumns{Table: &model.Table{ModelBase: base}, Columns: []*model.Column{
+		{ModelBase: base, COLUMN_NAME: "payload", DATA_TYPE: "json"},
+		{ModelBase: base, COLUMN_NAME: "id", DATA_TYPE: "int"},
+	}}
+	rule := &conf.TableRule{Properties: map[string]string{"replication_num": "3"}}
+	provider := &fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {tableColumns}}}
+	ddlList, _, err := new(StarRocks).Construct(&conf.Config{BENum: 3}, provider).ToCreateDDL()
+	if err != nil || len(ddlList) != 2 {
+		t.Fatalf("ToCreateDDL() = %v, %v", ddlList, err)
+	}
+	for _, want := range []string{"DUPLICATE KEY(`id`)\n", "DISTRIBUTED BY HASH(`id`)"} {
+		if !strings.Contains(ddlList[1], want) {
+			t.Errorf("ToCreateDDL() = %v, want %v", ddlList[1], want)
+		}
+	}
+	// no column can be a key
+	for version, want := range map[string]string{
+		"3.1.0": "COMMENT \"\"\nDISTRIBUTED BY RANDOM BUCKETS",
+		"3.0.0": "-- MANUAL REVIEW REQUIRED: table `db`.`events` can not be created automatically\n",
+	} {
+		tableColumns.Columns = []*model.Column{
+			{ModelBase: base, COLUMN_NAME: "payload", DATA_TYPE: "json"},
+			{ModelBase: base, COLUMN_NAME: "score", DATA_TYPE: "double"},
+		}
+		ddlList, _, err := new(StarRocks).Construct(&conf.Config{BENum: 3, StarRocksVersion: version}, provider).ToCreateDDL()
+		if err != nil || len(ddlList) != 2 || !strings.Contains(ddlList[1], want) || strings.Contains(ddlList[1], "KEY(") {
+			t.Errorf("ToCreateDDL() of %s = %v, %v, want %v", version, ddlList, err, want)
+		}
+	}
+}
+
+// namedColumnProvider formats the columns by their names
+type namedColumnProvider struct {
+	fakeProvider
+}
+
+func (c *namedColumnProvider) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	return fmt.Sprintf("  `%s` %s", column.COLUMN_NAME, column.DATA_TYPE), nil
+}
+
+func TestStarRocks_ToCreateDDLPartitionKey(t *testing.T) {
+	// postgresql tables partitioned by the timestamps
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "public", TABLE_NAME: "events"}
+	tableColumns := &common.TableColumns{
+		Table: &model.Table{ModelBase: base, DATA_LENGTH: 200 * common.GIGA_BYTES, CREATE_TIME: time.Now().AddDate(0, 0, -1)},
+		Columns: []*model.Column{
+			{ModelBase: base, COLUMN_NAME: "id", DATA_TYPE: "bigint", IS_NULLABLE: "YES"},
+			{ModelBase: base, COLUMN_NAME: "created_at", DATA_TYPE: "timestamp with time zone", IS_NULLABLE: "YES"},
+			{ModelBase: base, COLUMN_NAME: "logged_at", DATA_TYPE: "timestamp without time zone", IS_NULLABLE: "YES", IsInPartitionKey: true},
+		},
+	}
+	rule := &conf.TableRule{Properties: map[string]string{"replication_num": "3"}}
+	provider := &namedColumnProvider{fakeProvider{ruledTablesMap: map[*conf.TableRule][]*common.TableColumns{rule: {tableColumns}}}}
+	ddlList, _, err := new(StarRocks).Construct(&conf.Config{BENum: 3}, provider).ToCreateDDL()
+	if err != nil || len(ddlList) != 2 || !strings.Contains(ddlList[1], "PARTITION BY RANGE (logged_at)") {
+		t.Errorf("ToCreateDDL() = %v, %v", ddlList, err)
+	}
+}
@@ -12,6 +12,9 @@ require (
 	github.com/smartystreets/goconvey v1.7.2 // indirect
 	github.com/thoas/go-funk v0.9.0
 	github.com/unknwon/goconfig v0.0.0-20200908083735-df7de6a44db8
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20241021075129-b732d2ac9c9b
+	go.mongodb.org/mongo-driver v1.8.4
 	gorm.io/driver/clickhouse v0.2.1
 	gorm.io/driver/mysql v1.2.3
 	gorm.io/driver/postgres v1.2.2
@@ -49,6 +49,18 @@ func main() {
 		// convert to starrocks external ddl
 		converters = append(converters, new(convert.StarRocksExternal).Construct(config, dbProvider))
 	}
+	if dbProvider.ResultConventers()&common.ConvertToStarRocksMaterializedView == common.ConvertToStarRocksMaterializedView {
+		// convert to starrocks materialized view ddl
+		converters = append(converters, new(convert.StarRocksMaterializedView).Construct(config, dbProvider))
+	}
+	if dbProvider.ResultConventers()&common.ConvertToStarRocksBackfill == common.ConvertToStarRocksBackfill {
+		// convert to starrocks backfill statements
+		converters = append(converters, new(convert.StarRocksBackfill).Construct(config, dbProvider))
+	}
+	if dbProvider.ResultConventers()&common.ConvertToStarRocksLoad == common.ConvertToStarRocksLoad {
+		// convert to starrocks statements loading the files of the source
+		converters = append(converters, new(convert.StarRocksLoad).Construct(config, dbProvider))
+	}
 	if dbProvider.ResultConventers()&common.ConvertToFlink == common.ConvertToFlink {
 		// convert to flink ddl
 		converters = append(converters, new(convert.Flink).Construct(config, dbProvider))
@@ -81,6 +93,30 @@ func main() {
 		}
 		fmt.Println(fmt.Sprintf("Done writing to: %s", writeDir))
 	}
+	reporters := []convert.IReporter{}
+	if dbProvider.ResultConventers()&common.ConvertToStarRocks == common.ConvertToStarRocks {
+		// report foreign key relationships
+		reporters = append(reporters, new(convert.StarRocksRelationship).Construct(config, dbProvider))
+		// report defaults not representable
+		reporters = append(reporters, new(convert.StarRocksDefaults).Construct(config, dbProvider))
+	}
+	if config.SampleRows > 0 {
+		// suggest types refined by the sampled rows
+		reporters = append(reporters, new(convert.SampleAnalysis).Construct(config, dbProvider))
+	}
+	for _, reporter := range reporters {
+		reports, err := reporter.ToReports()
+		if err != nil {
+			panic(err)
+		}
+		for fileName, content := range reports {
+			err = ioutil.WriteFile(filepath.Join(writeDir, fileName), []byte(content), 0644)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(fmt.Sprintf("Done writing to: %s", filepath.Join(writeDir, fileName)))
+		}
+	}
 }
 
 func writeFile(ddlList []string, writeDir, fileName string) error {
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // Column information_schema.columns
 type Column struct {
 	ModelBase
@@ -15,6 +17,11 @@ type Column struct {
 	COLUMN_KEY         string  `gorm:"type:varchar(3);column:column_key" json:"columnKey"`
 	COLUMN_COMMENT     string  `gorm:"type:varchar(1024);column:column_comment" json:"columnComment"`
 	UDT_NAME           string  `gorm:"type:varchar(64);column:udt_name" json:"udtName"`
+	// length in characters of the character types, <= 0 if unbounded
+	CHARACTER_MAXIMUM_LENGTH int64  `gorm:"type:bigint(21);column:character_maximum_length" json:"characterMaximumLength"`
+	CHARACTER_SET_NAME       string `gorm:"type:varchar(64);column:character_set_name" json:"characterSetName"`
+	// `auto_increment`, `on update CURRENT_TIMESTAMP` of mysql, `auto_increment` of identity columns of other sources
+	EXTRA string `gorm:"type:varchar(256);column:extra" json:"extra"`
 	// clickhouse, hive
 	IsInPartitionKey bool `gorm:"type:int;column:is_in_partition_key" json:"isPartitionKey"`
 	// clickhouse
@@ -23,6 +30,34 @@ type Column struct {
 	// IsInPrimaryKey bool `gorm:"type:int;column:is_in_primary_key" json:"isPrimaryKey"`
 	// clickhouse
 	IsInSamplingKey bool `gorm:"type:int;column:is_in_sampling_key" json:"isInSamplingKey"`
+	// sqlserver computed columns, mysql generated columns
+	GenerationExpression string `gorm:"-" json:"generationExpression"`
+	// position in the clustered or secondary index of the source as the sort key, 0 if not in it
+	SortKeyPosition uint64 `gorm:"-" json:"sortKeyPosition"`
+	// distinct values estimated by the statistics of the source, 0 if unknown
+	Cardinality uint64 `gorm:"-" json:"cardinality"`
+	// the defaults, the auto increments and the generations not representable in starrocks
+	DefaultWarnings []string `gorm:"-" json:"defaultWarnings"`
+}
+
+// AddDefaultWarning records the warning once, the column definitions are formatted by each converter
+func (c *Column) AddDefaultWarning(warning string) {
+	for _, w := range c.DefaultWarnings {
+		if w == warning {
+			return
+		}
+	}
+	c.DefaultWarnings = append(c.DefaultWarnings, warning)
+}
+
+// IsAutoIncrement auto increment or identity columns
+func (c *Column) IsAutoIncrement() bool {
+	return strings.Contains(strings.ToLower(c.EXTRA), "auto_increment")
+}
+
+// IsGenerated columns computed by the source database are not captured by cdc
+func (c *Column) IsGenerated() bool {
+	return len(c.GenerationExpression) > 0
 }
 
 func (Column) TableName() string {
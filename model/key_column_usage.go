@@ -6,6 +6,12 @@ type KeyColumnUsage struct {
 	COLUMN_NAME      string `gorm:"type:varchar(64);column:column_name" json:"columnName"`
 	CONSTRAINT_NAME  string `gorm:"type:varchar(64);column:constraint_name" json:"contraintName"`
 	ORDINAL_POSITION uint64 `gorm:"type:bigint(2);column:ordinal_position" json:"ordinalPosition"`
+	// information_schema.table_constraints
+	CONSTRAINT_TYPE string `gorm:"type:varchar(64);column:constraint_type" json:"constraintType"`
+	// referenced key of foreign keys
+	REFERENCED_TABLE_SCHEMA string `gorm:"type:varchar(64);column:referenced_table_schema" json:"referencedTableSchema"`
+	REFERENCED_TABLE_NAME   string `gorm:"type:varchar(64);column:referenced_table_name" json:"referencedTableName"`
+	REFERENCED_COLUMN_NAME  string `gorm:"type:varchar(64);column:referenced_column_name" json:"referencedColumnName"`
 }
 
 func (KeyColumnUsage) TableName() string {
@@ -0,0 +1,20 @@
+package model
+
+// MaterializedView clickhouse system.tables where engine = 'MaterializedView'
+type MaterializedView struct {
+	ModelBase
+	UUID               string `gorm:"type:varchar(2048);column:uuid" json:"uuid"`
+	CREATE_TABLE_QUERY string `gorm:"type:longtext;column:create_table_query" json:"createTableQuery"`
+	TABLE_COMMENT      string `gorm:"type:varchar(2048);column:table_comment" json:"tableComment"`
+	// table the view writes into with `TO db.table`
+	TargetTable string `gorm:"-" json:"targetTable"`
+	// sorting keys and size of the inner table
+	SortingKeys []string `gorm:"-" json:"sortingKeys"`
+	DataLength  uint64   `gorm:"-" json:"dataLength"`
+	// output columns of the view, the columns of the inner table
+	Columns []*Column `gorm:"-" json:"columns"`
+	// query translated to the StarRocks dialect
+	Definition string `gorm:"-" json:"definition"`
+	// reasons why the view can not be translated automatically
+	ReviewReasons []string `gorm:"-" json:"reviewReasons"`
+}
@@ -0,0 +1,37 @@
+package model
+
+// OlapTable starrocks and doris tables parsed from `SHOW CREATE TABLE`
+type OlapTable struct {
+	// DUPLICATE, AGGREGATE, UNIQUE or PRIMARY
+	KeysType string   `json:"keysType"`
+	Keys     []string `json:"keys"`
+	// aggregation functions of the value columns of aggregate tables
+	Aggregations map[string]string `json:"aggregations"`
+	// RANGE, LIST, or EXPRESSION of the partitions created by the loaded data, empty if not partitioned
+	PartitionType string `json:"partitionType"`
+	// columns of RANGE and LIST, the expression of EXPRESSION
+	PartitionColumns []string         `json:"partitionColumns"`
+	Partitions       []*OlapPartition `json:"partitions"`
+	// hash columns, empty for random distributions
+	DistributedBy []string `json:"distributedBy"`
+	// 0 for the buckets decided by the cluster
+	Buckets    int64             `json:"buckets"`
+	OrderBy    []string          `json:"orderBy"`
+	Properties map[string]string `json:"properties"`
+	// `INDEX idx (`col`) USING BITMAP COMMENT ''`
+	Indexes []string      `json:"indexes"`
+	Rollups []*OlapRollup `json:"rollups"`
+}
+
+// OlapPartition partitions listed by `SHOW CREATE TABLE`
+type OlapPartition struct {
+	Name string `json:"name"`
+	// `VALUES [("2021-01-01"), ("2021-02-01"))`, `VALUES LESS THAN ("2021-02-01")` or `VALUES IN ("a", "b")`
+	Values string `json:"values"`
+}
+
+// OlapRollup rollups listed by `DESC ALL`
+type OlapRollup struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
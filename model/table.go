@@ -18,6 +18,17 @@ type Table struct {
 	TABLE_COMMENT   string    `gorm:"type:varchar(2048);column:table_comment" json:"tableComment"`
 	// clickhouse
 	UUID string `gorm:"type:varchar(2048);column:uuid" json:"uuid"`
+	// pgsql partitions and inheriting tables collapsed into this table
+	ChildTableNames []string `gorm:"-" json:"childTableNames"`
+	// hive partitions listed by `SHOW PARTITIONS`, `dt=2021-01-01/hour=01`
+	Partitions []string `gorm:"-" json:"partitions"`
+	// hive storage of `describe formatted`
+	Location       string `gorm:"-" json:"location"`
+	InputFormat    string `gorm:"-" json:"inputFormat"`
+	SerDe          string `gorm:"-" json:"serDe"`
+	FieldDelimiter string `gorm:"-" json:"fieldDelimiter"`
+	// starrocks and doris tables reproduced by their definitions
+	Olap *OlapTable `gorm:"-" json:"olap"`
 }
 
 func (Table) TableName() string {
@@ -1,13 +1,17 @@
 package source
 
 import (
+	"fmt"
 	"math"
+	"regexp"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
 	"strings"
 	"time"
 
+	"github.com/golang/glog"
+	funk "github.com/thoas/go-funk"
 	"gorm.io/gorm"
 )
 
@@ -27,10 +31,10 @@ type IDBSource interface {
 type IDBSourceProvider interface {
 	CombineSchemaName() bool
 	GetFlinkConnectorName() string
-	GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string
+	GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string
 	GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns
-	FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error)
-	FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error)
+	FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error)
+	FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error)
 	ResultConventers() int
 }
 
@@ -39,8 +43,28 @@ type DBSource struct {
 	config         *conf.Config
 	db             *gorm.DB
 	ruledTablesMap map[*conf.TableRule][]*common.TableColumns
+	// the auto increment column chosen of each table
+	autoIncrementColumns map[*model.Table]string
+	// rows sampled of each table, shared by the schema inference, the converters and the reporters
+	samples map[string][]map[string]interface{}
 }
 
+// columnStatistic distinct values of a column in the statistics of the source,
+// or the position of a column in the index sorting the rows of the source
+type columnStatistic struct {
+	TABLE_SCHEMA string `gorm:"column:table_schema"`
+	TABLE_NAME   string `gorm:"column:table_name"`
+	COLUMN_NAME  string `gorm:"column:column_name"`
+	CARDINALITY  uint64 `gorm:"column:cardinality"`
+	POSITION     uint64 `gorm:"column:position"`
+}
+
+var currentTimestampReg = regexp.MustCompile(`(?i)^(current_timestamp|now|now64|getdate|sysdatetime|sysdate|systimestamp|localtimestamp|transaction_timestamp|statement_timestamp)\s*(\(\s*\d*\s*\))?$`)
+var stringLiteralReg = regexp.MustCompile(`^'((?:[^']|'')*)'(::.+)?$`)
+var numberLiteralReg = regexp.MustCompile(`^(-?\d+(\.\d+)?)(::.+)?$`)
+var integerTypeReg = regexp.MustCompile(`(?i)^(TINYINT|SMALLINT|INT|BIGINT)(\(\d+\))?$`)
+var jsonTypeReg = regexp.MustCompile(`(?i)\bJSON\b`)
+
 func Create(config *conf.Config) IDBSource {
 	switch config.DBType {
 	case common.DBSourceMySQL:
@@ -57,6 +81,20 @@ func Create(config *conf.Config) IDBSource {
 		return new(HiveSource).Construct(config)
 	case common.DBSourceTiDB:
 		return new(TiDBSource).Construct(config)
+	case common.DBSourceIceberg:
+		return new(IcebergSource).Construct(config)
+	case common.DBSourceDelta:
+		return new(DeltaSource).Construct(config)
+	case common.DBSourceMongoDB:
+		return new(MongoDBSource).Construct(config)
+	case common.DBSourceStarRocks, common.DBSourceDoris:
+		return new(StarRocksSource).Construct(config)
+	case common.DBSourceMariaDB:
+		return new(MariaDBSource).Construct(config)
+	case common.DBSourceOceanBase:
+		return new(OceanBaseSource).Construct(config)
+	case common.DBSourcePolarDBX:
+		return new(PolarDBXSource).Construct(config)
 	}
 	return nil
 }
@@ -64,44 +102,57 @@ func Create(config *conf.Config) IDBSource {
 func (c *DBSource) calculateRuledTablesMap(matchedTables []*model.Table, allColumns []*model.Column, keyColumnUsageRows []*model.KeyColumnUsage) {
 	c.ruledTablesMap = map[*conf.TableRule][]*common.TableColumns{}
 	for _, table := range matchedTables {
-		matchedTableRule := &conf.TableRule{}
-		for _, tableRule := range c.config.TableRules {
-			if common.RegMatchString(tableRule.DatabasePattern, table.TABLE_CATALOG) &&
-				common.RegMatchString(tableRule.SchemaPattern, table.TABLE_SCHEMA) &&
-				common.RegMatchString(tableRule.TablePattern, table.TABLE_NAME) {
-				// last matched table rule
-				matchedTableRule = tableRule
-			}
-		}
-		if matchedTableRule.Properties == nil {
+		matchedTableRule := c.matchTableRule(table.TABLE_CATALOG, table.TABLE_SCHEMA, table.TABLE_NAME)
+		if matchedTableRule == nil {
 			continue
 		}
 		if _, ok := c.ruledTablesMap[matchedTableRule]; !ok {
 			c.ruledTablesMap[matchedTableRule] = []*common.TableColumns{}
 		}
 		columns := []*model.Column{}
+		excludedColumns := []string{}
 		for _, col := range allColumns {
 			if col.TABLE_SCHEMA == table.TABLE_SCHEMA && col.TABLE_NAME == table.TABLE_NAME && col.TABLE_CATALOG == table.TABLE_CATALOG {
+				if matchedTableRule.ExcludesColumn(col.COLUMN_NAME) {
+					excludedColumns = append(excludedColumns, col.COLUMN_NAME)
+					continue
+				}
 				columns = append(columns, col)
 			}
 		}
 		primaryKCU := []*model.KeyColumnUsage{}
 		uniqueKCU := []*model.KeyColumnUsage{}
+		foreignKCU := []*model.KeyColumnUsage{}
 		for _, keyCol := range keyColumnUsageRows {
 			if keyCol.TABLE_SCHEMA != table.TABLE_SCHEMA || keyCol.TABLE_NAME != table.TABLE_NAME || keyCol.TABLE_CATALOG != table.TABLE_CATALOG {
 				continue
 			}
-			if keyCol.CONSTRAINT_NAME == "PRIMARY" || strings.HasPrefix(keyCol.CONSTRAINT_NAME, "PK__") {
+			switch keyCol.CONSTRAINT_TYPE {
+			case common.CONSTRAINT_PRIMARY_KEY:
 				primaryKCU = append(primaryKCU, keyCol)
-			} else {
+				break
+			case common.CONSTRAINT_UNIQUE:
 				uniqueKCU = append(uniqueKCU, keyCol)
+				break
+			case common.CONSTRAINT_FOREIGN_KEY:
+				foreignKCU = append(foreignKCU, keyCol)
+				break
+			default:
+				// sources without constraint types
+				if keyCol.CONSTRAINT_NAME == "PRIMARY" || strings.HasPrefix(keyCol.CONSTRAINT_NAME, "PK__") {
+					primaryKCU = append(primaryKCU, keyCol)
+				} else {
+					uniqueKCU = append(uniqueKCU, keyCol)
+				}
+				break
 			}
 		}
 		c.ruledTablesMap[matchedTableRule] = append(c.ruledTablesMap[matchedTableRule], &common.TableColumns{
 			Table:      table,
 			Columns:    columns,
-			PrimaryKCU: primaryKCU,
-			UniqueKCU:  uniqueKCU,
+			PrimaryKCU: c.excludeKeys(table, primaryKCU, excludedColumns),
+			UniqueKCU:  c.excludeKeys(table, uniqueKCU, excludedColumns),
+			ForeignKCU: c.excludeKeys(table, foreignKCU, excludedColumns),
 		})
 	}
 	for rule, tables := range c.ruledTablesMap {
@@ -131,6 +182,7 @@ func (c *DBSource) calculateRuledTablesMap(matchedTables []*model.Table, allColu
 	for rule, tables := range c.ruledTablesMap {
 		if rule.FromShardingSrc {
 			totalSize := uint64(0)
+			totalRows := uint64(0)
 			firstCreatedTime := time.Now().Unix()
 			databaseNames := []string{}
 			schemaNames := []string{}
@@ -140,11 +192,13 @@ func (c *DBSource) calculateRuledTablesMap(matchedTables []*model.Table, allColu
 				schemaNames = append(schemaNames, table.Table.TABLE_SCHEMA)
 				tableNames = append(tableNames, table.Table.TABLE_NAME)
 				totalSize += table.Table.DATA_LENGTH
+				totalRows += table.Table.TABLE_ROWS
 				firstCreatedTime = int64(math.Min(float64(table.Table.CREATE_TIME.Unix()), float64(firstCreatedTime)))
 			}
 			singleTable := tables[0]
 			singleTable.Table.CREATE_TIME = time.Unix(firstCreatedTime, 0)
 			singleTable.Table.DATA_LENGTH = totalSize
+			singleTable.Table.TABLE_ROWS = totalRows
 			databaseName := common.LongestCommonXfix(databaseNames, true)
 			if len(databaseName) == 0 {
 				databaseName = "db" + common.SHARD_SUFFIX
@@ -182,6 +236,11 @@ func (c *DBSource) calculateRuledTablesMap(matchedTables []*model.Table, allColu
 				kcu.TABLE_SCHEMA = schemaName
 				kcu.TABLE_NAME = tableName
 			}
+			for _, kcu := range singleTable.ForeignKCU {
+				kcu.TABLE_CATALOG = databaseName
+				kcu.TABLE_SCHEMA = schemaName
+				kcu.TABLE_NAME = tableName
+			}
 			for _, col := range singleTable.Columns {
 				col.TABLE_CATALOG = databaseName
 				col.TABLE_SCHEMA = schemaName
@@ -192,6 +251,279 @@ func (c *DBSource) calculateRuledTablesMap(matchedTables []*model.Table, allColu
 	}
 }
 
+// sampleOnce the rows sampled before of the table, or the rows sampled now
+func (c *DBSource) sampleOnce(db, schema, table string, limit int, sample func() ([]map[string]interface{}, error)) ([]map[string]interface{}, error) {
+	key := fmt.Sprintf("%s.%s.%s.%d", db, schema, table, limit)
+	if rows, ok := c.samples[key]; ok {
+		return rows, nil
+	}
+	rows, err := sample()
+	if err != nil {
+		return rows, err
+	}
+	if c.samples == nil {
+		c.samples = map[string][]map[string]interface{}{}
+	}
+	c.samples[key] = rows
+	return rows, nil
+}
+
+// applyStatistics sets the cardinality and the positions in the sort keys of the columns in the statistics
+func (c *DBSource) applyStatistics(columns []*model.Column, statistics []*columnStatistic) {
+	columnMap := map[[3]string]*model.Column{}
+	for _, column := range columns {
+		columnMap[[3]string{column.TABLE_SCHEMA, column.TABLE_NAME, column.COLUMN_NAME}] = column
+	}
+	for _, statistic := range statistics {
+		column, ok := columnMap[[3]string{statistic.TABLE_SCHEMA, statistic.TABLE_NAME, statistic.COLUMN_NAME}]
+		if !ok {
+			continue
+		}
+		if statistic.CARDINALITY > 0 {
+			column.Cardinality = statistic.CARDINALITY
+		}
+		if statistic.POSITION > 0 {
+			column.SortKeyPosition = statistic.POSITION
+		}
+	}
+}
+
+// trimDefault `((0))` -> `0`
+func (c *DBSource) trimDefault(expression string) string {
+	expression = strings.TrimSpace(expression)
+	for strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+// isCurrentTimestamp `CURRENT_TIMESTAMP`, `now()`, `getdate()`, `SYSDATE` and so on
+func (c *DBSource) isCurrentTimestamp(expression string) bool {
+	return currentTimestampReg.MatchString(c.trimDefault(expression))
+}
+
+// literalDefault the value of quoted strings and numbers, casts of postgresql are ignored
+func (c *DBSource) literalDefault(expression string) (string, bool) {
+	expression = c.trimDefault(expression)
+	if matches := stringLiteralReg.FindStringSubmatch(expression); len(matches) > 1 {
+		return strings.Replace(matches[1], "''", "'", -1), true
+	}
+	if matches := numberLiteralReg.FindStringSubmatch(expression); len(matches) > 1 {
+		return matches[1], true
+	}
+	if strings.EqualFold(expression, "true") || strings.EqualFold(expression, "false") {
+		return strings.ToLower(expression), true
+	}
+	return "", false
+}
+
+// defaultClause DEFAULT of literals and the current timestamp, the others are recorded as unsupported
+func (c *DBSource) defaultClause(column *model.Column, colDataType, expression string) string {
+	if len(c.trimDefault(expression)) == 0 || strings.EqualFold(c.trimDefault(expression), "null") {
+		return ""
+	}
+	if c.isCurrentTimestamp(expression) {
+		if strings.HasPrefix(strings.ToUpper(colDataType), "DATETIME") {
+			return "DEFAULT CURRENT_TIMESTAMP"
+		}
+		column.AddDefaultWarning(fmt.Sprintf("default `%s` is only supported by DATETIME columns", expression))
+		return ""
+	}
+	if literal, ok := c.literalDefault(expression); ok {
+		return fmt.Sprintf("DEFAULT \"%s\"", strings.Replace(literal, "\"", "\\\"", -1))
+	}
+	column.AddDefaultWarning(fmt.Sprintf("default expression `%s` is not supported", expression))
+	return ""
+}
+
+// autoIncrement BIGINT of the auto increment column, only the first one of each table is kept
+func (c *DBSource) autoIncrement(table *model.Table, column *model.Column, colDataType string) (string, bool) {
+	if !c.config.Supports(conf.CapabilityAutoIncrement) {
+		column.AddDefaultWarning(fmt.Sprintf("AUTO_INCREMENT is not supported by %s", c.config.TargetName()))
+		return colDataType, false
+	}
+	if c.autoIncrementColumns == nil {
+		c.autoIncrementColumns = map[*model.Table]string{}
+	}
+	if name, ok := c.autoIncrementColumns[table]; ok && name != column.COLUMN_NAME {
+		column.AddDefaultWarning(fmt.Sprintf("only one AUTO_INCREMENT column is supported, `%s` is chosen", name))
+		return colDataType, false
+	}
+	if !integerTypeReg.MatchString(strings.TrimSpace(colDataType)) {
+		column.AddDefaultWarning(fmt.Sprintf("AUTO_INCREMENT of %s is not supported", strings.TrimSpace(colDataType)))
+		return colDataType, false
+	}
+	c.autoIncrementColumns[table] = column.COLUMN_NAME
+	return "BIGINT", true
+}
+
+// generatedColumnSupported generated columns are excluded from the targets not supporting them
+func (c *DBSource) generatedColumnSupported(column *model.Column) bool {
+	if c.config.Supports(conf.CapabilityGeneratedColumn) {
+		return true
+	}
+	column.AddDefaultWarning(fmt.Sprintf("generated columns are not supported by %s", c.config.TargetName()))
+	return false
+}
+
+// instantType the type of the timestamps with time zones,
+// instants are converted to the session time zone of flink before written into the DATETIME column
+func (c *DBSource) instantType(precision int, flink bool) string {
+	if flink {
+		return fmt.Sprintf("TIMESTAMP_LTZ(%d)", precision)
+	}
+	return "DATETIME"
+}
+
+// supportedType STRING for the semi-structured types not supported by the target starrocks
+func (c *DBSource) supportedType(colDataType string) string {
+	if !c.config.Supports(conf.CapabilityJSON) {
+		colDataType = jsonTypeReg.ReplaceAllString(colDataType, "STRING")
+	}
+	dataType := strings.ToUpper(strings.TrimSpace(colDataType))
+	if strings.HasPrefix(dataType, "ARRAY") && !c.config.Supports(conf.CapabilityArray) {
+		return "STRING"
+	}
+	if (strings.Contains(dataType, "MAP<") || strings.Contains(dataType, "STRUCT<")) && !c.config.Supports(conf.CapabilityMapStruct) {
+		return "STRING"
+	}
+	return colDataType
+}
+
+// columnType the type overridden by the column rules, or the type supported by the target starrocks,
+// followed by the aggregation function of the value columns of aggregate tables
+func (c *DBSource) columnType(matchedTableRule *conf.TableRule, column *model.Column, colDataType string, flink bool) string {
+	if flink {
+		return c.overriddenType(matchedTableRule, column, colDataType, flink)
+	}
+	colDataType = c.overriddenType(matchedTableRule, column, c.supportedType(colDataType), flink)
+	if matchedTableRule != nil {
+		if agg := matchedTableRule.Aggregation(column.COLUMN_NAME); len(agg) > 0 {
+			return colDataType + " " + agg
+		}
+	}
+	return colDataType
+}
+
+// overriddenType the type overridden by the column rules
+func (c *DBSource) overriddenType(matchedTableRule *conf.TableRule, column *model.Column, colDataType string, flink bool) string {
+	if matchedTableRule == nil {
+		return colDataType
+	}
+	columnRule := matchedTableRule.MatchColumnRule(column.COLUMN_NAME)
+	if columnRule == nil {
+		return colDataType
+	}
+	if len(columnRule.Type) == 0 && len(columnRule.FlinkType) == 0 {
+		if !columnRule.IsBuiltinTransform() {
+			return colDataType
+		}
+		// hex of sha-256 or masked strings
+		if !flink && columnRule.Transform == conf.TransformHash {
+			return "VARCHAR(64)"
+		}
+		return "STRING"
+	}
+	if !flink {
+		if len(columnRule.Type) > 0 {
+			return columnRule.Type
+		}
+		return colDataType
+	}
+	if len(columnRule.FlinkType) > 0 {
+		return columnRule.FlinkType
+	}
+	return flinkType(columnRule.Type)
+}
+
+// flinkType flink sql type of the starrocks type, the element, key, value and field types of the nested types included
+func flinkType(starRocksType string) string {
+	trimmed := strings.TrimSpace(starRocksType)
+	if idx := strings.Index(trimmed, "<"); idx > 0 && strings.HasSuffix(trimmed, ">") {
+		args := splitOlapDefs(trimmed[idx+1 : len(trimmed)-1])
+		switch strings.ToUpper(strings.TrimSpace(trimmed[:idx])) {
+		case "ARRAY":
+			if len(args) == 1 {
+				return fmt.Sprintf("ARRAY<%s>", flinkType(args[0]))
+			}
+			break
+		case "MAP":
+			if len(args) == 2 {
+				return fmt.Sprintf("MAP<%s, %s>", flinkType(args[0]), flinkType(args[1]))
+			}
+			break
+		case "STRUCT":
+			// `name type` fields as ROW of flink
+			fields := []string{}
+			for _, field := range args {
+				if idx := strings.Index(field, " "); idx > 0 {
+					fields = append(fields, fmt.Sprintf("%s %s", field[:idx], flinkType(field[idx+1:])))
+				}
+			}
+			return fmt.Sprintf("ROW<%s>", strings.Join(fields, ", "))
+		}
+	}
+	dataType := strings.ToUpper(trimmed)
+	if idx := strings.Index(dataType, "("); idx > 0 {
+		dataType = strings.TrimSpace(dataType[:idx])
+	}
+	switch dataType {
+	case "CHAR", "VARCHAR", "STRING", "JSON", "BITMAP", "HLL":
+		return "STRING"
+	case "DATETIME":
+		return "TIMESTAMP"
+	case "LARGEINT":
+		return "DECIMAL(38, 0)"
+	}
+	return starRocksType
+}
+
+// stringType VARCHAR(n) or CHAR(n) sized in utf-8 bytes with the `varchar` string mapping, STRING for unbounded text
+func (c *DBSource) stringType(matchedTableRule *conf.TableRule, column *model.Column, fixed bool) string {
+	if matchedTableRule == nil || matchedTableRule.StringMapping != conf.StringMappingVarchar || column.CHARACTER_MAXIMUM_LENGTH <= 0 {
+		return "STRING"
+	}
+	length := column.CHARACTER_MAXIMUM_LENGTH * common.CharsetMaxBytes(column.CHARACTER_SET_NAME)
+	if fixed && length <= 255 {
+		return fmt.Sprintf("CHAR(%d)", length)
+	}
+	// 65533 bytes before 2.1
+	if length > 1048576 || (length > 65533 && !c.config.Supports(conf.CapabilityLongVarchar)) {
+		return "STRING"
+	}
+	return fmt.Sprintf("VARCHAR(%d)", length)
+}
+
+// excludeKeys drops the constraints with excluded columns
+func (c *DBSource) excludeKeys(table *model.Table, kcuList []*model.KeyColumnUsage, excludedColumns []string) []*model.KeyColumnUsage {
+	if len(excludedColumns) == 0 {
+		return kcuList
+	}
+	excludedConstraints := []string{}
+	for _, kcu := range kcuList {
+		if funk.ContainsString(excludedColumns, kcu.COLUMN_NAME) && !funk.ContainsString(excludedConstraints, kcu.CONSTRAINT_NAME) {
+			glog.Warningf("constraint [%s] of table [%s.%s] is dropped with the excluded column [%s]", kcu.CONSTRAINT_NAME, table.TABLE_CATALOG, table.GetSchemaPrefixedTableName(), kcu.COLUMN_NAME)
+			excludedConstraints = append(excludedConstraints, kcu.CONSTRAINT_NAME)
+		}
+	}
+	return funk.Filter(kcuList, func(kcu *model.KeyColumnUsage) bool {
+		return !funk.ContainsString(excludedConstraints, kcu.CONSTRAINT_NAME)
+	}).([]*model.KeyColumnUsage)
+}
+
+func (c *DBSource) matchTableRule(catalog, schema, table string) *conf.TableRule {
+	var matchedTableRule *conf.TableRule
+	for _, tableRule := range c.config.TableRules {
+		if common.RegMatchString(tableRule.DatabasePattern, catalog) &&
+			common.RegMatchString(tableRule.SchemaPattern, schema) &&
+			common.RegMatchString(tableRule.TablePattern, table) {
+			// last matched table rule
+			matchedTableRule = tableRule
+		}
+	}
+	return matchedTableRule
+}
+
 func (c *DBSource) encodeComment(comment string) string {
 	comment = strings.Replace(comment, "\"", "\\\"", -1)
 	comment = strings.Replace(comment, "\n", " ", -1)
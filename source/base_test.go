@@ -0,0 +1,162 @@
+package source
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestDBSource_calculateRuledTablesMap(t *testing.T) {
+	rule := &conf.TableRule{DatabasePattern: "^db$", SchemaPattern: ".*", TablePattern: "^orders$", FromShardingSrc: true}
+	source := &DBSource{config: &conf.Config{TableRules: []*conf.TableRule{rule}}}
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "public", TABLE_NAME: "orders"}
+	keys := []*model.KeyColumnUsage{
+		{ModelBase: base, COLUMN_NAME: "id", CONSTRAINT_NAME: "orders_pkey", CONSTRAINT_TYPE: common.CONSTRAINT_PRIMARY_KEY},
+		{ModelBase: base, COLUMN_NAME: "code", CONSTRAINT_NAME: "orders_code_key", CONSTRAINT_TYPE: common.CONSTRAINT_UNIQUE},
+		{ModelBase: base, COLUMN_NAME: "user_id", CONSTRAINT_NAME: "orders_user_id_fkey", CONSTRAINT_TYPE: common.CONSTRAINT_FOREIGN_KEY},
+		{ModelBase: base, COLUMN_NAME: "bucket_id", CONSTRAINT_NAME: "bucket"},
+	}
+	source.calculateRuledTablesMap([]*model.Table{{ModelBase: base}}, []*model.Column{{ModelBase: base, COLUMN_NAME: "id"}}, keys)
+	tableColumns := source.ruledTablesMap[rule][0]
+	if len(tableColumns.PrimaryKCU) != 1 || tableColumns.PrimaryKCU[0].COLUMN_NAME != "id" {
+		t.Errorf("PrimaryKCU = %v", tableColumns.PrimaryKCU)
+	}
+	if len(tableColumns.UniqueKCU) != 2 || tableColumns.UniqueKCU[0].COLUMN_NAME != "code" || tableColumns.UniqueKCU[1].COLUMN_NAME != "bucket_id" {
+		t.Errorf("UniqueKCU = %v", tableColumns.UniqueKCU)
+	}
+	if len(tableColumns.ForeignKCU) != 1 || tableColumns.ForeignKCU[0].COLUMN_NAME != "user_id" {
+		t.Errorf("ForeignKCU = %v", tableColumns.ForeignKCU)
+	}
+}
+
+func TestDBSource_stringType(t *testing.T) {
+	varchar := &conf.TableRule{StringMapping: conf.StringMappingVarchar}
+	tests := []struct {
+		name   string
+		rule   *conf.TableRule
+		column *model.Column
+		fixed  bool
+		want   string
+	}{
+		{"string mapping", &conf.TableRule{StringMapping: conf.StringMappingString}, &model.Column{CHARACTER_MAXIMUM_LENGTH: 32, CHARACTER_SET_NAME: "utf8mb4"}, false, "STRING"},
+		{"utf8mb4 varchar", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 32, CHARACTER_SET_NAME: "utf8mb4"}, false, "VARCHAR(128)"},
+		{"latin1 char", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 10, CHARACTER_SET_NAME: "latin1"}, true, "CHAR(20)"},
+		{"wide char", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 100, CHARACTER_SET_NAME: "utf8"}, true, "VARCHAR(300)"},
+		{"unknown charset", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 10}, false, "VARCHAR(40)"},
+		{"max length", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: -1, CHARACTER_SET_NAME: "iso_1"}, false, "STRING"},
+		{"too long", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 1 << 20, CHARACTER_SET_NAME: "utf8mb4"}, false, "STRING"},
+		{"long before 2.1", varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 20000, CHARACTER_SET_NAME: "utf8mb4"}, false, "STRING"},
+	}
+	source := &DBSource{config: &conf.Config{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := source.stringType(tt.rule, tt.column, tt.fixed); got != tt.want {
+				t.Errorf("stringType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	source.config.StarRocksVersion = "2.1.0"
+	if got := source.stringType(varchar, &model.Column{CHARACTER_MAXIMUM_LENGTH: 20000, CHARACTER_SET_NAME: "utf8mb4"}, false); got != "VARCHAR(80000)" {
+		t.Errorf("stringType() = %v, want VARCHAR(80000)", got)
+	}
+}
+
+func TestDBSource_columnRules(t *testing.T) {
+	rule := &conf.TableRule{DatabasePattern: "^db$", SchemaPattern: ".*", TablePattern: "^users$", ColumnRules: []*conf.ColumnRule{
+		{Name: "amount", Type: "DECIMAL(20, 4)"},
+		{Name: "secret_kept", Type: "VARCHAR(64)"},
+		{Pattern: "^secret_", Exclude: true},
+	}}
+	source := &DBSource{config: &conf.Config{TableRules: []*conf.TableRule{rule}}}
+	base := model.ModelBase{TABLE_CATALOG: "db", TABLE_SCHEMA: "public", TABLE_NAME: "users"}
+	columns := []*model.Column{{ModelBase: base, COLUMN_NAME: "id"}, {ModelBase: base, COLUMN_NAME: "amount"}, {ModelBase: base, COLUMN_NAME: "secret_token"}, {ModelBase: base, COLUMN_NAME: "secret_kept"}}
+	keys := []*model.KeyColumnUsage{
+		{ModelBase: base, COLUMN_NAME: "id", CONSTRAINT_NAME: "users_pkey", CONSTRAINT_TYPE: common.CONSTRAINT_PRIMARY_KEY},
+		{ModelBase: base, COLUMN_NAME: "id", CONSTRAINT_NAME: "users_token_key", CONSTRAINT_TYPE: common.CONSTRAINT_UNIQUE},
+		{ModelBase: base, COLUMN_NAME: "secret_token", CONSTRAINT_NAME: "users_token_key", CONSTRAINT_TYPE: common.CONSTRAINT_UNIQUE},
+	}
+	source.calculateRuledTablesMap([]*model.Table{{ModelBase: base}}, columns, keys)
+	tableColumns := source.ruledTablesMap[rule][0]
+	if len(tableColumns.Columns) != 3 || tableColumns.Columns[2].COLUMN_NAME != "secret_kept" {
+		t.Errorf("Columns = %v", tableColumns.Columns)
+	}
+	if len(tableColumns.PrimaryKCU) != 1 || len(tableColumns.UniqueKCU) != 0 {
+		t.Errorf("PrimaryKCU = %v, UniqueKCU = %v", tableColumns.PrimaryKCU, tableColumns.UniqueKCU)
+	}
+	if got := source.columnType(rule, columns[1], "DOUBLE", false); got != "DECIMAL(20, 4)" {
+		t.Errorf("columnType() = %v", got)
+	}
+	if got := source.columnType(rule, columns[3], "STRING", true); got != "STRING" {
+		t.Errorf("columnType() of flink = %v", got)
+	}
+	if got := source.columnType(rule, columns[0], "BIGINT", false); got != "BIGINT" {
+		t.Errorf("columnType() without rules = %v", got)
+	}
+}
+
+func TestDBSource_supportedType(t *testing.T) {
+	tests := []struct {
+		version  string
+		dataType string
+		want     string
+	}{
+		{"", "JSON", "JSON"},
+		{"", "MAP<STRING, INT>", "STRING"},
+		{"2.0.0", "JSON", "STRING"},
+		{"2.0.0", "ARRAY<JSON>", "ARRAY<STRING>"},
+		{"1.18.3", "ARRAY<INT>", "STRING"},
+		{"2.5.0", "STRUCT<a INT>", "STRING"},
+		{"2.5.0", "ARRAY<MAP<STRING, INT>>", "STRING"},
+		{"3.1.0", "MAP<STRING, INT>", "MAP<STRING, INT>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.dataType, func(t *testing.T) {
+			source := &DBSource{config: &conf.Config{StarRocksVersion: tt.version}}
+			if got := source.supportedType(tt.dataType); got != tt.want {
+				t.Errorf("supportedType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlinkType(t *testing.T) {
+	tests := []struct {
+		starRocksType string
+		want          string
+	}{
+		{"VARCHAR(20)", "STRING"},
+		{"DATETIME", "TIMESTAMP"},
+		{"ARRAY<DATE>", "ARRAY<DATE>"},
+		{"ARRAY<DATETIME>", "ARRAY<TIMESTAMP>"},
+		{"MAP<VARCHAR(10), LARGEINT>", "MAP<STRING, DECIMAL(38, 0)>"},
+		{"STRUCT<`a` JSON, `b` ARRAY<DATETIME>>", "ROW<`a` STRING, `b` ARRAY<TIMESTAMP>>"},
+		{"ARRAY<MAP<INT, STRUCT<c DECIMAL(10, 2), d LARGEINT>>>", "ARRAY<MAP<INT, ROW<c DECIMAL(10, 2), d DECIMAL(38, 0)>>>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.starRocksType, func(t *testing.T) {
+			if got := flinkType(tt.starRocksType); got != tt.want {
+				t.Errorf("flinkType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDBSource_applyStatistics(t *testing.T) {
+	base := model.ModelBase{TABLE_SCHEMA: "public", TABLE_NAME: "orders"}
+	id := &model.Column{ModelBase: base, COLUMN_NAME: "id"}
+	created := &model.Column{ModelBase: base, COLUMN_NAME: "created"}
+	other := &model.Column{ModelBase: model.ModelBase{TABLE_SCHEMA: "public", TABLE_NAME: "users"}, COLUMN_NAME: "id"}
+	source := &DBSource{config: &conf.Config{}}
+	source.applyStatistics([]*model.Column{id, created, other}, []*columnStatistic{
+		{TABLE_SCHEMA: "public", TABLE_NAME: "orders", COLUMN_NAME: "id", CARDINALITY: 100},
+		{TABLE_SCHEMA: "public", TABLE_NAME: "orders", COLUMN_NAME: "created", POSITION: 1},
+		{TABLE_SCHEMA: "public", TABLE_NAME: "missing", COLUMN_NAME: "id", CARDINALITY: 5},
+	})
+	source.applyStatistics([]*model.Column{id, created, other}, []*columnStatistic{
+		{TABLE_SCHEMA: "public", TABLE_NAME: "orders", COLUMN_NAME: "id", POSITION: 2},
+	})
+	if id.Cardinality != 100 || id.SortKeyPosition != 2 || created.SortKeyPosition != 1 || other.Cardinality != 0 {
+		t.Errorf("applyStatistics() = %v, %v, %v", id, created, other)
+	}
+}
@@ -0,0 +1,163 @@
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"starrocks-migrate-tool/model"
+	"strings"
+)
+
+var (
+	clickHouseMVSelectReg  = regexp.MustCompile(`(?is)\sAS\s+((SELECT|WITH)\s.*)$`)
+	clickHouseMVTargetReg  = regexp.MustCompile(`(?is)^CREATE\s+MATERIALIZED\s+VIEW\s+\S+\s+TO\s+(\S+)`)
+	clickHouseMVCommentReg = regexp.MustCompile(`(?is)\s+COMMENT\s+'(?:[^'\\]|\\.)*'\s*$`)
+	clickHouseCastTypeReg  = regexp.MustCompile(`(?is)^(.*)\s+AS\s+(.+)$`)
+)
+
+var clickHouseMVSyntaxes = []*sqlUnsupportedSyntax{
+	{pattern: regexp.MustCompile(`(?i)\bFINAL\b`), reason: "`FINAL` modifier is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bPREWHERE\b`), reason: "`PREWHERE` is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bSAMPLE\s+[0-9]`), reason: "`SAMPLE` clause is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bARRAY\s+JOIN\b`), reason: "`ARRAY JOIN` is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bGLOBAL\s+(IN|JOIN|ANY|ALL|LEFT|INNER)\b`), reason: "`GLOBAL` joins are not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bWITH\s+(TOTALS|ROLLUP|CUBE)\b`), reason: "`WITH TOTALS/ROLLUP/CUBE` is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bLIMIT\s+[0-9]+\s+BY\b`), reason: "`LIMIT BY` is not supported"},
+	{pattern: regexp.MustCompile(`(?i)\bSETTINGS\b`), reason: "query `SETTINGS` are not supported"},
+	{pattern: regexp.MustCompile(`->`), reason: "lambda expressions are not supported"},
+}
+
+// translateMaterializedView translates the query of a clickhouse materialized view
+// into the StarRocks dialect, views which can not be translated get review reasons
+func (c *ClickHouseSource) translateMaterializedView(mv *model.MaterializedView) {
+	query := strings.TrimSpace(mv.CREATE_TABLE_QUERY)
+	if matches := clickHouseMVTargetReg.FindStringSubmatch(query); len(matches) > 1 {
+		mv.TargetTable = strings.Replace(matches[1], "`", "", -1)
+		mv.ReviewReasons = append(mv.ReviewReasons, fmt.Sprintf("the view writes into the existing table `%s`", mv.TargetTable))
+	}
+	matches := clickHouseMVSelectReg.FindStringSubmatch(query)
+	if len(matches) < 2 {
+		mv.ReviewReasons = append(mv.ReviewReasons, "the select query of the view is not found")
+		return
+	}
+	selectQuery := clickHouseMVCommentReg.ReplaceAllString(strings.TrimSpace(matches[1]), "")
+	definition, reasons := c.mvRewriter().rewrite(selectQuery)
+	mv.Definition = definition
+	mv.ReviewReasons = append(mv.ReviewReasons, reasons...)
+}
+
+func (c *ClickHouseSource) mvRewriter() *sqlRewriter {
+	functions := map[string]sqlFunctionRewriter{}
+	for _, name := range []string{
+		"sum", "min", "max", "avg", "abs", "round", "floor", "ceil", "coalesce", "concat", "lower", "upper", "length",
+		"substring", "trim", "ltrim", "rtrim", "if", "now", "greatest", "least", "sqrt", "exp", "ln", "log10", "replace",
+	} {
+		functions[name] = sameSQLFunction(name)
+	}
+	renamed := map[string]string{
+		"any": "any_value", "ifnull": "ifnull", "todate": "to_date", "groupArray": "array_agg",
+		"argmax": "max_by", "argmin": "min_by", "uniq": "approx_count_distinct", "uniqcombined": "approx_count_distinct",
+		"uniqhll12": "approx_count_distinct", "toyear": "year", "toquarter": "quarter", "tomonth": "month",
+		"todayofmonth": "dayofmonth", "todayofyear": "dayofyear", "todayofweek": "dayofweek_iso", "tohour": "hour",
+		"tominute": "minute", "tosecond": "second", "today": "curdate",
+	}
+	for name, target := range renamed {
+		functions[strings.ToLower(name)] = sameSQLFunction(target)
+	}
+	for name, unit := range map[string]string{
+		"tostartofyear": "year", "tostartofquarter": "quarter", "tostartofmonth": "month", "tomonday": "week",
+		"tostartofday": "day", "tostartofhour": "hour", "tostartofminute": "minute",
+	} {
+		functions[name] = templateSQLFunction(fmt.Sprintf("date_trunc('%s', %%s)", unit), 1)
+	}
+	functions["toyyyymm"] = templateSQLFunction("CAST(date_format(%s, '%%Y%%m') AS INT)", 1)
+	functions["toyyyymmdd"] = templateSQLFunction("CAST(date_format(%s, '%%Y%%m%%d') AS INT)", 1)
+	functions["todatetime"] = templateSQLFunction("CAST(%s AS DATETIME)", 1)
+	functions["tostring"] = templateSQLFunction("CAST(%s AS STRING)", 1)
+	functions["uniqexact"] = templateSQLFunction("count(DISTINCT %s)", 1)
+	functions["groupuniqarray"] = templateSQLFunction("array_distinct(array_agg(%s))", 1)
+	functions["isnull"] = templateSQLFunction("(%s IS NULL)", 1)
+	functions["isnotnull"] = templateSQLFunction("(%s IS NOT NULL)", 1)
+	functions["yesterday"] = templateSQLFunction("days_sub(curdate(), 1)", 0)
+	functions["countif"] = templateSQLFunction("count(if(%s, 1, NULL))", 1)
+	functions["sumif"] = func(params, args []string) (string, error) {
+		return c.conditionalAggregation("sum", "0", params, args)
+	}
+	for _, name := range []string{"avg", "min", "max"} {
+		aggregation := name
+		functions[name+"if"] = func(params, args []string) (string, error) {
+			return c.conditionalAggregation(aggregation, "NULL", params, args)
+		}
+	}
+	functions["count"] = func(params, args []string) (string, error) {
+		if params != nil {
+			return "", fmt.Errorf("parametric call is not supported")
+		}
+		if len(args) == 0 {
+			return "count(*)", nil
+		}
+		return fmt.Sprintf("count(%s)", strings.Join(args, ", ")), nil
+	}
+	functions["median"] = templateSQLFunction("percentile_approx(%s, 0.5)", 1)
+	for _, name := range []string{"quantile", "quantileexact", "quantiletiming"} {
+		functions[name] = func(params, args []string) (string, error) {
+			if len(params) != 1 || len(args) != 1 {
+				return "", fmt.Errorf("only `quantile(level)(expr)` is supported")
+			}
+			return fmt.Sprintf("percentile_approx(%s, %s)", args[0], params[0]), nil
+		}
+	}
+	functions["multiif"] = func(params, args []string) (string, error) {
+		if params != nil || len(args) < 3 || len(args)%2 == 0 {
+			return "", fmt.Errorf("invalid arguments")
+		}
+		caseWhen := "CASE"
+		for idx := 0; idx+1 < len(args); idx += 2 {
+			caseWhen += fmt.Sprintf(" WHEN %s THEN %s", args[idx], args[idx+1])
+		}
+		return caseWhen + fmt.Sprintf(" ELSE %s END", args[len(args)-1]), nil
+	}
+	functions["cast"] = func(params, args []string) (string, error) {
+		if params != nil || len(args) != 1 {
+			return "", fmt.Errorf("invalid arguments")
+		}
+		matches := clickHouseCastTypeReg.FindStringSubmatch(args[0])
+		if len(matches) < 3 {
+			return "", fmt.Errorf("only `CAST(expr AS type)` is supported")
+		}
+		return fmt.Sprintf("CAST(%s AS %s)", matches[1], c.convertTypeName(strings.Trim(strings.TrimSpace(matches[2]), "'"))), nil
+	}
+	for _, chType := range []string{"Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16", "UInt32", "UInt64", "Float32", "Float64"} {
+		functions[strings.ToLower("to"+chType)] = templateSQLFunction(fmt.Sprintf("CAST(%%s AS %s)", c.convertTypeName(chType)), 1)
+	}
+	return &sqlRewriter{
+		functions: functions,
+		keywords:  sqlKeywords,
+		syntaxes:  clickHouseMVSyntaxes,
+	}
+}
+
+func (c *ClickHouseSource) conditionalAggregation(aggregation, otherwise string, params, args []string) (string, error) {
+	if params != nil || len(args) != 2 {
+		return "", fmt.Errorf("invalid arguments")
+	}
+	return fmt.Sprintf("%s(if(%s, %s, %s))", aggregation, args[1], args[0], otherwise), nil
+}
+
+// convertTypeName converts a clickhouse type name to the StarRocks one
+func (c *ClickHouseSource) convertTypeName(typeName string) string {
+	column := &model.Column{DATA_TYPE: typeName}
+	for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+		if strings.HasPrefix(column.DATA_TYPE, wrapper) {
+			column.DATA_TYPE = strings.TrimSuffix(strings.TrimPrefix(column.DATA_TYPE, wrapper), ")")
+		}
+	}
+	if strings.HasPrefix(column.DATA_TYPE, "Decimal(") {
+		fmt.Sscanf(column.DATA_TYPE, "Decimal(%d, %d)", &column.NUMERIC_PRECISION, &column.NUMERIC_SCALE)
+		column.DATA_TYPE = "Decimal"
+	}
+	colDataType := c.transType(column)
+	if len(colDataType) == 0 {
+		return "STRING"
+	}
+	return colDataType
+}
@@ -0,0 +1,56 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestClickHouseSource_translateMaterializedView(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       string
+		wantReview bool
+	}{
+		{
+			name:  "aggregations",
+			query: "CREATE MATERIALIZED VIEW db.mv (`d` Date, `c` UInt64) ENGINE = SummingMergeTree ORDER BY d AS SELECT toStartOfMonth(ts) AS d, count() AS c, sumIf(v, v > 0) AS s, uniqExact(uid) AS u FROM db.events GROUP BY d",
+			want:  "SELECT date_trunc('month', ts) AS d, count(*) AS c, sum(if(v > 0, v, 0)) AS s, count(DISTINCT uid) AS u FROM db.events GROUP BY d",
+		},
+		{
+			name:  "casts and quantiles",
+			query: "CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree ORDER BY k AS SELECT toString(k) AS k, quantile(0.9)(latency) AS p90, CAST(v AS Nullable(Int32)) AS v FROM db.t WHERE v IN (1, 2) AND name = 'count()'",
+			want:  "SELECT CAST(k AS STRING) AS k, percentile_approx(latency, 0.9) AS p90, CAST(v AS INT) AS v FROM db.t WHERE v IN (1, 2) AND name = 'count()'",
+		},
+		{
+			name:       "unknown function",
+			query:      "CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree ORDER BY k AS SELECT k, dictGet('dict', 'name', k) AS name FROM db.t",
+			wantReview: true,
+		},
+		{
+			name:       "final modifier",
+			query:      "CREATE MATERIALIZED VIEW db.mv ENGINE = MergeTree ORDER BY k AS SELECT k FROM db.t FINAL",
+			wantReview: true,
+		},
+		{
+			name:       "target table",
+			query:      "CREATE MATERIALIZED VIEW db.mv TO db.target (`k` UInt8) AS SELECT k FROM db.t",
+			wantReview: true,
+		},
+	}
+	source := new(ClickHouseSource).Construct(&conf.Config{UseDecimalV3: true}).(*ClickHouseSource)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mv := &model.MaterializedView{CREATE_TABLE_QUERY: tt.query}
+			source.translateMaterializedView(mv)
+			if (len(mv.ReviewReasons) > 0) != tt.wantReview {
+				t.Errorf("translateMaterializedView() reviewReasons = %v, wantReview %v", mv.ReviewReasons, tt.wantReview)
+				return
+			}
+			if !tt.wantReview && mv.Definition != tt.want {
+				t.Errorf("translateMaterializedView() = %v, want %v", mv.Definition, tt.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 type ClickHouseSource struct {
 	DBSource
+	ruledViewsMap map[*conf.TableRule][]*model.MaterializedView
 }
 
 func (c *ClickHouseSource) Construct(config *conf.Config) IDBSource {
@@ -45,10 +46,12 @@ func (c *ClickHouseSource) Tables(db, _ string) ([]string, error) {
 	}).([]string), nil
 }
 
-func (c *ClickHouseSource) Sample(db, _, table string, limit int) ([]map[string]interface{}, error) {
-	results := []map[string]interface{}{}
-	c.db.Raw(fmt.Sprintf("SELECT * FROM `%s`.`%s` limit 0,%d;", db, table, limit)).Find(&results)
-	return results, nil
+func (c *ClickHouseSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		results := []map[string]interface{}{}
+		err := c.db.Raw(fmt.Sprintf("SELECT * FROM `%s`.`%s` limit 0,%d;", db, table, limit)).Find(&results).Error
+		return results, err
+	})
 }
 
 func (c *ClickHouseSource) Destroy() {
@@ -63,7 +66,7 @@ func (c *ClickHouseSource) Destroy() {
 }
 
 func (c *ClickHouseSource) ResultConventers() int {
-	return common.ConvertToStarRocks
+	return common.ConvertToStarRocks | common.ConvertToStarRocksMaterializedView
 }
 
 func (c *ClickHouseSource) InitDB() error {
@@ -105,12 +108,52 @@ order by a.database asc, a.table asc;`).Find(&matchedTables)
 	// 		return c, errors.New(fmt.Sprintf("Engine[%s] not supported.", table.ENGINE))
 	// 	}
 	// }
-	mvTables := []*model.Table{}
-	c.db.Raw(`select uuid, database as table_catalog, database as table_schema, table as table_name, comment as table_comment from system.tables where engine = 'MaterializedView';`).Find(&mvTables)
-	mvTableMap := map[string]*model.Table{}
+	mvTables := []*model.MaterializedView{}
+	c.db.Raw(`select uuid, database as table_catalog, database as table_schema, table as table_name, comment as table_comment, create_table_query from system.tables where engine = 'MaterializedView';`).Find(&mvTables)
+	mvTableMap := map[string]*model.MaterializedView{}
 	for _, mvTbl := range mvTables {
 		mvTableMap[fmt.Sprintf(".inner_id.%s", mvTbl.UUID)] = mvTbl
 	}
+
+	allColumns := []*model.Column{}
+	c.db.Raw("select * from information_schema.columns a left join system.columns b on a.table_schema = b.database and a.table_name = b.table and a.column_name = b.name where a.table_schema not in ('information_schema', 'INFORMATION_SCHEMA', 'system') order by a.ORDINAL_POSITION asc").Find(&allColumns)
+	if len(allColumns) == 0 {
+		return c, errors.New("Failed to get rows from information_schema.columns.")
+	}
+	c.ruledViewsMap = map[*conf.TableRule][]*model.MaterializedView{}
+	for _, mvTbl := range mvTables {
+		matchedTableRule := c.matchTableRule(mvTbl.TABLE_CATALOG, mvTbl.TABLE_SCHEMA, mvTbl.TABLE_NAME)
+		if matchedTableRule == nil {
+			continue
+		}
+		for _, tbl := range matchedTables {
+			if tbl.TABLE_CATALOG == mvTbl.TABLE_CATALOG && tbl.TABLE_NAME == fmt.Sprintf(".inner_id.%s", mvTbl.UUID) {
+				mvTbl.DataLength = tbl.DATA_LENGTH
+			}
+		}
+		for _, col := range allColumns {
+			if col.TABLE_CATALOG != mvTbl.TABLE_CATALOG || col.TABLE_NAME != fmt.Sprintf(".inner_id.%s", mvTbl.UUID) {
+				continue
+			}
+			mvTbl.Columns = append(mvTbl.Columns, col)
+			if col.IsInSortingKey {
+				mvTbl.SortingKeys = append(mvTbl.SortingKeys, col.COLUMN_NAME)
+			}
+		}
+		c.translateMaterializedView(mvTbl)
+		c.ruledViewsMap[matchedTableRule] = append(c.ruledViewsMap[matchedTableRule], mvTbl)
+	}
+	// inner tables of the translated views are replaced by StarRocks materialized views
+	isReplacedByView := func(tableName string) bool {
+		mvTbl, ok := mvTableMap[tableName]
+		return ok && len(mvTbl.Definition) > 0 && len(mvTbl.ReviewReasons) == 0 && c.matchTableRule(mvTbl.TABLE_CATALOG, mvTbl.TABLE_SCHEMA, mvTbl.TABLE_NAME) != nil
+	}
+	matchedTables = funk.Filter(matchedTables, func(tbl *model.Table) bool {
+		return !isReplacedByView(tbl.TABLE_NAME)
+	}).([]*model.Table)
+	allColumns = funk.Filter(allColumns, func(col *model.Column) bool {
+		return !isReplacedByView(col.TABLE_NAME)
+	}).([]*model.Column)
 	for _, tbl := range matchedTables {
 		if strings.HasPrefix(tbl.TABLE_NAME, ".inner_id.") {
 			if _, ok := mvTableMap[tbl.TABLE_NAME]; !ok {
@@ -122,12 +165,6 @@ order by a.database asc, a.table asc;`).Find(&matchedTables)
 			tbl.TABLE_NAME = mvTableMap[tbl.TABLE_NAME].TABLE_NAME
 		}
 	}
-
-	allColumns := []*model.Column{}
-	c.db.Raw("select * from information_schema.columns a left join system.columns b on a.table_schema = b.database and a.table_name = b.table and a.column_name = b.name where a.table_schema not in ('information_schema', 'INFORMATION_SCHEMA', 'system') order by a.ORDINAL_POSITION asc").Find(&allColumns)
-	if len(allColumns) == 0 {
-		return c, errors.New("Failed to get rows from information_schema.columns.")
-	}
 	for _, col := range allColumns {
 		if strings.HasPrefix(col.TABLE_NAME, ".inner_id.") {
 			if _, ok := mvTableMap[col.TABLE_NAME]; !ok {
@@ -144,11 +181,12 @@ order by a.database asc, a.table asc;`).Find(&matchedTables)
 				COLUMN_NAME:      col.COLUMN_NAME,
 				ORDINAL_POSITION: col.ORDINAL_POSITION,
 				CONSTRAINT_NAME:  "PRIMARY",
+				CONSTRAINT_TYPE:  common.CONSTRAINT_PRIMARY_KEY,
 			})
 		}
 	}
 	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
-	if len(c.ruledTablesMap) == 0 {
+	if len(c.ruledTablesMap) == 0 && len(c.ruledViewsMap) == 0 {
 		return c, errors.New("No matching table columns found.")
 	}
 	return c, nil
@@ -158,11 +196,15 @@ func (c *ClickHouseSource) GetRuledTablesMap() map[*conf.TableRule][]*common.Tab
 	return c.ruledTablesMap
 }
 
-func (c *ClickHouseSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *ClickHouseSource) GetRuledViewsMap() map[*conf.TableRule][]*model.MaterializedView {
+	return c.ruledViewsMap
+}
+
+func (c *ClickHouseSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	return "", nil
 }
 
-func (c *ClickHouseSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *ClickHouseSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := ""
 	if strings.Contains(column.DATA_TYPE, "AggregateFunction(") {
 		return "", errors.New("Columns with `AggregateFunction` are not supported.")
@@ -189,20 +231,14 @@ func (c *ClickHouseSource) FormatStarRocksColumnDef(table *model.Table, column *
 	}
 	defaultStr := ""
 	if column.COLUMN_DEFAULT != nil {
-		columnDefault := *column.COLUMN_DEFAULT
-		if len(columnDefault) > 0 && !strings.HasPrefix(columnDefault, "map(") && !strings.HasPrefix(columnDefault, "now(") {
-			if !strings.HasPrefix(columnDefault, "'") {
-				columnDefault = fmt.Sprintf("'%s'", strings.Replace(columnDefault, "'", "\\'", -1))
-			} else {
-				columnDefault = fmt.Sprintf("%s", columnDefault)
-			}
-		}
-		defaultStr = fmt.Sprintf("DEFAULT %s", columnDefault)
+		// `now()` to CURRENT_TIMESTAMP, literals of the other functions are unknown
+		defaultStr = c.defaultClause(column, colDataType, strings.Replace(*column.COLUMN_DEFAULT, "\\'", "''", -1))
 	}
 	sumAggregation := ""
-	if table.ENGINE == "SummingMergeTree" && !column.IsInSortingKey {
+	if table.ENGINE == "SummingMergeTree" && !column.IsInSortingKey && (matchedTableRule == nil || len(matchedTableRule.TableModel) == 0) {
 		sumAggregation = "SUM"
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
 	columnStr := fmt.Sprintf("  `%s` %s %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, sumAggregation, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
 	return columnStr, nil
 }
@@ -224,7 +260,7 @@ func (c *ClickHouseSource) transType(column *model.Column) string {
 	case "Float64", "Double":
 		return "DOUBLE"
 	case "Decimal":
-		if !c.config.UseDecimalV3 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
 			if column.NUMERIC_PRECISION > 27 {
 				column.NUMERIC_PRECISION = 27
 			}
@@ -266,7 +302,7 @@ func (c *ClickHouseSource) GetFlinkConnectorName() string {
 	return "jdbc"
 }
 
-func (c *ClickHouseSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *ClickHouseSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return nil
 }
 
@@ -0,0 +1,331 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/thoas/go-funk"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var deltaCommitFileReg = regexp.MustCompile(`^(\d{20})\.json$`)
+
+// `<version>.checkpoint.parquet`, or the parts `<version>.checkpoint.<part>.<parts>.parquet`
+var deltaCheckpointFileReg = regexp.MustCompile(`^(\d{20})\.checkpoint(\.\d{10}\.(\d{10}))?\.parquet$`)
+
+// values of the null partitions, the same as the default partition of hive
+const deltaNullPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// deltaAction line of the json commits of `_delta_log`
+type deltaAction struct {
+	MetaData *deltaMetaData `json:"metaData"`
+	Add      *deltaFile     `json:"add"`
+	Remove   *deltaFile     `json:"remove"`
+}
+
+type deltaMetaData struct {
+	Description      string   `json:"description"`
+	SchemaString     string   `json:"schemaString"`
+	PartitionColumns []string `json:"partitionColumns"`
+	CreatedTime      int64    `json:"createdTime"`
+}
+
+type deltaFile struct {
+	Path            string             `json:"path"`
+	PartitionValues map[string]*string `json:"partitionValues"`
+	Size            uint64             `json:"size"`
+	Stats           string             `json:"stats"`
+}
+
+type deltaStats struct {
+	NumRecords uint64 `json:"numRecords"`
+}
+
+type deltaField struct {
+	Name     string                 `json:"name"`
+	Type     json.RawMessage        `json:"type"`
+	Nullable bool                   `json:"nullable"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// deltaType nested types, primitive types are json strings
+type deltaType struct {
+	Type        string          `json:"type"`
+	Fields      []*deltaField   `json:"fields"`
+	ElementType json.RawMessage `json:"elementType"`
+	KeyType     json.RawMessage `json:"keyType"`
+	ValueType   json.RawMessage `json:"valueType"`
+}
+
+// DeltaSource delta lake tables of `<database>/<table>/_delta_log/*.json`
+type DeltaSource struct {
+	lakeSource
+}
+
+func (c *DeltaSource) Construct(config *conf.Config) IDBSource {
+	c.config = config
+	c.metadataDir = "_delta_log"
+	for _, tableRule := range c.config.TableRules {
+		tableRule.SchemaPattern = ".*"
+	}
+	return c
+}
+
+func (c *DeltaSource) Build() (IDBSourceProvider, error) {
+	if err := c.build(c.readTable); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// readTable columns of the latest metadata, partitions and sizes of the files added and not removed
+// by the latest checkpoint and the json commits after it
+func (c *DeltaSource) readTable(table *model.Table, dir string) ([]*model.Column, []*model.KeyColumnUsage, error) {
+	logDir := filepath.Join(dir, c.metadataDir)
+	infos, err := ioutil.ReadDir(logDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var metaData *deltaMetaData
+	files := map[string]*deltaFile{}
+	replay := func(action *deltaAction) {
+		if action.MetaData != nil {
+			metaData = action.MetaData
+		}
+		if action.Add != nil {
+			files[action.Add.Path] = action.Add
+		}
+		if action.Remove != nil {
+			delete(files, action.Remove.Path)
+		}
+	}
+	// 1. replay the latest complete checkpoint
+	checkpoint, checkpointFiles := c.latestCheckpoint(infos)
+	for _, checkpointFile := range checkpointFiles {
+		actions, err := c.readCheckpoint(filepath.Join(logDir, checkpointFile))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", checkpointFile, err.Error())
+		}
+		for _, action := range actions {
+			replay(action)
+		}
+	}
+	// 2. replay the commits after it
+	commits := []string{}
+	for _, info := range infos {
+		if matches := deltaCommitFileReg.FindStringSubmatch(info.Name()); len(matches) > 0 && (len(checkpointFiles) == 0 || matches[1] > checkpoint) {
+			commits = append(commits, info.Name())
+		}
+	}
+	sort.Strings(commits)
+	for _, commit := range commits {
+		content, err := ioutil.ReadFile(filepath.Join(logDir, commit))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, line := range bytes.Split(content, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			action := &deltaAction{}
+			if err := json.Unmarshal(line, action); err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", commit, err.Error())
+			}
+			replay(action)
+		}
+	}
+	if metaData == nil {
+		return nil, nil, fmt.Errorf("metadata not found in the checkpoints and the json commits of %s", logDir)
+	}
+	if len(checkpointFiles) == 0 && len(commits) > 0 && commits[0] != fmt.Sprintf("%020d.json", 0) {
+		// files of the cleaned up commits are unknown
+		glog.Warningf("commits of table [%s.%s] before %s are missing without checkpoints, partitions and sizes are not collected", table.TABLE_SCHEMA, table.TABLE_NAME, commits[0])
+		files = map[string]*deltaFile{}
+	}
+	table.TABLE_COMMENT = metaData.Description
+	table.CREATE_TIME = time.Unix(0, metaData.CreatedTime*int64(time.Millisecond))
+	// 2. columns
+	schema := &deltaType{}
+	if err := json.Unmarshal([]byte(metaData.SchemaString), schema); err != nil {
+		return nil, nil, fmt.Errorf("schema: %s", err.Error())
+	}
+	columns := []*model.Column{}
+	for idx, field := range schema.Fields {
+		colType, err := c.deltaType(field.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("column [%s]: %s", field.Name, err.Error())
+		}
+		comment, _ := field.Metadata["comment"].(string)
+		column := c.newColumn(table, field.Name, colType, idx+1, field.Nullable, comment)
+		column.IsInPartitionKey = funk.ContainsString(metaData.PartitionColumns, field.Name)
+		columns = append(columns, column)
+	}
+	// 3. partitions and sizes
+	partitions := []string{}
+	for _, file := range files {
+		table.DATA_LENGTH += file.Size
+		stats := &deltaStats{}
+		if len(file.Stats) > 0 && json.Unmarshal([]byte(file.Stats), stats) == nil {
+			table.TABLE_ROWS += stats.NumRecords
+		}
+		if len(metaData.PartitionColumns) == 0 {
+			continue
+		}
+		if partition := c.partition(metaData.PartitionColumns, file.PartitionValues); !funk.ContainsString(partitions, partition) {
+			partitions = append(partitions, partition)
+		}
+	}
+	sort.Strings(partitions)
+	table.Partitions = partitions
+	return columns, []*model.KeyColumnUsage{}, nil
+}
+
+// latestCheckpoint the version and the files of the latest checkpoint of which all the parts exist
+func (c *DeltaSource) latestCheckpoint(infos []os.FileInfo) (string, []string) {
+	checkpoints := map[string][]string{}
+	versions := []string{}
+	for _, info := range infos {
+		matches := deltaCheckpointFileReg.FindStringSubmatch(info.Name())
+		if len(matches) == 0 {
+			continue
+		}
+		if _, ok := checkpoints[matches[1]]; !ok {
+			versions = append(versions, matches[1])
+		}
+		checkpoints[matches[1]] = append(checkpoints[matches[1]], info.Name())
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	for _, version := range versions {
+		parts := 1
+		if matches := deltaCheckpointFileReg.FindStringSubmatch(checkpoints[version][0]); len(matches[3]) > 0 {
+			parts, _ = strconv.Atoi(matches[3])
+		}
+		if len(checkpoints[version]) == parts {
+			sort.Strings(checkpoints[version])
+			return version, checkpoints[version]
+		}
+	}
+	return "", []string{}
+}
+
+// readCheckpoint the actions of the parquet checkpoint, rows of the checkpoint are the actions of the json commits
+func (c *DeltaSource) readCheckpoint(path string) ([]*deltaAction, error) {
+	fileReader, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fileReader.Close()
+	parquetReader, err := reader.NewParquetReader(fileReader, nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer parquetReader.ReadStop()
+	rows, err := parquetReader.ReadByNumber(int(parquetReader.GetNumRows()))
+	if err != nil {
+		return nil, err
+	}
+	// the fields of the rows are the capitalized names of the columns, matched by the json tags case-insensitively
+	content, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	actions := []*deltaAction{}
+	if err := json.Unmarshal(content, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// partition `dt=2021-01-01/hour=01` of the partition values
+func (c *DeltaSource) partition(partitionColumns []string, partitionValues map[string]*string) string {
+	parts := []string{}
+	for _, name := range partitionColumns {
+		value := deltaNullPartition
+		if partitionValue, ok := partitionValues[name]; ok && partitionValue != nil {
+			value = url.PathEscape(*partitionValue)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+	}
+	return strings.Join(parts, "/")
+}
+
+// deltaType starrocks type of the delta type
+func (c *DeltaSource) deltaType(raw json.RawMessage) (string, error) {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		if decimalType, ok := c.decimalType(primitive); ok {
+			return decimalType, nil
+		}
+		switch primitive {
+		case "boolean":
+			return "BOOLEAN", nil
+		case "byte":
+			return "TINYINT", nil
+		case "short":
+			return "SMALLINT", nil
+		case "integer":
+			return "INT", nil
+		case "long":
+			return "BIGINT", nil
+		case "float":
+			return "FLOAT", nil
+		case "double":
+			return "DOUBLE", nil
+		case "date":
+			return "DATE", nil
+		case "timestamp", "timestamp_ntz":
+			return "DATETIME", nil
+		case "string", "binary":
+			return "STRING", nil
+		}
+		return "", fmt.Errorf("type `%s` is not supported", primitive)
+	}
+	nested := &deltaType{}
+	if err := json.Unmarshal(raw, nested); err != nil {
+		return "", err
+	}
+	switch nested.Type {
+	case "array":
+		elementType, err := c.deltaType(nested.ElementType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType), nil
+	case "map":
+		keyType, err := c.deltaType(nested.KeyType)
+		if err != nil {
+			return "", err
+		}
+		valueType, err := c.deltaType(nested.ValueType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("MAP<%s, %s>", keyType, valueType), nil
+	case "struct":
+		fields := []string{}
+		for _, field := range nested.Fields {
+			fieldType, err := c.deltaType(field.Type)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, fmt.Sprintf("`%s` %s", field.Name, fieldType))
+		}
+		return fmt.Sprintf("STRUCT<%s>", strings.Join(fields, ", ")), nil
+	}
+	return "", fmt.Errorf("type `%s` is not supported", nested.Type)
+}
@@ -0,0 +1,129 @@
+package source
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"starrocks-migrate-tool/conf"
+	"testing"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestDeltaSource_Build(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, "db", "events", "_delta_log")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	schema := `{\"type\":\"struct\",\"fields\":[` +
+		`{\"name\":\"id\",\"type\":\"long\",\"nullable\":false,\"metadata\":{\"comment\":\"key\"}},` +
+		`{\"name\":\"payload\",\"type\":{\"type\":\"array\",\"elementType\":\"string\",\"containsNull\":true},\"nullable\":true,\"metadata\":{}},` +
+		`{\"name\":\"dt\",\"type\":\"date\",\"nullable\":true,\"metadata\":{}}]}`
+	commits := []string{
+		`{"protocol":{"minReaderVersion":1,"minWriterVersion":2}}
+{"metaData":{"id":"1","format":{"provider":"parquet"},"schemaString":"` + schema + `","partitionColumns":["dt"],"configuration":{},"createdTime":1609459200000}}
+{"add":{"path":"dt=2021-01-01/a.parquet","partitionValues":{"dt":"2021-01-01"},"size":100,"stats":"{\"numRecords\":10}","dataChange":true}}
+{"add":{"path":"dt=2021-01-02/b.parquet","partitionValues":{"dt":"2021-01-02"},"size":100,"stats":"{\"numRecords\":10}","dataChange":true}}`,
+		`{"remove":{"path":"dt=2021-01-02/b.parquet","dataChange":true}}
+{"add":{"path":"dt=__HIVE_DEFAULT_PARTITION__/c.parquet","partitionValues":{"dt":null},"size":50,"stats":"{\"numRecords\":5}","dataChange":true}}`,
+	}
+	for idx, commit := range commits {
+		ioutil.WriteFile(filepath.Join(logDir, fmt.Sprintf("%020d.json", idx)), []byte(commit), 0644)
+	}
+	rule := &conf.TableRule{DatabasePattern: "^db$", TablePattern: ".*"}
+	source := new(DeltaSource).Construct(&conf.Config{DBPath: dir, UseDecimalV3: true, TableRules: []*conf.TableRule{rule}}).(*DeltaSource)
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tableColumns := source.GetRuledTablesMap()[rule][0]
+	if tableColumns.Table.TABLE_ROWS != 15 || tableColumns.Table.DATA_LENGTH != 150 {
+		t.Errorf("Table = %v", tableColumns.Table)
+	}
+	if want := []string{"dt=2021-01-01", "dt=__HIVE_DEFAULT_PARTITION__"}; !reflect.DeepEqual(tableColumns.Table.Partitions, want) {
+		t.Errorf("Partitions = %v, want %v", tableColumns.Table.Partitions, want)
+	}
+	id, payload, dt := tableColumns.Columns[0], tableColumns.Columns[1], tableColumns.Columns[2]
+	if id.COLUMN_TYPE != "BIGINT" || id.IS_NULLABLE != "NO" || id.COLUMN_COMMENT != "key" || payload.COLUMN_TYPE != "ARRAY<STRING>" || !dt.IsInPartitionKey || dt.DATA_TYPE != "date" {
+		t.Errorf("Columns = %v, %v, %v", id, payload, dt)
+	}
+}
+
+type deltaCheckpointFile struct {
+	Path            *string           `parquet:"name=path, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PartitionValues map[string]string `parquet:"name=partitionValues, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Size            *int64            `parquet:"name=size, type=INT64"`
+	Stats           *string           `parquet:"name=stats, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type deltaCheckpointMetaData struct {
+	SchemaString     *string  `parquet:"name=schemaString, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PartitionColumns []string `parquet:"name=partitionColumns, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+}
+
+type deltaCheckpointRow struct {
+	Add      *deltaCheckpointFile     `parquet:"name=add"`
+	MetaData *deltaCheckpointMetaData `parquet:"name=metaData"`
+}
+
+func TestDeltaSource_BuildCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, "db", "events", "_delta_log")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 1. checkpoint of version 1, the commits before it are cleaned up
+	fileWriter, err := local.NewLocalFileWriter(filepath.Join(logDir, fmt.Sprintf("%020d.checkpoint.parquet", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parquetWriter, err := writer.NewParquetWriter(fileWriter, new(deltaCheckpointRow), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := `{"type":"struct","fields":[{"name":"id","type":"long","nullable":false,"metadata":{}},{"name":"dt","type":"date","nullable":true,"metadata":{}}]}`
+	stats := `{"numRecords":10}`
+	rows := []deltaCheckpointRow{{MetaData: &deltaCheckpointMetaData{SchemaString: &schema, PartitionColumns: []string{"dt"}}}}
+	for _, dt := range []string{"2021-01-01", "2021-01-02"} {
+		path, size := fmt.Sprintf("dt=%s/a.parquet", dt), int64(100)
+		rows = append(rows, deltaCheckpointRow{Add: &deltaCheckpointFile{Path: &path, PartitionValues: map[string]string{"dt": dt}, Size: &size, Stats: &stats}})
+	}
+	for _, row := range rows {
+		if err := parquetWriter.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := parquetWriter.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+	fileWriter.Close()
+	// 2. commits after it, the commit of version 1 is replayed by the checkpoint
+	ioutil.WriteFile(filepath.Join(logDir, fmt.Sprintf("%020d.json", 1)), []byte(`{"add":{"path":"dt=2021-01-09/x.parquet","partitionValues":{"dt":"2021-01-09"},"size":1}}`), 0644)
+	ioutil.WriteFile(filepath.Join(logDir, fmt.Sprintf("%020d.json", 2)), []byte(`{"remove":{"path":"dt=2021-01-02/a.parquet"}}
+{"add":{"path":"dt=2021-01-03/b.parquet","partitionValues":{"dt":"2021-01-03"},"size":50,"stats":"{\"numRecords\":5}"}}`), 0644)
+	rule := &conf.TableRule{DatabasePattern: "^db$", TablePattern: ".*"}
+	source := new(DeltaSource).Construct(&conf.Config{DBPath: dir, TableRules: []*conf.TableRule{rule}}).(*DeltaSource)
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tableColumns := source.GetRuledTablesMap()[rule][0]
+	if tableColumns.Table.TABLE_ROWS != 15 || tableColumns.Table.DATA_LENGTH != 150 || len(tableColumns.Columns) != 2 {
+		t.Errorf("Table = %v, Columns = %v", tableColumns.Table, tableColumns.Columns)
+	}
+	if want := []string{"dt=2021-01-01", "dt=2021-01-03"}; !reflect.DeepEqual(tableColumns.Table.Partitions, want) {
+		t.Errorf("Partitions = %v, want %v", tableColumns.Table.Partitions, want)
+	}
+	// 3. partitions of the files added by the missing commits are unknown
+	os.Remove(filepath.Join(logDir, fmt.Sprintf("%020d.checkpoint.parquet", 1)))
+	ioutil.WriteFile(filepath.Join(logDir, fmt.Sprintf("%020d.json", 1)), []byte(`{"metaData":{"schemaString":"{\"type\":\"struct\",\"fields\":[{\"name\":\"dt\",\"type\":\"date\",\"nullable\":true,\"metadata\":{}}]}","partitionColumns":["dt"]}}`), 0644)
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tableColumns = source.GetRuledTablesMap()[rule][0]
+	if len(tableColumns.Table.Partitions) != 0 || tableColumns.Table.DATA_LENGTH != 0 {
+		t.Errorf("Table = %v, want no partitions and sizes", tableColumns.Table)
+	}
+}
@@ -53,11 +53,11 @@ func (c *HiveSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColu
 	return c.ruledTablesMap
 }
 
-func (c *HiveSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *HiveSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	return "", nil
 }
 
-func (c *HiveSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *HiveSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	return "", nil
 }
 
@@ -65,7 +65,7 @@ func (c *HiveSource) GetFlinkConnectorName() string {
 	return ""
 }
 
-func (c *HiveSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *HiveSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return nil
 }
 
@@ -56,10 +56,16 @@ func (c *HiveSource) Destroy() {
 }
 
 func (c *HiveSource) ResultConventers() int {
-	return common.ConvertToStarRocks | common.ConvertToStarRocksExternal | common.ConvertToFlink
+	return common.ConvertToStarRocks | common.ConvertToStarRocksExternal | common.ConvertToFlink | common.ConvertToStarRocksBackfill | common.ConvertToStarRocksLoad
 }
 
-func (c *HiveSource) Sample(db, _, table string, limit int) ([]map[string]interface{}, error) {
+func (c *HiveSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		return c.sample(db, table, limit)
+	})
+}
+
+func (c *HiveSource) sample(db, table string, limit int) ([]map[string]interface{}, error) {
 	cursor := c.conn.Cursor()
 	ctx := context.Background()
 	cursor.Exec(ctx, fmt.Sprintf("SELECT * FROM `%s`.`%s` limit %d", db, table, limit))
@@ -140,6 +146,13 @@ func (c *HiveSource) Build() (IDBSourceProvider, error) {
 			if err != nil {
 				return c, errors.New(err.Error())
 			}
+			if funk.Some(columns, func(column *model.Column) bool {
+				return column.IsInPartitionKey
+			}) {
+				if table.Partitions, err = c.showPartitions(table); err != nil {
+					return c, errors.New(err.Error())
+				}
+			}
 			matchedTables = append(matchedTables, table)
 			allColumns = append(allColumns, columns...)
 			keyColumnUsageRows = append(keyColumnUsageRows, kcuList...)
@@ -157,7 +170,7 @@ func (c *HiveSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColu
 	return c.ruledTablesMap
 }
 
-func (c *HiveSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *HiveSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := "STRING"
 	switch column.DATA_TYPE {
 	case "char", "varchar", "string":
@@ -170,7 +183,7 @@ func (c *HiveSource) FormatFlinkColumnDef(table *model.Table, column *model.Colu
 		colDataType = "INT"
 		break
 	case "decimal":
-		if !c.config.UseDecimalV3 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
 			if column.NUMERIC_PRECISION > 27 {
 				column.NUMERIC_PRECISION = 27
 			}
@@ -195,11 +208,12 @@ func (c *HiveSource) FormatFlinkColumnDef(table *model.Table, column *model.Colu
 		break
 	}
 	nullableStr := "NULL"
+	colDataType = c.columnType(matchedTableRule, column, colDataType, true)
 	columnStr := fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, colDataType, nullableStr)
 	return columnStr, nil
 }
 
-func (c *HiveSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *HiveSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := "STRING"
 	switch column.DATA_TYPE {
 	case "char", "varchar", "string":
@@ -212,7 +226,7 @@ func (c *HiveSource) FormatStarRocksColumnDef(table *model.Table, column *model.
 		colDataType = "INT"
 		break
 	case "decimal":
-		if !c.config.UseDecimalV3 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
 			if column.NUMERIC_PRECISION > 27 {
 				column.NUMERIC_PRECISION = 27
 			}
@@ -239,6 +253,7 @@ func (c *HiveSource) FormatStarRocksColumnDef(table *model.Table, column *model.
 
 	nullableStr := "NULL"
 	defaultStr := ""
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
 	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
 	return columnStr, nil
 }
@@ -247,7 +262,7 @@ func (c *HiveSource) GetFlinkConnectorName() string {
 	return ""
 }
 
-func (c *HiveSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *HiveSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return nil
 }
 
@@ -321,6 +336,27 @@ func (c *HiveSource) getTables(dbList []string) ([]*model.Table, error) {
 	return tables, nil
 }
 
+// showPartitions `dt=2021-01-01/hour=01` of the partitioned tables
+func (c *HiveSource) showPartitions(table *model.Table) ([]string, error) {
+	cursor := c.conn.Cursor()
+	ctx := context.Background()
+	cursor.Exec(ctx, fmt.Sprintf("show partitions `%s`.`%s`", table.TABLE_SCHEMA, table.TABLE_NAME))
+	if cursor.Err != nil {
+		return nil, cursor.Err
+	}
+	defer cursor.Close()
+	partitions := []string{}
+	var partition string
+	for cursor.HasMore(ctx) {
+		cursor.FetchOne(ctx, &partition)
+		if cursor.Err != nil {
+			return nil, cursor.Err
+		}
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
 func (c *HiveSource) describeTable(table *model.Table) ([]*model.Column, []*model.KeyColumnUsage, error) {
 	cursor := c.conn.Cursor()
 	ctx := context.Background()
@@ -382,6 +418,20 @@ func (c *HiveSource) describeTable(table *model.Table) ([]*model.Column, []*mode
 				table.TABLE_COMMENT = strings.Trim(col3, " ")
 			}
 		}
+		if len(col1) == 0 && strings.Contains(parentType, "Storage Desc Params") && strings.TrimSpace(col2) == "field.delim" {
+			// tabs and spaces are delimiters as well
+			table.FieldDelimiter = strings.TrimRight(col3, " ")
+			if len(table.FieldDelimiter) == 0 && len(col3) > 0 {
+				table.FieldDelimiter = " "
+			}
+		}
+		if strings.HasPrefix(col1, "Location:") {
+			table.Location = strings.TrimSpace(col2)
+		} else if strings.HasPrefix(col1, "InputFormat:") {
+			table.InputFormat = strings.TrimSpace(col2)
+		} else if strings.HasPrefix(col1, "SerDe Library:") {
+			table.SerDe = strings.TrimSpace(col2)
+		}
 		if strings.Contains(col1, "CreateTime:") {
 			table.CREATE_TIME, _ = time.Parse("Mon Jan 2 15:04:05 MST 2006", strings.Trim(col2, " "))
 		}
@@ -407,7 +457,7 @@ func (c *HiveSource) describeTable(table *model.Table) ([]*model.Column, []*mode
 		if strings.Contains(col1, "# Partition Information") {
 			colAsPartition = true
 		}
-		if len(col1) > 0 && strings.Index(col1, "#") == 0 || strings.Contains(col1, "Table Parameters") {
+		if len(col1) > 0 && strings.Index(col1, "#") == 0 || strings.Contains(col1, "Table Parameters") || strings.Contains(col1, "Storage Desc Params") {
 			parentType = col1
 		}
 	}
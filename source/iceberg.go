@@ -0,0 +1,323 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/thoas/go-funk"
+)
+
+var icebergMetadataFileReg = regexp.MustCompile(`^v?(\d+)(-[^.]*)?\.metadata\.json$`)
+
+// transforms of the partition fields kept as the partition columns
+var icebergPartitionTransforms = []string{"identity", "year", "month", "day", "hour"}
+
+// icebergMetadata `metadata/*.metadata.json` of format version 1 and 2
+type icebergMetadata struct {
+	Location           string                   `json:"location"`
+	LastUpdatedMs      int64                    `json:"last-updated-ms"`
+	Properties         map[string]string        `json:"properties"`
+	Schema             *icebergSchema           `json:"schema"`
+	Schemas            []*icebergSchema         `json:"schemas"`
+	CurrentSchemaID    int                      `json:"current-schema-id"`
+	PartitionSpec      []*icebergPartitionField `json:"partition-spec"`
+	PartitionSpecs     []*icebergPartitionSpec  `json:"partition-specs"`
+	DefaultSpecID      int                      `json:"default-spec-id"`
+	SortOrders         []*icebergSortOrder      `json:"sort-orders"`
+	DefaultSortOrderID int                      `json:"default-sort-order-id"`
+	CurrentSnapshotID  int64                    `json:"current-snapshot-id"`
+	Snapshots          []*icebergSnapshot       `json:"snapshots"`
+}
+
+type icebergSchema struct {
+	SchemaID           int             `json:"schema-id"`
+	IdentifierFieldIDs []int           `json:"identifier-field-ids"`
+	Fields             []*icebergField `json:"fields"`
+}
+
+type icebergField struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+	Doc      string          `json:"doc"`
+}
+
+// icebergType nested types, primitive types are json strings
+type icebergType struct {
+	Type            string          `json:"type"`
+	Fields          []*icebergField `json:"fields"`
+	Element         json.RawMessage `json:"element"`
+	ElementRequired bool            `json:"element-required"`
+	Key             json.RawMessage `json:"key"`
+	Value           json.RawMessage `json:"value"`
+}
+
+type icebergPartitionSpec struct {
+	SpecID int                      `json:"spec-id"`
+	Fields []*icebergPartitionField `json:"fields"`
+}
+
+type icebergPartitionField struct {
+	Name      string `json:"name"`
+	Transform string `json:"transform"`
+	SourceID  int    `json:"source-id"`
+}
+
+type icebergSortOrder struct {
+	OrderID int                 `json:"order-id"`
+	Fields  []*icebergSortField `json:"fields"`
+}
+
+type icebergSortField struct {
+	Transform string `json:"transform"`
+	SourceID  int    `json:"source-id"`
+}
+
+type icebergSnapshot struct {
+	SnapshotID  int64             `json:"snapshot-id"`
+	TimestampMs int64             `json:"timestamp-ms"`
+	Summary     map[string]string `json:"summary"`
+}
+
+// IcebergSource iceberg tables of the hadoop catalog layout `<database>/<table>/metadata/*.metadata.json`
+type IcebergSource struct {
+	lakeSource
+}
+
+func (c *IcebergSource) Construct(config *conf.Config) IDBSource {
+	c.config = config
+	c.metadataDir = "metadata"
+	for _, tableRule := range c.config.TableRules {
+		tableRule.SchemaPattern = ".*"
+	}
+	return c
+}
+
+func (c *IcebergSource) Build() (IDBSourceProvider, error) {
+	if err := c.build(c.readTable); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// readTable columns of the current schema, partition columns of the default spec, sort keys of the default sort order
+// and identifier fields as the primary key
+func (c *IcebergSource) readTable(table *model.Table, dir string) ([]*model.Column, []*model.KeyColumnUsage, error) {
+	metadataFile, err := c.currentMetadataFile(filepath.Join(dir, c.metadataDir))
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := ioutil.ReadFile(metadataFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := &icebergMetadata{}
+	if err := json.Unmarshal(content, metadata); err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", metadataFile, err.Error())
+	}
+	schema := metadata.currentSchema()
+	if schema == nil {
+		return nil, nil, fmt.Errorf("%s: current schema not found", metadataFile)
+	}
+	table.TABLE_COMMENT = metadata.Properties["comment"]
+	table.Location = metadata.Location
+	if snapshot := metadata.currentSnapshot(); snapshot != nil {
+		table.DATA_LENGTH, _ = strconv.ParseUint(snapshot.Summary["total-files-size"], 10, 64)
+		table.TABLE_ROWS, _ = strconv.ParseUint(snapshot.Summary["total-records"], 10, 64)
+	}
+	table.CREATE_TIME = time.Unix(0, metadata.LastUpdatedMs*int64(time.Millisecond))
+	if len(metadata.Snapshots) > 0 {
+		table.CREATE_TIME = time.Unix(0, metadata.Snapshots[0].TimestampMs*int64(time.Millisecond))
+	}
+	// 1. columns
+	columns := []*model.Column{}
+	fieldNames := map[int]string{}
+	for idx, field := range schema.Fields {
+		colType, err := c.icebergType(field.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("column [%s]: %s", field.Name, err.Error())
+		}
+		columns = append(columns, c.newColumn(table, field.Name, colType, idx+1, !field.Required, field.Doc))
+		fieldNames[field.ID] = field.Name
+	}
+	// 2. partition columns
+	for _, field := range metadata.partitionFields() {
+		column := c.findColumn(columns, fieldNames[field.SourceID])
+		if column == nil || !funk.ContainsString(icebergPartitionTransforms, field.Transform) {
+			glog.Warningf("partition field [%s] of table [%s.%s] is ignored", field.Name, table.TABLE_SCHEMA, table.TABLE_NAME)
+			continue
+		}
+		column.IsInPartitionKey = true
+	}
+	// 3. sort keys till the first field not sorted by its values
+	for idx, field := range metadata.sortFields() {
+		column := c.findColumn(columns, fieldNames[field.SourceID])
+		if column == nil || field.Transform != "identity" {
+			break
+		}
+		column.SortKeyPosition = uint64(idx + 1)
+	}
+	// 4. identifier fields
+	kcuList := []*model.KeyColumnUsage{}
+	for idx, id := range schema.IdentifierFieldIDs {
+		if _, ok := fieldNames[id]; !ok {
+			glog.Warningf("identifier fields of table [%s.%s] are nested, the primary key is ignored", table.TABLE_SCHEMA, table.TABLE_NAME)
+			return columns, []*model.KeyColumnUsage{}, nil
+		}
+		kcuList = append(kcuList, &model.KeyColumnUsage{
+			ModelBase:        table.ModelBase,
+			COLUMN_NAME:      fieldNames[id],
+			CONSTRAINT_NAME:  "PRIMARY",
+			CONSTRAINT_TYPE:  common.CONSTRAINT_PRIMARY_KEY,
+			ORDINAL_POSITION: uint64(idx + 1),
+		})
+	}
+	return columns, kcuList, nil
+}
+
+// currentMetadataFile the version of `version-hint.text`, or the latest version of the metadata files
+func (c *IcebergSource) currentMetadataFile(metadataDir string) (string, error) {
+	if hint, err := ioutil.ReadFile(filepath.Join(metadataDir, "version-hint.text")); err == nil {
+		metadataFile := filepath.Join(metadataDir, fmt.Sprintf("v%s.metadata.json", strings.TrimSpace(string(hint))))
+		if _, err := os.Stat(metadataFile); err == nil {
+			return metadataFile, nil
+		}
+	}
+	infos, err := ioutil.ReadDir(metadataDir)
+	if err != nil {
+		return "", err
+	}
+	metadataFile := ""
+	latestVersion := int64(-1)
+	for _, info := range infos {
+		matches := icebergMetadataFileReg.FindStringSubmatch(info.Name())
+		if len(matches) == 0 {
+			continue
+		}
+		if version, _ := strconv.ParseInt(matches[1], 10, 64); version > latestVersion {
+			latestVersion = version
+			metadataFile = filepath.Join(metadataDir, info.Name())
+		}
+	}
+	if len(metadataFile) == 0 {
+		return "", fmt.Errorf("metadata files not found in %s", metadataDir)
+	}
+	return metadataFile, nil
+}
+
+// icebergType starrocks type of the iceberg type
+func (c *IcebergSource) icebergType(raw json.RawMessage) (string, error) {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		if decimalType, ok := c.decimalType(primitive); ok {
+			return decimalType, nil
+		}
+		switch primitive {
+		case "boolean":
+			return "BOOLEAN", nil
+		case "int":
+			return "INT", nil
+		case "long":
+			return "BIGINT", nil
+		case "float":
+			return "FLOAT", nil
+		case "double":
+			return "DOUBLE", nil
+		case "date":
+			return "DATE", nil
+		case "timestamp", "timestamptz", "timestamp_ns", "timestamptz_ns":
+			return "DATETIME", nil
+		case "uuid":
+			return "VARCHAR(36)", nil
+		case "string", "time", "binary":
+			return "STRING", nil
+		}
+		if strings.HasPrefix(primitive, "fixed[") {
+			return "STRING", nil
+		}
+		return "", fmt.Errorf("type `%s` is not supported", primitive)
+	}
+	nested := &icebergType{}
+	if err := json.Unmarshal(raw, nested); err != nil {
+		return "", err
+	}
+	switch nested.Type {
+	case "list":
+		elementType, err := c.icebergType(nested.Element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType), nil
+	case "map":
+		keyType, err := c.icebergType(nested.Key)
+		if err != nil {
+			return "", err
+		}
+		valueType, err := c.icebergType(nested.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("MAP<%s, %s>", keyType, valueType), nil
+	case "struct":
+		fields := []string{}
+		for _, field := range nested.Fields {
+			fieldType, err := c.icebergType(field.Type)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, fmt.Sprintf("`%s` %s", field.Name, fieldType))
+		}
+		return fmt.Sprintf("STRUCT<%s>", strings.Join(fields, ", ")), nil
+	}
+	return "", fmt.Errorf("type `%s` is not supported", nested.Type)
+}
+
+func (metadata *icebergMetadata) currentSchema() *icebergSchema {
+	for _, schema := range metadata.Schemas {
+		if schema.SchemaID == metadata.CurrentSchemaID {
+			return schema
+		}
+	}
+	// format version 1
+	return metadata.Schema
+}
+
+func (metadata *icebergMetadata) partitionFields() []*icebergPartitionField {
+	for _, spec := range metadata.PartitionSpecs {
+		if spec.SpecID == metadata.DefaultSpecID {
+			return spec.Fields
+		}
+	}
+	// format version 1
+	return metadata.PartitionSpec
+}
+
+func (metadata *icebergMetadata) sortFields() []*icebergSortField {
+	for _, sortOrder := range metadata.SortOrders {
+		if sortOrder.OrderID == metadata.DefaultSortOrderID {
+			return sortOrder.Fields
+		}
+	}
+	return []*icebergSortField{}
+}
+
+func (metadata *icebergMetadata) currentSnapshot() *icebergSnapshot {
+	for _, snapshot := range metadata.Snapshots {
+		if snapshot.SnapshotID == metadata.CurrentSnapshotID {
+			return snapshot
+		}
+	}
+	return nil
+}
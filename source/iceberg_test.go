@@ -0,0 +1,89 @@
+package source
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"starrocks-migrate-tool/conf"
+	"testing"
+)
+
+const icebergTestMetadata = `{
+  "format-version": 2,
+  "location": "s3://warehouse/db/orders",
+  "last-updated-ms": 1609459200000,
+  "current-schema-id": 1,
+  "schemas": [
+    {"schema-id": 0, "fields": [{"id": 1, "name": "id", "required": true, "type": "long"}]},
+    {"schema-id": 1, "identifier-field-ids": [1], "fields": [
+      {"id": 1, "name": "id", "required": true, "type": "long"},
+      {"id": 2, "name": "ts", "required": false, "type": "timestamptz", "doc": "created"},
+      {"id": 3, "name": "amount", "required": false, "type": "decimal(40, 2)"},
+      {"id": 4, "name": "tags", "required": false, "type": {"type": "list", "element-id": 6, "element": "string", "element-required": false}},
+      {"id": 5, "name": "attrs", "required": false, "type": {"type": "map", "key-id": 7, "key": "string", "value-id": 8, "value": {"type": "struct", "fields": [{"id": 9, "name": "v", "required": false, "type": "int"}]}}}
+    ]}
+  ],
+  "default-spec-id": 0,
+  "partition-specs": [{"spec-id": 0, "fields": [{"name": "ts_day", "transform": "day", "source-id": 2, "field-id": 1000}, {"name": "id_bucket", "transform": "bucket[16]", "source-id": 1, "field-id": 1001}]}],
+  "default-sort-order-id": 1,
+  "sort-orders": [{"order-id": 1, "fields": [{"transform": "identity", "source-id": 1, "direction": "asc", "null-order": "nulls-first"}]}],
+  "current-snapshot-id": 2,
+  "snapshots": [
+    {"snapshot-id": 1, "timestamp-ms": 1577836800000, "summary": {"total-records": "10", "total-files-size": "100"}},
+    {"snapshot-id": 2, "timestamp-ms": 1609459200000, "summary": {"total-records": "20", "total-files-size": "200"}}
+  ]
+}`
+
+func TestIcebergSource_Build(t *testing.T) {
+	dir := t.TempDir()
+	metadataDir := filepath.Join(dir, "db", "orders", "metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the latest version without version hints
+	ioutil.WriteFile(filepath.Join(metadataDir, "00001-a.metadata.json"), []byte(`{"format-version": 1}`), 0644)
+	ioutil.WriteFile(filepath.Join(metadataDir, "00002-b.metadata.json"), []byte(icebergTestMetadata), 0644)
+	rule := &conf.TableRule{DatabasePattern: "^db$", TablePattern: ".*"}
+	source := new(IcebergSource).Construct(&conf.Config{DBPath: dir, UseDecimalV3: true, TableRules: []*conf.TableRule{rule}}).(*IcebergSource)
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tableColumns := source.GetRuledTablesMap()[rule][0]
+	if tableColumns.Table.TABLE_ROWS != 20 || tableColumns.Table.DATA_LENGTH != 200 || tableColumns.Table.CREATE_TIME.Year() != 2020 {
+		t.Errorf("Table = %v", tableColumns.Table)
+	}
+	wantTypes := []string{"BIGINT", "DATETIME", "STRING", "ARRAY<STRING>", "MAP<STRING, STRUCT<`v` INT>>"}
+	for idx, column := range tableColumns.Columns {
+		if column.COLUMN_TYPE != wantTypes[idx] {
+			t.Errorf("column [%s] type = %v, want %v", column.COLUMN_NAME, column.COLUMN_TYPE, wantTypes[idx])
+		}
+	}
+	if id, ts := tableColumns.Columns[0], tableColumns.Columns[1]; id.IS_NULLABLE != "NO" || id.SortKeyPosition != 1 || id.IsInPartitionKey ||
+		ts.DATA_TYPE != "datetime" || !ts.IsInPartitionKey || ts.COLUMN_COMMENT != "created" {
+		t.Errorf("Columns = %v, %v", id, ts)
+	}
+	if len(tableColumns.PrimaryKCU) != 1 || tableColumns.PrimaryKCU[0].COLUMN_NAME != "id" {
+		t.Errorf("PrimaryKCU = %v", tableColumns.PrimaryKCU)
+	}
+}
+
+func TestLakeSource_decimalType(t *testing.T) {
+	tests := []struct {
+		colType      string
+		useDecimalV3 bool
+		want         string
+	}{
+		{"decimal(38,2)", true, "DECIMAL(38, 2)"},
+		{"decimal(27, 9)", false, "DECIMAL(27, 9)"},
+		{"decimal(30,2)", false, "STRING"},
+		{"decimal(38,38)", true, "DECIMAL(38, 38)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.colType, func(t *testing.T) {
+			source := &lakeSource{DBSource: DBSource{config: &conf.Config{UseDecimalV3: tt.useDecimalV3}}}
+			if got, ok := source.decimalType(tt.colType); !ok || got != tt.want {
+				t.Errorf("decimalType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
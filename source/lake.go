@@ -0,0 +1,206 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strconv"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+var lakeDecimalTypeReg = regexp.MustCompile(`^decimal\(\s*(\d+)\s*,\s*(\d+)\s*\)$`)
+
+// lakeSource tables of the metadata files under `[db].path/<database>/<table>/`, read without connections,
+// the types of the columns are converted to starrocks by the sources reading them
+type lakeSource struct {
+	DBSource
+	// directory of the metadata files in the directories of the tables
+	metadataDir string
+}
+
+func (c *lakeSource) Databases() ([]string, error) {
+	return c.subDirs(c.config.DBPath, "")
+}
+
+func (c *lakeSource) Schemas(db string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (c *lakeSource) Tables(db, _ string) ([]string, error) {
+	return c.subDirs(filepath.Join(c.config.DBPath, db), c.metadataDir)
+}
+
+func (c *lakeSource) Sample(_, _, _ string, _ int) ([]map[string]interface{}, error) {
+	// data files are not read
+	rows := []map[string]interface{}{}
+	return rows, nil
+}
+
+func (c *lakeSource) Destroy() {
+}
+
+func (c *lakeSource) ResultConventers() int {
+	return common.ConvertToStarRocks | common.ConvertToStarRocksExternal
+}
+
+func (c *lakeSource) InitDB() error {
+	info, err := os.Stat(c.config.DBPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("[db].path `%s` is not a directory.", c.config.DBPath)
+	}
+	return nil
+}
+
+func (c *lakeSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns {
+	return c.ruledTablesMap
+}
+
+func (c *lakeSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.columnType(matchedTableRule, column, flinkType(column.COLUMN_TYPE), true)
+	columnStr := fmt.Sprintf("  `%s` %s", column.COLUMN_NAME, colDataType)
+	if column.IS_NULLABLE == "NO" {
+		columnStr += " NOT NULL"
+	}
+	return columnStr, nil
+}
+
+func (c *lakeSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	nullableStr := "NULL"
+	if column.IS_NULLABLE == "NO" {
+		nullableStr = "NOT NULL"
+	}
+	colDataType := c.columnType(matchedTableRule, column, column.COLUMN_TYPE, false)
+	columnStr := fmt.Sprintf("  `%s` %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, c.encodeComment(column.COLUMN_COMMENT))
+	return columnStr, nil
+}
+
+func (c *lakeSource) GetFlinkConnectorName() string {
+	return ""
+}
+
+func (c *lakeSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	return nil
+}
+
+func (c *lakeSource) CombineSchemaName() bool {
+	return false
+}
+
+// build reads the tables matching the rules by readTable
+func (c *lakeSource) build(readTable func(table *model.Table, dir string) ([]*model.Column, []*model.KeyColumnUsage, error)) error {
+	dbList, err := c.Databases()
+	if err != nil {
+		return err
+	}
+	matchedTables := []*model.Table{}
+	allColumns := []*model.Column{}
+	keyColumnUsageRows := []*model.KeyColumnUsage{}
+	for _, db := range dbList {
+		tableNames, err := c.Tables(db, "")
+		if err != nil {
+			return err
+		}
+		for _, tableName := range tableNames {
+			if c.matchTableRule(db, db, tableName) == nil {
+				continue
+			}
+			table := &model.Table{
+				ModelBase: model.ModelBase{
+					TABLE_CATALOG: db,
+					TABLE_SCHEMA:  db,
+					TABLE_NAME:    tableName,
+				},
+			}
+			columns, kcuList, err := readTable(table, filepath.Join(c.config.DBPath, db, tableName))
+			if err != nil {
+				return fmt.Errorf("Table [%s.%s]: %s", db, tableName, err.Error())
+			}
+			matchedTables = append(matchedTables, table)
+			allColumns = append(allColumns, columns...)
+			keyColumnUsageRows = append(keyColumnUsageRows, kcuList...)
+		}
+	}
+	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
+	if len(c.ruledTablesMap) == 0 {
+		return errors.New("No matching table columns found.")
+	}
+	return nil
+}
+
+// subDirs directories in the dir, only those with the marker directory if not empty
+func (c *lakeSource) subDirs(dir, marker string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{}
+	for _, info := range infos {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		if len(marker) > 0 {
+			if markerInfo, err := os.Stat(filepath.Join(dir, info.Name(), marker)); err != nil || !markerInfo.IsDir() {
+				continue
+			}
+		}
+		dirs = append(dirs, info.Name())
+	}
+	return dirs, nil
+}
+
+// newColumn column of the converted starrocks type, DATA_TYPE is the lower case name of the type
+func (c *lakeSource) newColumn(table *model.Table, name, colType string, position int, nullable bool, comment string) *model.Column {
+	dataType := strings.ToLower(colType)
+	if idx := strings.IndexAny(dataType, "(<"); idx > 0 {
+		dataType = dataType[:idx]
+	}
+	isNullable := "YES"
+	if !nullable {
+		isNullable = "NO"
+	}
+	return &model.Column{
+		ModelBase:        table.ModelBase,
+		COLUMN_NAME:      name,
+		ORDINAL_POSITION: uint64(position),
+		IS_NULLABLE:      isNullable,
+		DATA_TYPE:        dataType,
+		COLUMN_TYPE:      colType,
+		COLUMN_COMMENT:   comment,
+	}
+}
+
+// decimalType DECIMAL(p, s) of `decimal(p,s)`, STRING if the precision is beyond the decimal version
+func (c *lakeSource) decimalType(colType string) (string, bool) {
+	matches := lakeDecimalTypeReg.FindStringSubmatch(colType)
+	if len(matches) == 0 {
+		return "", false
+	}
+	precision, _ := strconv.ParseUint(matches[1], 10, 64)
+	scale, _ := strconv.ParseUint(matches[2], 10, 64)
+	if (!c.config.Supports(conf.CapabilityDecimalV3) && precision > 27) || precision > 38 {
+		return "STRING", true
+	}
+	return fmt.Sprintf("DECIMAL(%d, %d)", precision, scale), true
+}
+
+// findColumn column of the name, nil if not found
+func (c *lakeSource) findColumn(columns []*model.Column, name string) *model.Column {
+	column := funk.Find(columns, func(col *model.Column) bool {
+		return col.COLUMN_NAME == name
+	})
+	if column == nil {
+		return nil
+	}
+	return column.(*model.Column)
+}
@@ -0,0 +1,109 @@
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+)
+
+// `json_valid(`doc`)` of the check constraints of the json columns, aliases of longtext
+var mariadbJSONCheckReg = regexp.MustCompile("(?i)^json_valid\\(`([^`]+)`\\)$")
+
+// `nextval(`db`.`seq`)` of the columns filled by the sequences
+var mariadbSequenceReg = regexp.MustCompile(`(?i)^nextval\s*\(`)
+
+// max lengths of the text forms of the types of mariadb
+var mariadbTextTypes = map[string]int64{"uuid": 36, "inet4": 15, "inet6": 39}
+
+type mariadbCheckConstraint struct {
+	CONSTRAINT_SCHEMA string `gorm:"column:CONSTRAINT_SCHEMA"`
+	TABLE_NAME        string `gorm:"column:TABLE_NAME"`
+	CHECK_CLAUSE      string `gorm:"column:CHECK_CLAUSE"`
+}
+
+// MariaDBSource system versioned tables are migrated along with the base tables, the sequences are not,
+// the columns filled by them are auto increment columns
+type MariaDBSource struct {
+	MySQLSource
+}
+
+func (c *MariaDBSource) Construct(config *conf.Config) IDBSource {
+	c.MySQLSource.Construct(config)
+	c.tableTypes = []string{"BASE TABLE", "SYSTEM VERSIONED"}
+	return c
+}
+
+func (c *MariaDBSource) Build() (IDBSourceProvider, error) {
+	if _, err := c.MySQLSource.Build(); err != nil {
+		return c, err
+	}
+	checks := []*mariadbCheckConstraint{}
+	c.db.Raw("SELECT CONSTRAINT_SCHEMA, TABLE_NAME, CHECK_CLAUSE FROM information_schema.check_constraints").Scan(&checks)
+	for _, tableColumnsList := range c.ruledTablesMap {
+		for _, tableColumns := range tableColumnsList {
+			c.normalizeColumns(tableColumns.Table, tableColumns.Columns, checks)
+		}
+	}
+	return c, nil
+}
+
+// GetFlinkSpecialProps none, mysql-cdc reads the binlog of mariadb as the one of mysql,
+// the offsets are the binlog positions as `SHOW MASTER STATUS` of mariadb has no gtid sets of mysql,
+// and the server ids are generated for mariadb along with mysql
+func (c *MariaDBSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	return nil
+}
+
+// normalizeColumns the columns in the forms of mysql
+func (c *MariaDBSource) normalizeColumns(table *model.Table, columns []*model.Column, checks []*mariadbCheckConstraint) {
+	jsonColumns := []string{}
+	for _, check := range checks {
+		if check.CONSTRAINT_SCHEMA != table.TABLE_SCHEMA || check.TABLE_NAME != table.TABLE_NAME {
+			continue
+		}
+		if matches := mariadbJSONCheckReg.FindStringSubmatch(strings.TrimSpace(check.CHECK_CLAUSE)); len(matches) > 0 {
+			jsonColumns = append(jsonColumns, matches[1])
+		}
+	}
+	for _, column := range columns {
+		// 1. types
+		if length, ok := mariadbTextTypes[column.DATA_TYPE]; ok {
+			column.DATA_TYPE = "varchar"
+			column.COLUMN_TYPE = fmt.Sprintf("varchar(%d)", length)
+			column.CHARACTER_MAXIMUM_LENGTH = length
+			column.CHARACTER_SET_NAME = "ascii"
+		}
+		if column.DATA_TYPE == "longtext" {
+			for _, name := range jsonColumns {
+				if name == column.COLUMN_NAME {
+					column.DATA_TYPE = "json"
+					break
+				}
+			}
+		}
+		// 2. defaults, strings are quoted and the absent defaults are `NULL` since mariadb 10.2.7
+		if column.COLUMN_DEFAULT == nil || column.DATA_TYPE == "bit" {
+			continue
+		}
+		columnDefault := strings.TrimSpace(*column.COLUMN_DEFAULT)
+		if strings.EqualFold(columnDefault, "NULL") {
+			column.COLUMN_DEFAULT = nil
+			continue
+		}
+		if matches := stringLiteralReg.FindStringSubmatch(columnDefault); len(matches) > 0 {
+			literal := strings.Replace(matches[1], "''", "'", -1)
+			column.COLUMN_DEFAULT = &literal
+			continue
+		}
+		if mariadbSequenceReg.MatchString(columnDefault) {
+			column.COLUMN_DEFAULT = nil
+			column.EXTRA = strings.TrimSpace(column.EXTRA + " auto_increment")
+			continue
+		}
+		if !numberLiteralReg.MatchString(columnDefault) && !strings.Contains(column.EXTRA, "DEFAULT_GENERATED") {
+			column.EXTRA = strings.TrimSpace(column.EXTRA + " DEFAULT_GENERATED")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestMariaDBSource_normalizeColumns(t *testing.T) {
+	literal := "'it''s'"
+	null := "NULL"
+	number := "0"
+	currentTimestamp := "current_timestamp()"
+	uuid := "uuid()"
+	sequence := "nextval(`shop`.`order_seq`)"
+	date := "'2021-01-01'"
+	base := model.ModelBase{TABLE_SCHEMA: "shop", TABLE_NAME: "orders"}
+	tests := []struct {
+		name        string
+		column      *model.Column
+		want        string
+		wantWarning bool
+	}{
+		{"sequence", &model.Column{ModelBase: base, COLUMN_NAME: "id", DATA_TYPE: "bigint", COLUMN_TYPE: "bigint(20)", IS_NULLABLE: "NO", COLUMN_DEFAULT: &sequence}, "  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT \"\"", false},
+		{"literal", &model.Column{ModelBase: base, COLUMN_NAME: "name", DATA_TYPE: "varchar", IS_NULLABLE: "NO", COLUMN_DEFAULT: &literal}, "  `name` STRING NOT NULL DEFAULT \"it's\" COMMENT \"\"", false},
+		{"null", &model.Column{ModelBase: base, COLUMN_NAME: "remark", DATA_TYPE: "varchar", IS_NULLABLE: "YES", COLUMN_DEFAULT: &null}, "  `remark` STRING NULL  COMMENT \"\"", false},
+		{"number", &model.Column{ModelBase: base, COLUMN_NAME: "qty", DATA_TYPE: "int", COLUMN_TYPE: "int(11)", IS_NULLABLE: "NO", COLUMN_DEFAULT: &number}, "  `qty` INT(11) NOT NULL DEFAULT \"0\" COMMENT \"\"", false},
+		{"current timestamp", &model.Column{ModelBase: base, COLUMN_NAME: "created", DATA_TYPE: "timestamp", IS_NULLABLE: "NO", COLUMN_DEFAULT: &currentTimestamp}, "  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT \"\"", false},
+		{"date", &model.Column{ModelBase: base, COLUMN_NAME: "dt", DATA_TYPE: "date", IS_NULLABLE: "NO", COLUMN_DEFAULT: &date}, "  `dt` DATE NOT NULL DEFAULT \"2021-01-01\" COMMENT \"\"", false},
+		{"uuid", &model.Column{ModelBase: base, COLUMN_NAME: "token", DATA_TYPE: "uuid", COLUMN_TYPE: "uuid", IS_NULLABLE: "YES", COLUMN_DEFAULT: &uuid}, "  `token` VARCHAR(36) NULL  COMMENT \"\"", true},
+		{"inet6", &model.Column{ModelBase: base, COLUMN_NAME: "ip", DATA_TYPE: "inet6", COLUMN_TYPE: "inet6", IS_NULLABLE: "YES"}, "  `ip` VARCHAR(39) NULL  COMMENT \"\"", false},
+		{"json", &model.Column{ModelBase: base, COLUMN_NAME: "doc", DATA_TYPE: "longtext", COLUMN_TYPE: "longtext", IS_NULLABLE: "YES"}, "  `doc` JSON NULL  COMMENT \"\"", false},
+		{"longtext", &model.Column{ModelBase: base, COLUMN_NAME: "body", DATA_TYPE: "longtext", COLUMN_TYPE: "longtext", IS_NULLABLE: "YES"}, "  `body` STRING NULL  COMMENT \"\"", false},
+	}
+	checks := []*mariadbCheckConstraint{
+		{CONSTRAINT_SCHEMA: "shop", TABLE_NAME: "orders", CHECK_CLAUSE: "json_valid(`doc`)"},
+		{CONSTRAINT_SCHEMA: "shop", TABLE_NAME: "users", CHECK_CLAUSE: "json_valid(`body`)"},
+	}
+	source := new(MariaDBSource).Construct(&conf.Config{StarRocksVersion: "3.1.0"}).(*MariaDBSource)
+	table := &model.Table{ModelBase: base}
+	rule := &conf.TableRule{StringMapping: conf.StringMappingVarchar}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source.normalizeColumns(table, []*model.Column{tt.column}, checks)
+			got, err := source.FormatStarRocksColumnDef(rule, table, tt.column)
+			if err != nil || got != tt.want {
+				t.Errorf("FormatStarRocksColumnDef() = %v, %v, want %v", got, err, tt.want)
+			}
+			if (len(tt.column.DefaultWarnings) > 0) != tt.wantWarning {
+				t.Errorf("FormatStarRocksColumnDef() warning = %v, wantWarning %v", tt.column.DefaultWarnings, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/thoas/go-funk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// documents sampled from each collection to infer the schema without `[other].sample_rows`
+const mongoDefaultSampleSize = 1000
+
+var mongoArrayTypeReg = regexp.MustCompile(`^ARRAY<(.*)>$`)
+
+// mongoSampler documents of the collections, a mongod or the stand-ins of the tests
+type mongoSampler interface {
+	Databases() ([]string, error)
+	Collections(db string) ([]string, error)
+	// Statistics the documents and the bytes of the collection
+	Statistics(db, collection string) (uint64, uint64, error)
+	SampleDocuments(db, collection string, limit int) ([]bson.D, error)
+	Close()
+}
+
+// mongoClient samples the collections by `$sample`
+type mongoClient struct {
+	client *mongo.Client
+}
+
+func (c *mongoClient) Databases() ([]string, error) {
+	return c.client.ListDatabaseNames(context.Background(), bson.D{{Key: "name", Value: bson.D{{Key: "$nin", Value: bson.A{"admin", "config", "local"}}}}})
+}
+
+func (c *mongoClient) Collections(db string) ([]string, error) {
+	// views and time series collections are not captured by the change streams
+	return c.client.Database(db).ListCollectionNames(context.Background(), bson.D{{Key: "type", Value: "collection"}, {Key: "name", Value: bson.D{{Key: "$not", Value: primitive.Regex{Pattern: "^system\\."}}}}})
+}
+
+func (c *mongoClient) Statistics(db, collection string) (uint64, uint64, error) {
+	stats := struct {
+		Count int64 `bson:"count"`
+		Size  int64 `bson:"size"`
+	}{}
+	err := c.client.Database(db).RunCommand(context.Background(), bson.D{{Key: "collStats", Value: collection}}).Decode(&stats)
+	return uint64(stats.Count), uint64(stats.Size), err
+}
+
+func (c *mongoClient) SampleDocuments(db, collection string, limit int) ([]bson.D, error) {
+	ctx := context.Background()
+	cursor, err := c.client.Database(db).Collection(collection).Aggregate(ctx, mongo.Pipeline{{{Key: "$sample", Value: bson.D{{Key: "size", Value: limit}}}}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	documents := []bson.D{}
+	err = cursor.All(ctx, &documents)
+	return documents, err
+}
+
+func (c *mongoClient) Close() {
+	c.client.Disconnect(context.Background())
+}
+
+// MongoDBSource collections as tables of the schema inferred from the sampled documents,
+// nested documents and the fields of mixed types are JSON columns
+type MongoDBSource struct {
+	DBSource
+	sampler mongoSampler
+}
+
+func (c *MongoDBSource) Construct(config *conf.Config) IDBSource {
+	c.config = config
+	for _, tableRule := range c.config.TableRules {
+		tableRule.SchemaPattern = ".*"
+	}
+	return c
+}
+
+func (c *MongoDBSource) InitDB() error {
+	clientOptions := options.Client().ApplyURI(fmt.Sprintf("mongodb://%s:%d", c.config.DBHost, c.config.DBPort))
+	if len(c.config.DBUser) > 0 {
+		clientOptions.SetAuth(options.Credential{Username: c.config.DBUser, Password: c.config.DBPassword})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		return err
+	}
+	c.sampler = &mongoClient{client: client}
+	return nil
+}
+
+func (c *MongoDBSource) Databases() ([]string, error) {
+	return c.sampler.Databases()
+}
+
+func (c *MongoDBSource) Schemas(db string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (c *MongoDBSource) Tables(db, _ string) ([]string, error) {
+	return c.sampler.Collections(db)
+}
+
+func (c *MongoDBSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		documents, err := c.sampler.SampleDocuments(db, table, limit)
+		if err != nil {
+			return nil, err
+		}
+		return c.sampleRows(documents), nil
+	})
+}
+
+// sampleRows the rows of the sampled documents
+func (c *MongoDBSource) sampleRows(documents []bson.D) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	for _, document := range documents {
+		row := map[string]interface{}{}
+		for _, elem := range document {
+			row[elem.Key] = c.sampleValue(elem.Value)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (c *MongoDBSource) Destroy() {
+	if c.sampler == nil {
+		return
+	}
+	c.sampler.Close()
+}
+
+func (c *MongoDBSource) ResultConventers() int {
+	return common.ConvertToStarRocks | common.ConvertToFlink
+}
+
+func (c *MongoDBSource) Build() (IDBSourceProvider, error) {
+	dbList, err := c.sampler.Databases()
+	if err != nil {
+		return c, err
+	}
+	sampleSize := mongoDefaultSampleSize
+	if c.config.SampleRows > 0 {
+		sampleSize = int(c.config.SampleRows)
+	}
+	matchedTables := []*model.Table{}
+	allColumns := []*model.Column{}
+	keyColumnUsageRows := []*model.KeyColumnUsage{}
+	for _, db := range dbList {
+		collections, err := c.sampler.Collections(db)
+		if err != nil {
+			return c, err
+		}
+		for _, collection := range collections {
+			if c.matchTableRule(db, db, collection) == nil {
+				continue
+			}
+			table := &model.Table{
+				ModelBase: model.ModelBase{
+					TABLE_CATALOG: db,
+					TABLE_SCHEMA:  db,
+					TABLE_NAME:    collection,
+				},
+			}
+			if table.TABLE_ROWS, table.DATA_LENGTH, err = c.sampler.Statistics(db, collection); err != nil {
+				glog.Warningf("failed to get the statistics of collection [%s.%s]: %v", db, collection, err)
+			}
+			documents, err := c.sampler.SampleDocuments(db, collection, sampleSize)
+			if err != nil {
+				return c, fmt.Errorf("Collection [%s.%s]: %s", db, collection, err.Error())
+			}
+			// documents of `[other].sample_rows` are the samples of the converters and the reporters as well
+			c.sampleOnce(db, db, collection, sampleSize, func() ([]map[string]interface{}, error) {
+				return c.sampleRows(documents), nil
+			})
+			columns := c.inferColumns(table, documents)
+			matchedTables = append(matchedTables, table)
+			allColumns = append(allColumns, columns...)
+			// `_id` is the primary key required by mongodb-cdc
+			keyColumnUsageRows = append(keyColumnUsageRows, &model.KeyColumnUsage{
+				ModelBase:        table.ModelBase,
+				COLUMN_NAME:      "_id",
+				CONSTRAINT_NAME:  "PRIMARY",
+				CONSTRAINT_TYPE:  common.CONSTRAINT_PRIMARY_KEY,
+				ORDINAL_POSITION: 1,
+			})
+		}
+	}
+	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
+	if len(c.ruledTablesMap) == 0 {
+		return c, errors.New("No matching table columns found.")
+	}
+	return c, nil
+}
+
+// inferColumns the fields of the documents in the order first seen, `_id` first,
+// the types of each field are unified and the fields missing or null in any document are nullable
+func (c *MongoDBSource) inferColumns(table *model.Table, documents []bson.D) []*model.Column {
+	names := []string{"_id"}
+	types := map[string]string{"_id": ""}
+	presences := map[string]int{}
+	for _, document := range documents {
+		for _, elem := range document {
+			if _, ok := types[elem.Key]; !ok {
+				names = append(names, elem.Key)
+			}
+			valueType := c.valueType(elem.Value)
+			types[elem.Key] = c.unifyTypes(types[elem.Key], valueType)
+			if len(valueType) > 0 {
+				presences[elem.Key]++
+			}
+		}
+	}
+	columns := []*model.Column{}
+	for idx, name := range names {
+		colType := types[name]
+		if len(colType) == 0 || (name == "_id" && (colType == "JSON" || strings.HasPrefix(colType, "ARRAY"))) {
+			// only nulls sampled, or the json of compound identifiers
+			colType = "STRING"
+		}
+		colType = strings.Replace(colType, "ARRAY<>", "ARRAY<STRING>", -1)
+		colType = strings.Replace(colType, "DECIMAL", c.decimalType(), -1)
+		dataType := strings.ToLower(colType)
+		if idx := strings.IndexAny(dataType, "(<"); idx > 0 {
+			dataType = dataType[:idx]
+		}
+		isNullable := "YES"
+		if len(documents) > 0 && presences[name] == len(documents) {
+			isNullable = "NO"
+		}
+		columns = append(columns, &model.Column{
+			ModelBase:        table.ModelBase,
+			COLUMN_NAME:      name,
+			ORDINAL_POSITION: uint64(idx + 1),
+			IS_NULLABLE:      isNullable,
+			DATA_TYPE:        dataType,
+			COLUMN_TYPE:      colType,
+		})
+	}
+	return columns
+}
+
+// valueType starrocks type of the bson value, empty for nulls, `ARRAY<>` for empty arrays, `DECIMAL` for decimal128
+func (c *MongoDBSource) valueType(value interface{}) string {
+	switch v := value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return ""
+	case bool:
+		return "BOOLEAN"
+	case int32:
+		return "INT"
+	case int64:
+		return "BIGINT"
+	case float64:
+		return "DOUBLE"
+	case primitive.Decimal128:
+		return "DECIMAL"
+	case primitive.ObjectID:
+		return "VARCHAR(24)"
+	case primitive.DateTime, primitive.Timestamp, time.Time:
+		return "DATETIME"
+	case bson.D, bson.M:
+		return "JSON"
+	case bson.A:
+		elementType := ""
+		for _, element := range v {
+			elementType = c.unifyTypes(elementType, c.valueType(element))
+		}
+		if elementType == "JSON" {
+			return "JSON"
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType)
+	}
+	// strings, binaries, regular expressions, javascript and so on
+	return "STRING"
+}
+
+// unifyTypes the type of the values of both types, numbers are widened, the other mixed scalars are strings
+// and the mixed documents or arrays are JSON
+func (c *MongoDBSource) unifyTypes(left, right string) string {
+	if len(left) == 0 || left == right {
+		return right
+	}
+	if len(right) == 0 {
+		return left
+	}
+	leftElement, rightElement := mongoArrayTypeReg.FindStringSubmatch(left), mongoArrayTypeReg.FindStringSubmatch(right)
+	if len(leftElement) > 0 && len(rightElement) > 0 {
+		elementType := c.unifyTypes(leftElement[1], rightElement[1])
+		if elementType == "JSON" {
+			return "JSON"
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType)
+	}
+	if len(leftElement) > 0 || len(rightElement) > 0 || left == "JSON" || right == "JSON" {
+		return "JSON"
+	}
+	numbers := []string{"INT", "BIGINT", "DECIMAL", "DOUBLE"}
+	leftIdx, rightIdx := funk.IndexOfString(numbers, left), funk.IndexOfString(numbers, right)
+	if leftIdx >= 0 && rightIdx >= 0 {
+		if leftIdx > rightIdx {
+			return left
+		}
+		return right
+	}
+	return "STRING"
+}
+
+func (c *MongoDBSource) decimalType() string {
+	if !c.config.Supports(conf.CapabilityDecimalV3) {
+		return "DECIMAL(27, 9)"
+	}
+	return "DECIMAL(38, 10)"
+}
+
+// sampleValue strings of the identifiers and the json of the documents and the arrays
+func (c *MongoDBSource) sampleValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time()
+	case bson.D, bson.M, bson.A:
+		content, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+		if err != nil {
+			return nil
+		}
+		// unwrap the value
+		return strings.TrimSuffix(strings.TrimPrefix(string(content), `{"v":`), "}")
+	}
+	return value
+}
+
+func (c *MongoDBSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns {
+	return c.ruledTablesMap
+}
+
+func (c *MongoDBSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	// documents of mongodb-cdc are read as json strings
+	colDataType := c.columnType(matchedTableRule, column, flinkType(column.COLUMN_TYPE), true)
+	columnStr := fmt.Sprintf("  `%s` %s", column.COLUMN_NAME, colDataType)
+	if column.COLUMN_NAME == "_id" {
+		columnStr += " NOT NULL"
+	}
+	return columnStr, nil
+}
+
+func (c *MongoDBSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	// the fields present in all the samples may still be missing in the other documents
+	nullableStr := "NULL"
+	if column.COLUMN_NAME == "_id" {
+		nullableStr = "NOT NULL"
+	}
+	colDataType := c.columnType(matchedTableRule, column, column.COLUMN_TYPE, false)
+	columnStr := fmt.Sprintf("  `%s` %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, c.encodeComment(column.COLUMN_COMMENT))
+	return columnStr, nil
+}
+
+func (c *MongoDBSource) GetFlinkConnectorName() string {
+	return "mongodb-cdc"
+}
+
+func (c *MongoDBSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	specialProps := map[string]string{
+		"hosts": fmt.Sprintf("%s:%d", c.config.DBHost, c.config.DBPort),
+	}
+	if len(c.config.DBUser) > 0 {
+		specialProps["username"] = c.config.DBUser
+		specialProps["password"] = c.config.DBPassword
+	}
+	return specialProps
+}
+
+func (c *MongoDBSource) CombineSchemaName() bool {
+	return false
+}
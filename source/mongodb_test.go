@@ -0,0 +1,141 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeMongoSampler in-process stand-in of a mongod
+type fakeMongoSampler struct {
+	documents map[string][]bson.D
+	samples   int
+}
+
+func (c *fakeMongoSampler) Databases() ([]string, error) {
+	return []string{"shop"}, nil
+}
+
+func (c *fakeMongoSampler) Collections(db string) ([]string, error) {
+	names := []string{}
+	for name := range c.documents {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (c *fakeMongoSampler) Statistics(db, collection string) (uint64, uint64, error) {
+	return uint64(len(c.documents[collection])), 1024, nil
+}
+
+func (c *fakeMongoSampler) SampleDocuments(db, collection string, limit int) ([]bson.D, error) {
+	c.samples++
+	return c.documents[collection], nil
+}
+
+func (c *fakeMongoSampler) Close() {
+}
+
+func TestMongoDBSource_Build(t *testing.T) {
+	sampler := &fakeMongoSampler{documents: map[string][]bson.D{"orders": {
+		{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "amount", Value: int32(10)},
+			{Key: "tags", Value: bson.A{"a", "b"}},
+			{Key: "address", Value: bson.D{{Key: "city", Value: "x"}}},
+			{Key: "created", Value: primitive.NewDateTimeFromTime(time.Now())},
+			{Key: "code", Value: "c1"},
+		},
+		{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "amount", Value: 10.5},
+			{Key: "tags", Value: bson.A{}},
+			{Key: "address", Value: nil},
+			{Key: "code", Value: int64(2)},
+			{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "s1"}}}},
+		},
+	}}}
+	rule := &conf.TableRule{DatabasePattern: "^shop$", TablePattern: ".*"}
+	source := new(MongoDBSource).Construct(&conf.Config{DBHost: "127.0.0.1", DBPort: 27017, UseDecimalV3: true, TableRules: []*conf.TableRule{rule}}).(*MongoDBSource)
+	source.sampler = sampler
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tableColumns := source.GetRuledTablesMap()[rule][0]
+	if len(tableColumns.PrimaryKCU) != 1 || tableColumns.PrimaryKCU[0].COLUMN_NAME != "_id" {
+		t.Errorf("PrimaryKCU = %v", tableColumns.PrimaryKCU)
+	}
+	want := map[string]string{
+		"_id":     "  `_id` VARCHAR(24) NOT NULL COMMENT \"\"",
+		"amount":  "  `amount` DOUBLE NULL COMMENT \"\"",
+		"tags":    "  `tags` ARRAY<STRING> NULL COMMENT \"\"",
+		"address": "  `address` JSON NULL COMMENT \"\"",
+		"created": "  `created` DATETIME NULL COMMENT \"\"",
+		"code":    "  `code` STRING NULL COMMENT \"\"",
+		"items":   "  `items` JSON NULL COMMENT \"\"",
+	}
+	if len(tableColumns.Columns) != len(want) {
+		t.Fatalf("Columns = %v", tableColumns.Columns)
+	}
+	for _, column := range tableColumns.Columns {
+		if got, _ := source.FormatStarRocksColumnDef(rule, tableColumns.Table, column); got != want[column.COLUMN_NAME] {
+			t.Errorf("FormatStarRocksColumnDef() = %v, want %v", got, want[column.COLUMN_NAME])
+		}
+	}
+	// present in all the samples
+	if amount, address := tableColumns.Columns[1], tableColumns.Columns[3]; amount.IS_NULLABLE != "NO" || address.IS_NULLABLE != "YES" {
+		t.Errorf("IS_NULLABLE = %v, %v", amount.IS_NULLABLE, address.IS_NULLABLE)
+	}
+	if props := source.GetFlinkSpecialProps(rule, tableColumns.Table); props["hosts"] != "127.0.0.1:27017" {
+		t.Errorf("GetFlinkSpecialProps() = %v", props)
+	}
+}
+
+func TestMongoDBSource_Sample(t *testing.T) {
+	sampler := &fakeMongoSampler{documents: map[string][]bson.D{"orders": {
+		{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "amount", Value: int32(10)}},
+		{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "amount", Value: int32(20)}},
+	}}}
+	rule := &conf.TableRule{DatabasePattern: "^shop$", TablePattern: ".*"}
+	source := new(MongoDBSource).Construct(&conf.Config{SampleRows: 10, TableRules: []*conf.TableRule{rule}}).(*MongoDBSource)
+	source.sampler = sampler
+	if _, err := source.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	// the documents sampled to infer the schema
+	for i := 0; i < 2; i++ {
+		if rows, err := source.Sample("shop", "shop", "orders", 10); err != nil || len(rows) != 2 {
+			t.Errorf("Sample() = %v, %v", rows, err)
+		}
+	}
+	if sampler.samples != 1 {
+		t.Errorf("SampleDocuments() called %d times", sampler.samples)
+	}
+}
+
+func TestMongoDBSource_unifyTypes(t *testing.T) {
+	tests := []struct {
+		left  string
+		right string
+		want  string
+	}{
+		{"", "INT", "INT"},
+		{"INT", "BIGINT", "BIGINT"},
+		{"DECIMAL", "INT", "DECIMAL"},
+		{"DOUBLE", "DECIMAL", "DOUBLE"},
+		{"STRING", "INT", "STRING"},
+		{"ARRAY<>", "ARRAY<INT>", "ARRAY<INT>"},
+		{"ARRAY<INT>", "ARRAY<STRING>", "ARRAY<STRING>"},
+		{"ARRAY<INT>", "INT", "JSON"},
+		{"JSON", "STRING", "JSON"},
+	}
+	source := &MongoDBSource{}
+	for _, tt := range tests {
+		if got := source.unifyTypes(tt.left, tt.right); got != tt.want {
+			t.Errorf("unifyTypes(%v, %v) = %v, want %v", tt.left, tt.right, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package source
 
 import (
 	"fmt"
+	"regexp"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
@@ -18,10 +19,25 @@ import (
 
 type MySQLSource struct {
 	DBSource
+	// TABLE_TYPE of the migrated tables
+	tableTypes []string
 }
 
+type mysqlGeneratedColumn struct {
+	TABLE_SCHEMA          string `gorm:"column:TABLE_SCHEMA"`
+	TABLE_NAME            string `gorm:"column:TABLE_NAME"`
+	COLUMN_NAME           string `gorm:"column:COLUMN_NAME"`
+	GENERATION_EXPRESSION string `gorm:"column:GENERATION_EXPRESSION"`
+}
+
+var mysqlSpatialTypes = []string{"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection"}
+
+// `_utf8mb4\'$.name\'` -> `'$.name'`
+var mysqlCharsetIntroducerReg = regexp.MustCompile(`(^|[^0-9a-zA-Z_'])_[0-9a-zA-Z]+'`)
+
 func (c *MySQLSource) Construct(config *conf.Config) IDBSource {
 	c.config = config
+	c.tableTypes = []string{"BASE TABLE"}
 	for _, tableRule := range c.config.TableRules {
 		tableRule.SchemaPattern = ".*"
 	}
@@ -66,10 +82,12 @@ func (c *MySQLSource) Tables(db, _ string) ([]string, error) {
 	}).([]string), nil
 }
 
-func (c *MySQLSource) Sample(db, _, table string, limit int) ([]map[string]interface{}, error) {
-	results := []map[string]interface{}{}
-	c.db.Raw(fmt.Sprintf("SELECT * FROM `%s`.`%s` limit %d;", db, table, limit)).Find(&results)
-	return results, nil
+func (c *MySQLSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		results := []map[string]interface{}{}
+		err := c.db.Raw(fmt.Sprintf("SELECT * FROM `%s`.`%s` limit %d;", db, table, limit)).Find(&results).Error
+		return results, err
+	})
 }
 
 func (c *MySQLSource) Destroy() {
@@ -106,7 +124,7 @@ func (c *MySQLSource) InitDB() error {
 
 func (c *MySQLSource) Build() (IDBSourceProvider, error) {
 	matchedTables := []*model.Table{}
-	err := c.db.Where("TABLE_TYPE=?", "BASE TABLE").Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&matchedTables).Error
+	err := c.db.Where("TABLE_TYPE IN ?", c.tableTypes).Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&matchedTables).Error
 	if err != nil {
 		return nil, errors.WithMessage(err, "Failed to get rows from information_schema.tables.")
 	}
@@ -129,8 +147,32 @@ func (c *MySQLSource) Build() (IDBSourceProvider, error) {
 	for _, column := range allColumns {
 		column.TABLE_CATALOG = column.TABLE_SCHEMA
 	}
+	// cardinality of the leading columns of indexes
+	statistics := []*columnStatistic{}
+	c.db.Raw("SELECT TABLE_SCHEMA AS table_schema, TABLE_NAME AS table_name, COLUMN_NAME AS column_name, max(CARDINALITY) AS cardinality FROM information_schema.statistics WHERE SEQ_IN_INDEX = 1 AND CARDINALITY IS NOT NULL GROUP BY TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME").Scan(&statistics)
+	c.applyStatistics(allColumns, statistics)
+	// innodb tables are clustered by the primary keys, the non-unique secondary index of the most selective leading column is the sort key,
+	// the first by the names among the ones as selective
+	sortKeyColumns := []*columnStatistic{}
+	c.db.Raw("SELECT s.TABLE_SCHEMA AS table_schema, s.TABLE_NAME AS table_name, s.COLUMN_NAME AS column_name, s.SEQ_IN_INDEX AS position FROM information_schema.statistics s " +
+		"WHERE s.NON_UNIQUE = 1 AND s.COLUMN_NAME IS NOT NULL AND s.INDEX_NAME = (SELECT i.INDEX_NAME FROM information_schema.statistics i WHERE i.TABLE_SCHEMA = s.TABLE_SCHEMA AND i.TABLE_NAME = s.TABLE_NAME AND i.NON_UNIQUE = 1 AND i.SEQ_IN_INDEX = 1 " +
+		"ORDER BY i.CARDINALITY DESC, i.INDEX_NAME LIMIT 1)").Scan(&sortKeyColumns)
+	c.applyStatistics(allColumns, sortKeyColumns)
+	// virtual and stored generated columns of mysql 5.7
+	generatedColumns := []*mysqlGeneratedColumn{}
+	c.db.Raw("SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, GENERATION_EXPRESSION FROM information_schema.columns WHERE EXTRA IN ('VIRTUAL GENERATED', 'STORED GENERATED')").Scan(&generatedColumns)
+	for _, generatedColumn := range generatedColumns {
+		for _, column := range allColumns {
+			if column.TABLE_SCHEMA == generatedColumn.TABLE_SCHEMA && column.TABLE_NAME == generatedColumn.TABLE_NAME && column.COLUMN_NAME == generatedColumn.COLUMN_NAME {
+				column.GenerationExpression = generatedColumn.GENERATION_EXPRESSION
+				break
+			}
+		}
+	}
 	keyColumnUsageRows := []*model.KeyColumnUsage{}
-	err = c.db.Model(&model.KeyColumnUsage{}).Find(&keyColumnUsageRows).Error
+	err = c.db.Table("information_schema.key_column_usage k").Select("k.*, t.CONSTRAINT_TYPE").
+		Joins("JOIN information_schema.table_constraints t ON t.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND t.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND t.TABLE_NAME = k.TABLE_NAME").
+		Find(&keyColumnUsageRows).Error
 	if err != nil {
 		return nil, errors.WithMessage(err, "Failed to get rows from information_schema.key_column_usage.")
 	}
@@ -149,7 +191,7 @@ func (c *MySQLSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableCol
 	return c.ruledTablesMap
 }
 
-func (c *MySQLSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *MySQLSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := "STRING"
 	switch column.DATA_TYPE {
 	case "char", "year", "varchar", "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "json":
@@ -197,7 +239,7 @@ func (c *MySQLSource) FormatFlinkColumnDef(table *model.Table, column *model.Col
 		colDataType = strings.ToUpper(column.DATA_TYPE)
 		break
 	case "decimal":
-		if (!c.config.UseDecimalV3 && column.NUMERIC_PRECISION > 27) || column.NUMERIC_PRECISION > 38 {
+		if (!c.config.Supports(conf.CapabilityDecimalV3) && column.NUMERIC_PRECISION > 27) || column.NUMERIC_PRECISION > 38 {
 			colDataType = "STRING"
 		} else {
 			colDataType = fmt.Sprintf("DECIMAL(%d, %d)", column.NUMERIC_PRECISION, column.NUMERIC_SCALE)
@@ -215,16 +257,31 @@ func (c *MySQLSource) FormatFlinkColumnDef(table *model.Table, column *model.Col
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, true)
 	columnStr := fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, colDataType, nullableStr)
 	return columnStr, nil
 }
 
-func (c *MySQLSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *MySQLSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := ""
 	switch column.DATA_TYPE {
-	case "char", "year", "varchar", "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "json", "binary", "varbinary", "set":
+	case "char":
+		colDataType = c.stringType(matchedTableRule, column, true)
+		break
+	case "varchar":
+		colDataType = c.stringType(matchedTableRule, column, false)
+		break
+	case "binary", "varbinary":
+		binaryColumn := *column
+		binaryColumn.CHARACTER_SET_NAME = "binary"
+		colDataType = c.stringType(matchedTableRule, &binaryColumn, column.DATA_TYPE == "binary")
+		break
+	case "year", "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "set":
 		colDataType = "STRING"
 		break
+	case "json":
+		colDataType = "JSON"
+		break
 	case "tinyint":
 		dataType := "TINYINT"
 		if isUnsigned(column.COLUMN_TYPE) {
@@ -277,7 +334,7 @@ func (c *MySQLSource) FormatStarRocksColumnDef(table *model.Table, column *model
 		colDataType = "DOUBLE"
 		break
 	case "decimal":
-		if (!c.config.UseDecimalV3 && column.NUMERIC_PRECISION > 27) || column.NUMERIC_PRECISION > 38 {
+		if (!c.config.Supports(conf.CapabilityDecimalV3) && column.NUMERIC_PRECISION > 27) || column.NUMERIC_PRECISION > 38 {
 			colDataType = "STRING"
 		} else {
 			colDataType = fmt.Sprintf("DECIMAL(%d, %d)", column.NUMERIC_PRECISION, column.NUMERIC_SCALE)
@@ -293,6 +350,24 @@ func (c *MySQLSource) FormatStarRocksColumnDef(table *model.Table, column *model
 		colDataType = "STRING"
 		break
 	}
+	columnComment := column.COLUMN_COMMENT
+	if funk.ContainsString(mysqlSpatialTypes, column.DATA_TYPE) {
+		// converted by the flink projection
+		colDataType = "STRING"
+		columnComment = strings.TrimSpace(fmt.Sprintf("%s WKT of %s", columnComment, column.DATA_TYPE))
+	}
+	if column.IsGenerated() {
+		expression, reasons := c.generatedColumnRewriter().rewrite(c.unescapeGenerationExpression(column.GenerationExpression))
+		for _, reason := range reasons {
+			column.AddDefaultWarning(fmt.Sprintf("generated column excluded: %s", reason))
+		}
+		if len(reasons) > 0 || !c.generatedColumnSupported(column) {
+			// generated columns not portable are excluded
+			return "", nil
+		}
+		colDataType = c.columnType(matchedTableRule, column, strings.TrimSpace(strings.Replace(strings.Replace(colDataType, "unsigned", "", -1), "UNSIGNED", "", -1)), false)
+		return fmt.Sprintf("  `%s` %s NULL AS %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, expression, c.encodeComment(columnComment)), nil
+	}
 
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
@@ -314,10 +389,17 @@ func (c *MySQLSource) FormatStarRocksColumnDef(table *model.Table, column *model
 				if column.DATA_TYPE == "datetime" || column.DATA_TYPE == "timestamp" {
 					dateTemplate = common.DATETIME_TEMPLATE
 				}
-				if len(dateTemplate) > 0 {
+				if c.isCurrentTimestamp(columnDefault) {
+					defaultStr = c.defaultClause(column, colDataType, columnDefault)
+				} else if strings.Contains(column.EXTRA, "DEFAULT_GENERATED") {
+					// expression defaults of mysql 8.0
+					defaultStr = c.defaultClause(column, colDataType, columnDefault)
+				} else if len(dateTemplate) > 0 {
 					dt, err := time.Parse(dateTemplate, columnDefault)
 					if err != nil {
+						// zero dates
 						nullableStr = "NULL"
+						column.AddDefaultWarning(fmt.Sprintf("default `%s` is not a valid date, the column is nullable instead", columnDefault))
 					} else {
 						columnDefault = dt.Format(dateTemplate)
 						defaultStr = fmt.Sprintf("DEFAULT \"%s\"", columnDefault)
@@ -332,18 +414,73 @@ func (c *MySQLSource) FormatStarRocksColumnDef(table *model.Table, column *model
 	}
 	colDataType = strings.Replace(colDataType, "unsigned", "", -1)
 	colDataType = strings.Replace(colDataType, "UNSIGNED", "", -1)
-	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
+	if strings.Contains(strings.ToLower(column.EXTRA), "on update") {
+		column.AddDefaultWarning(fmt.Sprintf("`%s` is not supported, the values are synchronized from the source", strings.TrimSpace(strings.Replace(column.EXTRA, "DEFAULT_GENERATED", "", -1))))
+	}
+	if column.IsAutoIncrement() {
+		if dataType, ok := c.autoIncrement(table, column, colDataType); ok {
+			colDataType = dataType
+			nullableStr = "NOT NULL"
+			defaultStr = "AUTO_INCREMENT"
+		}
+	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
+	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(columnComment))
 	return columnStr, nil
 }
 
+// unescapeGenerationExpression quotes are escaped and strings are prefixed with the charsets by mysql 8.0
+func (c *MySQLSource) unescapeGenerationExpression(expression string) string {
+	expression = strings.Replace(expression, "\\'", "'", -1)
+	return mysqlCharsetIntroducerReg.ReplaceAllString(expression, "$1'")
+}
+
+func (c *MySQLSource) generatedColumnRewriter() *sqlRewriter {
+	functions := map[string]sqlFunctionRewriter{}
+	for _, name := range []string{
+		"abs", "ceil", "floor", "round", "truncate", "power", "pow", "sqrt", "mod", "greatest", "least", "upper", "lower", "ltrim", "rtrim", "trim",
+		"replace", "substring", "substr", "left", "right", "reverse", "lpad", "rpad", "concat", "concat_ws", "length", "char_length", "md5",
+		"coalesce", "ifnull", "nullif", "if", "year", "month", "day", "hour", "minute", "second", "date", "datediff", "date_format", "from_unixtime", "unix_timestamp",
+	} {
+		functions[name] = sameSQLFunction(name)
+	}
+	for name, target := range map[string]string{
+		"ceiling": "ceil", "ucase": "upper", "lcase": "lower", "character_length": "char_length", "json_extract": "json_query",
+	} {
+		functions[name] = sameSQLFunction(target)
+	}
+	functions["json_unquote"] = func(params, args []string) (string, error) {
+		if params != nil || len(args) != 1 || !strings.HasPrefix(args[0], "json_query(") {
+			return "", fmt.Errorf("only `json_unquote(json_extract(...))` is supported")
+		}
+		return "get_json_string(" + strings.TrimPrefix(args[0], "json_query("), nil
+	}
+	return &sqlRewriter{
+		functions: functions,
+		keywords:  sqlKeywords,
+	}
+}
+
 func (c *MySQLSource) GetFlinkConnectorName() string {
 	return "mysql-cdc"
 }
 
-func (c *MySQLSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *MySQLSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return nil
 }
 
+// FlinkProjection WKT of the spatial values captured as `{"coordinates":[[1.0,2.0],...],"type":"LineString","srid":0}` by mysql-cdc,
+// the coordinates pairs are the points and the nested arrays are the parenthesized lists, geometry collections are NULL
+func (c *MySQLSource) FlinkProjection(column *model.Column) string {
+	if !funk.ContainsString(mysqlSpatialTypes, column.DATA_TYPE) {
+		return ""
+	}
+	quotedName := fmt.Sprintf("`%s`", column.COLUMN_NAME)
+	points := fmt.Sprintf("REGEXP_REPLACE(JSON_QUERY(%s, '$.coordinates'), '\\[([^\\[\\],]+),([^\\[\\],]+)\\]', '$1 $2')", quotedName)
+	lists := fmt.Sprintf("REPLACE(REPLACE(REPLACE(REGEXP_REPLACE(%s, '^\\[(.*)\\]$', '$1'), '[', '('), ']', ')'), ',', ', ')", points)
+	return fmt.Sprintf("CONCAT(UPPER(JSON_VALUE(%s, '$.type')), ' (', %s, ')')", quotedName, lists)
+}
+
 func (c *MySQLSource) CombineSchemaName() bool {
 	return false
 }
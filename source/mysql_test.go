@@ -0,0 +1,65 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestMySQLSource_FormatStarRocksColumnDef(t *testing.T) {
+	currentTimestamp := "CURRENT_TIMESTAMP"
+	uuid := "uuid()"
+	zeroDate := "0000-00-00 00:00:00"
+	literal := "abc"
+	tests := []struct {
+		name        string
+		column      *model.Column
+		want        string
+		wantWarning bool
+	}{
+		{"auto increment", &model.Column{COLUMN_NAME: "id", DATA_TYPE: "int", COLUMN_TYPE: "int(11) unsigned", IS_NULLABLE: "NO", EXTRA: "auto_increment"}, "  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT \"\"", false},
+		{"current timestamp", &model.Column{COLUMN_NAME: "created_at", DATA_TYPE: "timestamp", IS_NULLABLE: "NO", COLUMN_DEFAULT: &currentTimestamp, EXTRA: "DEFAULT_GENERATED"}, "  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT \"\"", false},
+		{"on update", &model.Column{COLUMN_NAME: "updated_at", DATA_TYPE: "datetime", IS_NULLABLE: "NO", COLUMN_DEFAULT: &currentTimestamp, EXTRA: "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"}, "  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT \"\"", true},
+		{"expression", &model.Column{COLUMN_NAME: "token", DATA_TYPE: "varchar", IS_NULLABLE: "YES", COLUMN_DEFAULT: &uuid, EXTRA: "DEFAULT_GENERATED"}, "  `token` STRING NULL  COMMENT \"\"", true},
+		{"zero date", &model.Column{COLUMN_NAME: "deleted_at", DATA_TYPE: "datetime", IS_NULLABLE: "NO", COLUMN_DEFAULT: &zeroDate}, "  `deleted_at` DATETIME NULL  COMMENT \"\"", true},
+		{"json", &model.Column{COLUMN_NAME: "doc", DATA_TYPE: "json", IS_NULLABLE: "YES"}, "  `doc` JSON NULL  COMMENT \"\"", false},
+		{"spatial", &model.Column{COLUMN_NAME: "location", DATA_TYPE: "point", IS_NULLABLE: "YES", COLUMN_COMMENT: "shop"}, "  `location` STRING NULL  COMMENT \"shop WKT of point\"", false},
+		{"generated", &model.Column{COLUMN_NAME: "total", DATA_TYPE: "decimal", NUMERIC_PRECISION: 10, NUMERIC_SCALE: 2, EXTRA: "STORED GENERATED", GenerationExpression: "(`price` * `qty`)"}, "  `total` DECIMAL(10, 2) NULL AS (`price` * `qty`) COMMENT \"\"", false},
+		{"generated json", &model.Column{COLUMN_NAME: "name", DATA_TYPE: "varchar", EXTRA: "VIRTUAL GENERATED", GenerationExpression: "json_unquote(json_extract(`doc`,_utf8mb4\\'$.a_b\\'))"}, "  `name` STRING NULL AS get_json_string(`doc`, '$.a_b') COMMENT \"\"", false},
+		{"generated not portable", &model.Column{COLUMN_NAME: "code", DATA_TYPE: "int", EXTRA: "VIRTUAL GENERATED", GenerationExpression: "crc32(`name`)"}, "", true},
+		{"literal", &model.Column{COLUMN_NAME: "name", DATA_TYPE: "varchar", IS_NULLABLE: "NO", COLUMN_DEFAULT: &literal}, "  `name` STRING NOT NULL DEFAULT \"abc\" COMMENT \"\"", false},
+	}
+	source := new(MySQLSource).Construct(&conf.Config{StarRocksVersion: "3.1.0"}).(*MySQLSource)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.FormatStarRocksColumnDef(&conf.TableRule{}, &model.Table{}, tt.column)
+			if err != nil || got != tt.want {
+				t.Errorf("FormatStarRocksColumnDef() = %v, %v, want %v", got, err, tt.want)
+			}
+			if (len(tt.column.DefaultWarnings) > 0) != tt.wantWarning {
+				t.Errorf("FormatStarRocksColumnDef() warning = %v, wantWarning %v", tt.column.DefaultWarnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestMySQLSource_FlinkProjection(t *testing.T) {
+	tests := []struct {
+		name   string
+		column *model.Column
+		want   string
+	}{
+		{"spatial", &model.Column{COLUMN_NAME: "location", DATA_TYPE: "point"}, "CONCAT(UPPER(JSON_VALUE(`location`, '$.type')), ' (', " +
+			`REPLACE(REPLACE(REPLACE(REGEXP_REPLACE(REGEXP_REPLACE(JSON_QUERY(` + "`location`" + `, '$.coordinates'), '\[([^\[\],]+),([^\[\],]+)\]', '$1 $2'), '^\[(.*)\]$', '$1'), '[', '('), ']', ')'), ',', ', ')` +
+			", ')')"},
+		{"other", &model.Column{COLUMN_NAME: "name", DATA_TYPE: "varchar"}, ""},
+	}
+	source := new(MySQLSource).Construct(&conf.Config{}).(*MySQLSource)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := source.FlinkProjection(tt.column); got != tt.want {
+				t.Errorf("FlinkProjection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
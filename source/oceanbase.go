@@ -0,0 +1,106 @@
+package source
+
+import (
+	"fmt"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strings"
+)
+
+// default port of the log proxy of oceanbase-cdc
+const oceanbaseLogProxyPort = "2983"
+
+// OceanBaseSource mysql mode of oceanbase, captured by oceanbase-cdc through the log proxy
+type OceanBaseSource struct {
+	MySQLSource
+}
+
+func (c *OceanBaseSource) Construct(config *conf.Config) IDBSource {
+	c.MySQLSource.Construct(config)
+	return c
+}
+
+func (c *OceanBaseSource) Build() (IDBSourceProvider, error) {
+	if _, err := c.MySQLSource.Build(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func (c *OceanBaseSource) GetFlinkConnectorName() string {
+	return "oceanbase-cdc"
+}
+
+func (c *OceanBaseSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	specialProps := make(map[string]string)
+	if _, ok := matchedTableRule.FlinkSourceProps["scan.startup.mode"]; !ok {
+		specialProps["scan.startup.mode"] = "initial"
+	}
+	if _, ok := matchedTableRule.FlinkSourceProps["tenant-name"]; !ok {
+		specialProps["tenant-name"] = c.tenantName()
+	}
+	if _, ok := matchedTableRule.FlinkSourceProps["rootserver-list"]; !ok {
+		if rootServers := c.rootServerList(); len(rootServers) > 0 {
+			specialProps["rootserver-list"] = rootServers
+		}
+	}
+	// the log proxy deployed along with the observer by default
+	if _, ok := matchedTableRule.FlinkSourceProps["logproxy.host"]; !ok {
+		specialProps["logproxy.host"] = c.config.DBHost
+	}
+	if _, ok := matchedTableRule.FlinkSourceProps["logproxy.port"]; !ok {
+		specialProps["logproxy.port"] = oceanbaseLogProxyPort
+	}
+	return specialProps
+}
+
+// FormatFlinkColumnDef years are integers of oceanbase-cdc
+func (c *OceanBaseSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	if column.DATA_TYPE != "year" {
+		return c.MySQLSource.FormatFlinkColumnDef(matchedTableRule, table, column)
+	}
+	nullableStr := "NULL"
+	if column.IS_NULLABLE != "YES" {
+		nullableStr = "NOT NULL"
+	}
+	return fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, c.columnType(matchedTableRule, column, "INT", true), nullableStr), nil
+}
+
+// tenantName the tenant of the user connecting through obproxy, or the tenant connected directly
+func (c *OceanBaseSource) tenantName() string {
+	if tenant := oceanbaseTenant(c.config.DBUser); len(tenant) > 0 {
+		return tenant
+	}
+	results := map[string]interface{}{}
+	if err := c.db.Raw("SELECT effective_tenant() AS tenant").Find(&results).Error; err != nil {
+		return ""
+	}
+	tenant, _ := results["tenant"].(string)
+	return tenant
+}
+
+// rootServerList `ip:rpc_port:sql_port;...` of the root services, empty if not visible to the tenant
+func (c *OceanBaseSource) rootServerList() string {
+	results := []map[string]interface{}{}
+	if err := c.db.Raw("SHOW PARAMETERS LIKE 'rootservice_list'").Find(&results).Error; err != nil || len(results) == 0 {
+		return ""
+	}
+	rootServers, _ := results[0]["value"].(string)
+	return rootServers
+}
+
+// oceanbaseTenant the tenant of `user@tenant#cluster` or `cluster:tenant:user` of obproxy
+func oceanbaseTenant(user string) string {
+	if parts := strings.Split(user, ":"); len(parts) == 3 {
+		return parts[1]
+	}
+	idx := strings.Index(user, "@")
+	if idx < 0 {
+		return ""
+	}
+	tenant := user[idx+1:]
+	if end := strings.Index(tenant, "#"); end >= 0 {
+		tenant = tenant[:end]
+	}
+	return tenant
+}
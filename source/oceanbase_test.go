@@ -0,0 +1,39 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestOceanbaseTenant(t *testing.T) {
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"root@sales#obcluster", "sales"},
+		{"root@sales", "sales"},
+		{"obcluster:sales:root", "sales"},
+		{"root", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			if got := oceanbaseTenant(tt.user); got != tt.want {
+				t.Errorf("oceanbaseTenant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOceanBaseSource_FormatFlinkColumnDef(t *testing.T) {
+	source := new(OceanBaseSource).Construct(&conf.Config{}).(*OceanBaseSource)
+	for column, want := range map[*model.Column]string{
+		{COLUMN_NAME: "year", DATA_TYPE: "year", IS_NULLABLE: "NO"}:         "  `year` INT NOT NULL",
+		{COLUMN_NAME: "name", DATA_TYPE: "varchar", IS_NULLABLE: "YES"}:     "  `name` STRING NULL",
+		{COLUMN_NAME: "created", DATA_TYPE: "datetime", IS_NULLABLE: "YES"}: "  `created` TIMESTAMP NULL",
+	} {
+		if got, err := source.FormatFlinkColumnDef(&conf.TableRule{}, &model.Table{}, column); err != nil || got != want {
+			t.Errorf("FormatFlinkColumnDef() = %v, %v, want %v", got, err, want)
+		}
+	}
+}
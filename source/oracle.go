@@ -5,21 +5,39 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
+	"strconv"
 	"strings"
 
 	_ "github.com/sijms/go-ora/v2"
 	"github.com/thoas/go-funk"
 )
 
+const oracleNumberSampleRows = 10000
+
+var (
+	oracleTypeArgsReg  = regexp.MustCompile(`\([^)]*\)`)
+	oracleTimestampReg = regexp.MustCompile(`(?i)^TIMESTAMP\s*\(([0-9])\)`)
+)
+
+var oracleConstraintTypes = map[string]string{
+	"P": common.CONSTRAINT_PRIMARY_KEY,
+	"U": common.CONSTRAINT_UNIQUE,
+	"R": common.CONSTRAINT_FOREIGN_KEY,
+}
+
 type OracleSource struct {
 	DBSource
-	odb       *sql.DB
-	dbVersion string
-	cdbMode   bool
-	cdbName   string
+	odb         *sql.DB
+	dbVersion   string
+	cdbMode     bool
+	cdbName     string
+	serviceName string
+	// container of the session, `CDB$ROOT` or a pdb name in cdb mode
+	containerName string
 }
 
 func (c *OracleSource) Construct(config *conf.Config) IDBSource {
@@ -33,14 +51,21 @@ func (c *OracleSource) DescribeTable(db, schema, table string) ([]*model.Column,
 
 func (c *OracleSource) Databases() ([]string, error) {
 	databases := []string{}
-	rows, err := c.odb.Query("select NAME from v$database")
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			db := ""
-			rows.Scan(&db)
-			databases = append(databases, db)
-		}
+	query := "select NAME from v$database"
+	if c.cdbMode && c.containerName == "CDB$ROOT" {
+		query = "select NAME from v$pdbs where NAME != 'PDB$SEED' order by NAME"
+	} else if c.cdbMode {
+		return []string{c.containerName}, nil
+	}
+	rows, err := c.odb.Query(query)
+	if err != nil {
+		return databases, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		db := ""
+		rows.Scan(&db)
+		databases = append(databases, db)
 	}
 	return databases, nil
 }
@@ -75,12 +100,18 @@ func (c *OracleSource) Tables(db, schema string) ([]string, error) {
 	return tables, nil
 }
 
-func (c *OracleSource) Sample(db, _, table string, limit int) ([]map[string]interface{}, error) {
-	err := c.SwitchDB(db)
+func (c *OracleSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		return c.sample(db, schema, table, limit)
+	})
+}
+
+func (c *OracleSource) sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
+	err := c.SwitchContainer(db)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := c.odb.Query(fmt.Sprintf("SELECT * FROM %s where rownum<=%d;", table, limit))
+	rows, err := c.odb.Query(fmt.Sprintf("SELECT * FROM \"%s\".\"%s\" WHERE rownum <= %d", schema, table, limit))
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +150,11 @@ func (c *OracleSource) ResultConventers() int {
 }
 
 func (c *OracleSource) InitDB() error {
-	err := c.SwitchDB(c.config.TableRules[0].DatabasePattern)
+	c.serviceName = c.config.DBServiceName
+	if len(c.serviceName) == 0 {
+		c.serviceName = c.patternServices()[0]
+	}
+	err := c.SwitchDB(c.serviceName)
 	if err != nil {
 		return err
 	}
@@ -162,9 +197,69 @@ func (c *OracleSource) InitDB() error {
 			}
 		}
 	}
+	if !c.cdbMode {
+		return nil
+	}
+	rows2, err := c.odb.Query("select sys_context( 'userenv', 'con_name' ) from dual")
+	if err != nil {
+		return err
+	}
+	defer rows2.Close()
+	for rows2.Next() {
+		rows2.Scan(&c.containerName)
+	}
 	return nil
 }
 
+// matchedDatabases the pdbs or the databases matched by the rules
+func (c *OracleSource) matchedDatabases() ([]string, error) {
+	if len(c.config.DBServiceName) == 0 {
+		return c.patternServices(), nil
+	}
+	databases, err := c.Databases()
+	if err != nil {
+		return databases, err
+	}
+	return funk.Filter(databases, func(database string) bool {
+		return funk.Find(c.config.TableRules, func(tableRule *conf.TableRule) bool {
+			return common.RegMatchString(tableRule.DatabasePattern, database)
+		}) != nil
+	}).([]string), nil
+}
+
+// patternServices the services of the configurations without `service_name`, the database patterns are the
+// service names as well as the catalogs
+func (c *OracleSource) patternServices() []string {
+	services := []string{}
+	for _, tableRule := range c.config.TableRules {
+		service := strings.TrimSuffix(strings.TrimPrefix(tableRule.DatabasePattern, "^"), "$")
+		if !funk.ContainsString(services, service) {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// SwitchContainer connects to the service and switches the session into the pdb in cdb mode,
+// or connects to the service of the database pattern without `service_name`
+func (c *OracleSource) SwitchContainer(database string) error {
+	if len(c.config.DBServiceName) == 0 {
+		return c.SwitchDB(database)
+	}
+	err := c.SwitchDB(c.serviceName)
+	if err != nil {
+		return err
+	}
+	if !c.cdbMode || c.containerName != "CDB$ROOT" {
+		return nil
+	}
+	// the container is a session state, keep the only connection
+	c.odb.SetMaxOpenConns(1)
+	c.odb.SetMaxIdleConns(1)
+	_, err = c.odb.Exec(fmt.Sprintf("ALTER SESSION SET CONTAINER = \"%s\"", database))
+	return err
+}
+
 func (c *OracleSource) SwitchDB(database string) error {
 	if c.odb != nil {
 		c.odb.Close()
@@ -188,21 +283,28 @@ func (c *OracleSource) Build() (IDBSourceProvider, error) {
 	matchedTables := []*model.Table{}
 	allColumns := []*model.Column{}
 	keyColumnUsageRows := []*model.KeyColumnUsage{}
-	for _, tableRule := range c.config.TableRules {
+	databases, err := c.matchedDatabases()
+	if err != nil {
+		return c, err
+	}
+	for _, database := range databases {
 		tables := []*model.Table{}
-		c.SwitchDB(tableRule.DatabasePattern)
-		rows, err := c.odb.Query("select a.owner as table_schema, a.table_name, a.avg_row_len * a.num_rows as data_length, b.comments from all_tables a left join all_tab_comments b on a.table_name = b.table_name where a.dropped='NO' and a.table_name != 'LOG_MINING_FLUSH' and a.owner not in ('APEX_030200', 'XDB', 'WMSYS', 'OE', 'SH', 'PM', 'IX', 'MDSYS', 'OUTLN', 'CTXSYS', 'OLAPSYS', 'FLOWS_FILES', 'OWBSYS', 'HR', 'EXFSYS', 'SCOTT', 'DBSNMP', 'ORDSYS', 'SYSMAN', 'APPQOSSYS', 'ORDDATA', 'SYS', 'SYSTEM')")
+		err := c.SwitchContainer(database)
+		if err != nil {
+			return c, err
+		}
+		rows, err := c.odb.Query("select a.owner as table_schema, a.table_name, a.avg_row_len * a.num_rows as data_length, nvl(a.num_rows, 0), b.comments from all_tables a left join all_tab_comments b on a.table_name = b.table_name where a.dropped='NO' and a.table_name != 'LOG_MINING_FLUSH' and a.owner not in ('APEX_030200', 'XDB', 'WMSYS', 'OE', 'SH', 'PM', 'IX', 'MDSYS', 'OUTLN', 'CTXSYS', 'OLAPSYS', 'FLOWS_FILES', 'OWBSYS', 'HR', 'EXFSYS', 'SCOTT', 'DBSNMP', 'ORDSYS', 'SYSMAN', 'APPQOSSYS', 'ORDDATA', 'SYS', 'SYSTEM')")
 		if err != nil {
 			return c, err
 		}
 		defer rows.Close()
 		for rows.Next() {
 			table := &model.Table{}
-			rows.Scan(&table.TABLE_SCHEMA, &table.TABLE_NAME, &table.DATA_LENGTH, &table.TABLE_COMMENT)
-			if !common.RegMatchString(tableRule.TablePattern, table.TABLE_NAME) || !common.RegMatchString(tableRule.SchemaPattern, table.TABLE_SCHEMA) {
+			rows.Scan(&table.TABLE_SCHEMA, &table.TABLE_NAME, &table.DATA_LENGTH, &table.TABLE_ROWS, &table.TABLE_COMMENT)
+			if c.matchTableRule(database, table.TABLE_SCHEMA, table.TABLE_NAME) == nil {
 				continue
 			}
-			table.TABLE_CATALOG = tableRule.DatabasePattern
+			table.TABLE_CATALOG = database
 			tables = append(tables, table)
 		}
 		if len(tables) == 0 {
@@ -228,25 +330,86 @@ func (c *OracleSource) Build() (IDBSourceProvider, error) {
 				}
 			}
 		}
-		rows, err = c.odb.Query(fmt.Sprintf("select owner as table_schema, column_name, table_name, nullable, data_type, data_length, data_precision, data_scale from all_tab_columns where table_name in (%s) order by column_id asc", tableNames))
+		charsets, err := c.charsets()
+		if err != nil {
+			return c, err
+		}
+		rows, err = c.odb.Query(fmt.Sprintf("select owner as table_schema, column_name, table_name, nullable, data_type, data_length, data_precision, data_scale, char_length, nvl(char_used, ' '), nvl(num_distinct, 0), data_default from all_tab_columns where table_name in (%s) order by column_id asc", tableNames))
 		if err != nil {
 			return c, err
 		}
+		unconstrainedColumns := []*model.Column{}
 		for rows.Next() {
 			rows.Columns()
 			column := &model.Column{}
 			var dataLength uint64
-			rows.Scan(&column.TABLE_SCHEMA, &column.COLUMN_NAME, &column.TABLE_NAME, &column.IS_NULLABLE, &column.DATA_TYPE, &dataLength, &column.NUMERIC_PRECISION, &column.NUMERIC_SCALE)
-			if column.NUMERIC_PRECISION == 0 {
+			var precision, scale sql.NullInt64
+			var charUsed string
+			var dataDefault sql.NullString
+			rows.Scan(&column.TABLE_SCHEMA, &column.COLUMN_NAME, &column.TABLE_NAME, &column.IS_NULLABLE, &column.DATA_TYPE, &dataLength, &precision, &scale, &column.CHARACTER_MAXIMUM_LENGTH, &charUsed, &column.Cardinality, &dataDefault)
+			if dataDefault.Valid {
+				columnDefault := strings.TrimSpace(dataDefault.String)
+				column.COLUMN_DEFAULT = &columnDefault
+				if strings.HasSuffix(strings.ToLower(columnDefault), ".nextval") {
+					// defaults on sequences
+					column.EXTRA = "auto_increment"
+				}
+			}
+			column.CHARACTER_SET_NAME = charsets["NLS_CHARACTERSET"]
+			if strings.HasPrefix(strings.ToUpper(column.DATA_TYPE), "N") {
+				column.CHARACTER_SET_NAME = charsets["NLS_NCHAR_CHARACTERSET"]
+			}
+			if charUsed == "B" && funk.ContainsString([]string{"AL32UTF8", "UTF8"}, strings.ToUpper(column.CHARACTER_SET_NAME)) {
+				// byte length semantics of utf-8 databases
+				column.CHARACTER_SET_NAME = "binary"
+			}
+			column.COLUMN_TYPE = column.DATA_TYPE
+			column.NUMERIC_PRECISION = uint64(precision.Int64)
+			column.NUMERIC_SCALE = uint64(scale.Int64)
+			if scale.Int64 < 0 {
+				// NUMBER(p, -s) rounds to the left of the decimal point
+				column.NUMERIC_PRECISION = uint64(precision.Int64 - scale.Int64)
+				column.NUMERIC_SCALE = 0
+			}
+			if strings.ToUpper(column.DATA_TYPE) == "NUMBER" && !precision.Valid {
+				if !scale.Valid {
+					// NUMBER without precision and scale
+					unconstrainedColumns = append(unconstrainedColumns, column)
+				}
+				column.NUMERIC_PRECISION = 38
+			} else if !precision.Valid {
 				column.NUMERIC_PRECISION = dataLength
 			}
-			column.TABLE_CATALOG = tableRule.DatabasePattern
+			column.TABLE_CATALOG = database
 			if column.IS_NULLABLE == "Y" {
 				column.IS_NULLABLE = "YES"
 			}
 			allColumns = append(allColumns, column)
 		}
-		rows, err = c.odb.Query(fmt.Sprintf("select a.owner as table_schema, b.constraint_type, a.table_name, a.column_name, a.position from all_cons_columns a left join all_constraints b on a.table_name = b.table_name and a.constraint_name = b.constraint_name and a.owner = b.owner where b.constraint_type = 'P' and a.table_name in (%s)", strings.Join(funk.Map(tables, func(table *model.Table) string {
+		// identity columns of oracle 12c
+		identityRows, err := c.odb.Query(fmt.Sprintf("select owner, table_name, column_name from all_tab_identity_cols where table_name in (%s)", tableNames))
+		if err == nil {
+			for identityRows.Next() {
+				identity := &model.Column{}
+				identityRows.Scan(&identity.TABLE_SCHEMA, &identity.TABLE_NAME, &identity.COLUMN_NAME)
+				for _, column := range allColumns {
+					if column.TABLE_CATALOG == database && column.TABLE_SCHEMA == identity.TABLE_SCHEMA && column.TABLE_NAME == identity.TABLE_NAME && column.COLUMN_NAME == identity.COLUMN_NAME {
+						column.EXTRA = "auto_increment"
+						column.COLUMN_DEFAULT = nil
+					}
+				}
+			}
+			identityRows.Close()
+		}
+		for _, table := range tables {
+			err = c.sampleNumberColumns(table, funk.Filter(unconstrainedColumns, func(column *model.Column) bool {
+				return column.TABLE_SCHEMA == table.TABLE_SCHEMA && column.TABLE_NAME == table.TABLE_NAME
+			}).([]*model.Column))
+			if err != nil {
+				return c, err
+			}
+		}
+		rows, err = c.odb.Query(fmt.Sprintf("select a.owner as table_schema, a.constraint_name, b.constraint_type, a.table_name, a.column_name, a.position, nvl(r.owner, ' '), nvl(r.table_name, ' '), nvl(r.column_name, ' ') from all_cons_columns a left join all_constraints b on a.table_name = b.table_name and a.constraint_name = b.constraint_name and a.owner = b.owner left join all_cons_columns r on r.owner = b.r_owner and r.constraint_name = b.r_constraint_name and r.position = a.position where b.constraint_type in ('P', 'U', 'R') and a.table_name in (%s)", strings.Join(funk.Map(tables, func(table *model.Table) string {
 			return fmt.Sprintf("'%s'", table.TABLE_NAME)
 		}).([]string), ",")))
 		if err != nil {
@@ -254,8 +417,12 @@ func (c *OracleSource) Build() (IDBSourceProvider, error) {
 		}
 		for rows.Next() {
 			columnKey := &model.KeyColumnUsage{}
-			rows.Scan(&columnKey.TABLE_SCHEMA, &columnKey.CONSTRAINT_NAME, &columnKey.TABLE_NAME, &columnKey.COLUMN_NAME, &columnKey.ORDINAL_POSITION)
-			columnKey.TABLE_CATALOG = tableRule.DatabasePattern
+			rows.Scan(&columnKey.TABLE_SCHEMA, &columnKey.CONSTRAINT_NAME, &columnKey.CONSTRAINT_TYPE, &columnKey.TABLE_NAME, &columnKey.COLUMN_NAME, &columnKey.ORDINAL_POSITION, &columnKey.REFERENCED_TABLE_SCHEMA, &columnKey.REFERENCED_TABLE_NAME, &columnKey.REFERENCED_COLUMN_NAME)
+			columnKey.REFERENCED_TABLE_SCHEMA = strings.TrimSpace(columnKey.REFERENCED_TABLE_SCHEMA)
+			columnKey.REFERENCED_TABLE_NAME = strings.TrimSpace(columnKey.REFERENCED_TABLE_NAME)
+			columnKey.REFERENCED_COLUMN_NAME = strings.TrimSpace(columnKey.REFERENCED_COLUMN_NAME)
+			columnKey.CONSTRAINT_TYPE = oracleConstraintTypes[columnKey.CONSTRAINT_TYPE]
+			columnKey.TABLE_CATALOG = database
 			keyColumnUsageRows = append(keyColumnUsageRows, columnKey)
 		}
 	}
@@ -265,11 +432,6 @@ func (c *OracleSource) Build() (IDBSourceProvider, error) {
 	if len(allColumns) == 0 {
 		return c, errors.New("Failed to get rows from information_schema.columns.")
 	}
-	for _, keyColumn := range keyColumnUsageRows {
-		if strings.ToLower(keyColumn.CONSTRAINT_NAME) == "p" {
-			keyColumn.CONSTRAINT_NAME = "PRIMARY"
-		}
-	}
 	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
 	if len(c.ruledTablesMap) == 0 {
 		return c, errors.New("No matching table columns found.")
@@ -277,39 +439,137 @@ func (c *OracleSource) Build() (IDBSourceProvider, error) {
 	return c, nil
 }
 
-func (c *OracleSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
-	colDataType := c.convertType(column.DATA_TYPE, column.NUMERIC_PRECISION, column.NUMERIC_SCALE, true)
+// charsets NLS_CHARACTERSET and NLS_NCHAR_CHARACTERSET of the current container
+func (c *OracleSource) charsets() (map[string]string, error) {
+	charsets := map[string]string{}
+	rows, err := c.odb.Query("select parameter, value from nls_database_parameters where parameter in ('NLS_CHARACTERSET', 'NLS_NCHAR_CHARACTERSET')")
+	if err != nil {
+		return charsets, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var parameter, value string
+		rows.Scan(&parameter, &value)
+		charsets[parameter] = value
+	}
+	return charsets, nil
+}
+
+// sampleNumberColumns chooses the types of unconstrained NUMBER columns with the sampled values
+func (c *OracleSource) sampleNumberColumns(table *model.Table, columns []*model.Column) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	selectList := []string{}
+	for _, column := range columns {
+		selectList = append(selectList,
+			fmt.Sprintf("max(case when \"%s\" = trunc(\"%s\") then 0 else length(to_char(abs(\"%s\" - trunc(\"%s\")))) - 1 end)", column.COLUMN_NAME, column.COLUMN_NAME, column.COLUMN_NAME, column.COLUMN_NAME),
+			fmt.Sprintf("max(length(to_char(trunc(abs(\"%s\")))))", column.COLUMN_NAME))
+	}
+	rows, err := c.odb.Query(fmt.Sprintf("select %s from (select * from \"%s\".\"%s\" where rownum <= %d)", strings.Join(selectList, ", "), table.TABLE_SCHEMA, table.TABLE_NAME, oracleNumberSampleRows))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	values := make([]sql.NullInt64, len(selectList))
+	dest := make([]interface{}, len(selectList))
+	for idx := range values {
+		dest[idx] = &values[idx]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+	}
+	for idx, column := range columns {
+		c.applyNumberSample(column, values[2*idx], values[2*idx+1])
+	}
+	return nil
+}
+
+// applyNumberSample the max scale and the max integer digits of the sampled values,
+// BIGINT of the integers, DECIMAL of the fitting numbers or DOUBLE otherwise
+func (c *OracleSource) applyNumberSample(column *model.Column, scale, digits sql.NullInt64) {
+	maxPrecision := int64(27)
+	if c.config.Supports(conf.CapabilityDecimalV3) {
+		maxPrecision = 38
+	}
+	if !scale.Valid || !digits.Valid {
+		// no sampled values
+		column.NUMERIC_PRECISION = uint64(maxPrecision)
+		column.NUMERIC_SCALE = 10
+	} else if scale.Int64 == 0 && digits.Int64 < 19 {
+		column.NUMERIC_PRECISION = 18
+		column.NUMERIC_SCALE = 0
+	} else if scale.Int64+digits.Int64 <= maxPrecision {
+		column.NUMERIC_PRECISION = uint64(maxPrecision)
+		column.NUMERIC_SCALE = uint64(scale.Int64)
+	} else {
+		column.DATA_TYPE = "BINARY_DOUBLE"
+	}
+}
+
+func (c *OracleSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.convertType(column, true)
 
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, true)
 	columnStr := fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, colDataType, nullableStr)
 	return columnStr, nil
 }
 
-func (c *OracleSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
-	colDataType := c.convertType(column.DATA_TYPE, column.NUMERIC_PRECISION, column.NUMERIC_SCALE, false)
+func (c *OracleSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.convertType(column, false)
+	switch strings.ToLower(column.DATA_TYPE) {
+	case "char", "nchar":
+		colDataType = c.stringType(matchedTableRule, column, true)
+		break
+	case "varchar", "varchar2", "nvarchar2":
+		colDataType = c.stringType(matchedTableRule, column, false)
+		break
+	}
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
 	defaultStr := ""
+	if column.IsAutoIncrement() {
+		if dataType, ok := c.autoIncrement(table, column, colDataType); ok {
+			colDataType = dataType
+			nullableStr = "NOT NULL"
+			defaultStr = "AUTO_INCREMENT"
+		}
+	} else if column.COLUMN_DEFAULT != nil {
+		defaultStr = c.defaultClause(column, colDataType, *column.COLUMN_DEFAULT)
+	}
 	colDataType = strings.Replace(colDataType, "unsigned", "", -1)
 	colDataType = strings.Replace(colDataType, "UNSIGNED", "", -1)
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
 	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
 	return columnStr, nil
 }
 
-func (c *OracleSource) convertType(dt string, NUMERIC_PRECISION, NUMERIC_SCALE uint64, flink bool) string {
-	switch strings.TrimSpace(strings.Split(strings.ToLower(dt), "(")[0]) {
-	case "char", "nchar", "nvarchar2", "varchar", "varchar2", "clob", "nclob", "xmltype":
+func (c *OracleSource) convertType(column *model.Column, flink bool) string {
+	NUMERIC_PRECISION := column.NUMERIC_PRECISION
+	NUMERIC_SCALE := column.NUMERIC_SCALE
+	// `TIMESTAMP(6) WITH TIME ZONE` -> `timestamp with time zone`
+	dt := strings.Join(strings.Fields(oracleTypeArgsReg.ReplaceAllString(strings.ToLower(column.DATA_TYPE), " ")), " ")
+	switch dt {
+	case "char", "nchar", "nvarchar2", "varchar", "varchar2", "clob", "nclob", "long", "xmltype", "rowid", "urowid", "bfile":
+		return "STRING"
+	case "raw", "long raw", "blob":
+		if flink {
+			return "BYTES"
+		}
 		return "STRING"
 	case "number":
 		if NUMERIC_PRECISION == 1 && NUMERIC_SCALE == 0 {
 			return "BOOLEAN"
 		}
-		if !c.config.UseDecimalV3 && NUMERIC_PRECISION > 27 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) && NUMERIC_PRECISION > 27 {
 			return "STRING"
 		}
 		if NUMERIC_SCALE <= 0 {
@@ -330,11 +590,16 @@ func (c *OracleSource) convertType(dt string, NUMERIC_PRECISION, NUMERIC_SCALE u
 			return fmt.Sprintf("DECIMAL(%d, %d)", NUMERIC_PRECISION, NUMERIC_SCALE)
 		}
 		return "STRING"
-	case "float", "binary_float":
-		if NUMERIC_PRECISION < 24 {
+	case "float":
+		// binary precision
+		if NUMERIC_PRECISION <= 24 {
 			return "FLOAT"
 		}
 		return "DOUBLE"
+	case "binary_float":
+		return "FLOAT"
+	case "binary_double":
+		return "DOUBLE"
 	case "date":
 		if flink {
 			return "TIMESTAMP"
@@ -342,15 +607,30 @@ func (c *OracleSource) convertType(dt string, NUMERIC_PRECISION, NUMERIC_SCALE u
 		return "DATETIME"
 	case "timestamp":
 		if flink {
-			return dt
+			return fmt.Sprintf("TIMESTAMP(%d)", c.datetimePrecision(column.DATA_TYPE))
 		}
 		return "DATETIME"
-	case "interval":
+	case "timestamp with time zone", "timestamp with local time zone":
+		return c.instantType(c.datetimePrecision(column.DATA_TYPE), flink)
+	case "interval day to second", "interval year to month":
+		// microseconds of the interval
 		return "BIGINT"
 	}
 	return "STRING"
 }
 
+// datetimePrecision fractional seconds precision of `TIMESTAMP(p)`, 6 by default
+func (c *OracleSource) datetimePrecision(dataType string) int {
+	precision := 6
+	if matches := oracleTimestampReg.FindStringSubmatch(dataType); len(matches) > 1 {
+		precision, _ = strconv.Atoi(matches[1])
+	}
+	if precision > 6 {
+		precision = 6
+	}
+	return precision
+}
+
 func (c *OracleSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns {
 	return c.ruledTablesMap
 }
@@ -359,7 +639,7 @@ func (c *OracleSource) GetFlinkConnectorName() string {
 	return "oracle-cdc"
 }
 
-func (c *OracleSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *OracleSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	props := map[string]string{
 		"debezium.database.tablename.case.insensitive": "false",
 		"debezium.log.mining.strategy":                 "online_catalog",
@@ -368,7 +648,7 @@ func (c *OracleSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) ma
 		props["debezium.decimal.handling.mode"] = "STRING"
 	}
 	if c.cdbMode {
-		props["debezium.database.pdb.name"] = matchedTableRule.DatabasePattern
+		props["debezium.database.pdb.name"] = table.TABLE_CATALOG
 		props["database-name"] = c.cdbName
 	}
 	return props
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"database/sql"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+
+	funk "github.com/thoas/go-funk"
+)
+
+func TestOracleSource_convertType(t *testing.T) {
+	source := new(OracleSource).Construct(&conf.Config{UseDecimalV3: true}).(*OracleSource)
+	tests := []struct {
+		column    *model.Column
+		want      string
+		wantFlink string
+	}{
+		{&model.Column{DATA_TYPE: "TIMESTAMP(3)"}, "DATETIME", "TIMESTAMP(3)"},
+		{&model.Column{DATA_TYPE: "TIMESTAMP(6) WITH TIME ZONE"}, "DATETIME", "TIMESTAMP_LTZ(6)"},
+		{&model.Column{DATA_TYPE: "TIMESTAMP(9) WITH LOCAL TIME ZONE"}, "DATETIME", "TIMESTAMP_LTZ(6)"},
+		{&model.Column{DATA_TYPE: "INTERVAL DAY(2) TO SECOND(6)"}, "BIGINT", "BIGINT"},
+		{&model.Column{DATA_TYPE: "INTERVAL YEAR(2) TO MONTH"}, "BIGINT", "BIGINT"},
+		{&model.Column{DATA_TYPE: "RAW"}, "STRING", "BYTES"},
+		{&model.Column{DATA_TYPE: "LONG RAW"}, "STRING", "BYTES"},
+		{&model.Column{DATA_TYPE: "BLOB"}, "STRING", "BYTES"},
+		{&model.Column{DATA_TYPE: "LONG"}, "STRING", "STRING"},
+		{&model.Column{DATA_TYPE: "ROWID"}, "STRING", "STRING"},
+		{&model.Column{DATA_TYPE: "BINARY_DOUBLE"}, "DOUBLE", "DOUBLE"},
+		{&model.Column{DATA_TYPE: "FLOAT", NUMERIC_PRECISION: 126}, "DOUBLE", "DOUBLE"},
+		{&model.Column{DATA_TYPE: "NUMBER", NUMERIC_PRECISION: 18}, "BIGINT", "BIGINT"},
+		{&model.Column{DATA_TYPE: "NUMBER", NUMERIC_PRECISION: 38, NUMERIC_SCALE: 4}, "DECIMAL(38, 4)", "DECIMAL(38, 4)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column.DATA_TYPE, func(t *testing.T) {
+			if got := source.convertType(tt.column, false); got != tt.want {
+				t.Errorf("convertType() = %v, want %v", got, tt.want)
+			}
+			if got := source.convertType(tt.column, true); got != tt.wantFlink {
+				t.Errorf("convertType() flink = %v, want %v", got, tt.wantFlink)
+			}
+		})
+	}
+}
+
+func TestOracleSource_applyNumberSample(t *testing.T) {
+	tests := []struct {
+		name          string
+		scale         sql.NullInt64
+		digits        sql.NullInt64
+		wantType      string
+		wantPrecision uint64
+		wantScale     uint64
+	}{
+		{"no samples", sql.NullInt64{}, sql.NullInt64{}, "NUMBER", 38, 10},
+		{"integers", sql.NullInt64{Int64: 0, Valid: true}, sql.NullInt64{Int64: 10, Valid: true}, "NUMBER", 18, 0},
+		{"large integers", sql.NullInt64{Int64: 0, Valid: true}, sql.NullInt64{Int64: 20, Valid: true}, "NUMBER", 38, 0},
+		{"decimals", sql.NullInt64{Int64: 4, Valid: true}, sql.NullInt64{Int64: 10, Valid: true}, "NUMBER", 38, 4},
+		{"beyond decimals", sql.NullInt64{Int64: 20, Valid: true}, sql.NullInt64{Int64: 20, Valid: true}, "BINARY_DOUBLE", 0, 0},
+	}
+	source := new(OracleSource).Construct(&conf.Config{UseDecimalV3: true}).(*OracleSource)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column := &model.Column{DATA_TYPE: "NUMBER"}
+			source.applyNumberSample(column, tt.scale, tt.digits)
+			if column.DATA_TYPE != tt.wantType || column.NUMERIC_PRECISION != tt.wantPrecision || column.NUMERIC_SCALE != tt.wantScale {
+				t.Errorf("applyNumberSample() = %v(%d, %d), want %v(%d, %d)", column.DATA_TYPE, column.NUMERIC_PRECISION, column.NUMERIC_SCALE, tt.wantType, tt.wantPrecision, tt.wantScale)
+			}
+		})
+	}
+}
+
+func TestOracleSource_matchedDatabases(t *testing.T) {
+	// the database patterns are the services without `service_name`
+	source := new(OracleSource).Construct(&conf.Config{TableRules: []*conf.TableRule{
+		{DatabasePattern: "^orclpdb1$", SchemaPattern: "^FLINK$", TablePattern: "^ORDERS$"},
+		{DatabasePattern: "orclpdb2", SchemaPattern: ".*", TablePattern: ".*"},
+		{DatabasePattern: "^orclpdb1$", SchemaPattern: "^FLINK$", TablePattern: "^USERS$"},
+	}}).(*OracleSource)
+	databases, err := source.matchedDatabases()
+	if err != nil || !funk.Equal(databases, []string{"orclpdb1", "orclpdb2"}) {
+		t.Errorf("matchedDatabases() = %v, %v", databases, err)
+	}
+	if rule := source.matchTableRule(databases[0], "FLINK", "ORDERS"); rule == nil || rule.TablePattern != "^ORDERS$" {
+		t.Errorf("matchTableRule() = %v", rule)
+	}
+}
@@ -16,6 +16,24 @@ import (
 
 type PostgreSQLSource struct {
 	DBSource
+	// domains and enums keyed by `database.typname`
+	userDefinedTypes map[string]*pgUserDefinedType
+}
+
+// pgUserDefinedType pg_type rows of domains and enums
+type pgUserDefinedType struct {
+	TYPNAME     string `gorm:"column:typname"`
+	TYPTYPE     string `gorm:"column:typtype"`
+	BASE_TYPE   string `gorm:"column:base_type"`
+	ENUM_LENGTH uint64 `gorm:"column:enum_length"`
+}
+
+// pgInherit pg_inherits rows of partitions and inheriting tables
+type pgInherit struct {
+	CHILD_SCHEMA  string `gorm:"column:child_schema"`
+	CHILD_NAME    string `gorm:"column:child_name"`
+	PARENT_SCHEMA string `gorm:"column:parent_schema"`
+	PARENT_NAME   string `gorm:"column:parent_name"`
 }
 
 func (c *PostgreSQLSource) Construct(config *conf.Config) IDBSource {
@@ -52,10 +70,14 @@ func (c *PostgreSQLSource) Tables(db, schema string) ([]string, error) {
 }
 
 func (c *PostgreSQLSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
-	results := []map[string]interface{}{}
-	c.SwitchDB(db)
-	c.db.Raw(fmt.Sprintf("SELECT * FROM %s.%s limit %d;", schema, table, limit)).Find(&results)
-	return results, nil
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		results := []map[string]interface{}{}
+		if err := c.SwitchDB(db); err != nil {
+			return results, err
+		}
+		err := c.db.Raw(fmt.Sprintf("SELECT * FROM \"%s\".\"%s\" limit %d;", schema, table, limit)).Find(&results).Error
+		return results, err
+	})
 }
 
 func (c *PostgreSQLSource) Destroy() {
@@ -118,13 +140,23 @@ func (c *PostgreSQLSource) Build() (IDBSourceProvider, error) {
 			return common.RegMatchString(tableRule.DatabasePattern, database)
 		})
 	}).([]string)
+	c.userDefinedTypes = map[string]*pgUserDefinedType{}
 	for _, database := range databases {
 		tables := []*model.Table{}
 		c.SwitchDB(database)
-		err := c.db.Select("table_name", "table_schema", "table_catalog", "pg_table_size(table_schema || '.' || table_name) as data_length", "pg_indexes_size(table_schema || '.' || table_name) as index_length").Where("table_type=? and table_schema not in ('information_schema', 'pg_catalog')", "BASE TABLE").Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&tables).Error
+		err := c.db.Select("table_name", "table_schema", "table_catalog", "pg_table_size(table_schema || '.' || table_name) as data_length", "pg_indexes_size(table_schema || '.' || table_name) as index_length",
+			"coalesce((SELECT greatest(pc.reltuples, 0)::bigint FROM pg_class pc JOIN pg_namespace pn ON pn.oid = pc.relnamespace WHERE pn.nspname = table_schema AND pc.relname = table_name), 0) as table_rows").Where("table_type=? and table_schema not in ('information_schema', 'pg_catalog')", "BASE TABLE").Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&tables).Error
+		if err != nil {
+			return c, err
+		}
+		inherits := []*pgInherit{}
+		err = c.db.Raw("SELECT cn.nspname AS child_schema, cc.relname AS child_name, pn.nspname AS parent_schema, pc.relname AS parent_name " +
+			"FROM pg_inherits i JOIN pg_class cc ON cc.oid = i.inhrelid JOIN pg_namespace cn ON cn.oid = cc.relnamespace " +
+			"JOIN pg_class pc ON pc.oid = i.inhparent JOIN pg_namespace pn ON pn.oid = pc.relnamespace").Scan(&inherits).Error
 		if err != nil {
 			return c, err
 		}
+		tables = c.collapseInheritedTables(tables, inherits)
 		tables = funk.Filter(tables, func(table *model.Table) bool {
 			table.CREATE_TIME = time.Now().Add(-24 * 365 * time.Hour)
 			return funk.Some(c.config.TableRules, func(tableRule *conf.TableRule) bool {
@@ -141,13 +173,60 @@ func (c *PostgreSQLSource) Build() (IDBSourceProvider, error) {
 		schemaNames := funk.Map(tables, func(table *model.Table) string {
 			return table.TABLE_SCHEMA
 		}).([]string)
-		err = c.db.Where("table_catalog = ? and table_name in ? and table_schema in ?", database, tableNames, schemaNames).Order("ORDINAL_POSITION asc").Find(&columns).Error
+		err = c.db.Select("*, CASE WHEN is_identity = 'YES' OR column_default LIKE 'nextval(%' THEN 'auto_increment' ELSE '' END AS extra").
+			Where("table_catalog = ? and table_name in ? and table_schema in ?", database, tableNames, schemaNames).Order("ORDINAL_POSITION asc").Find(&columns).Error
+		if err != nil {
+			return c, err
+		}
+		partitionKeys := []*model.Column{}
+		err = c.db.Raw("SELECT n.nspname AS table_schema, pc.relname AS table_name, a.attname AS column_name " +
+			"FROM pg_partitioned_table p JOIN pg_class pc ON pc.oid = p.partrelid JOIN pg_namespace n ON n.oid = pc.relnamespace " +
+			"JOIN pg_attribute a ON a.attrelid = p.partrelid AND a.attnum = ANY(p.partattrs)").Scan(&partitionKeys).Error
+		if err != nil {
+			return c, err
+		}
+		for _, column := range columns {
+			column.IsInPartitionKey = funk.Some(partitionKeys, func(partitionKey *model.Column) bool {
+				return partitionKey.TABLE_SCHEMA == column.TABLE_SCHEMA && partitionKey.TABLE_NAME == column.TABLE_NAME && partitionKey.COLUMN_NAME == column.COLUMN_NAME
+			})
+		}
+		// negative n_distinct is the fraction of the rows
+		statistics := []*columnStatistic{}
+		err = c.db.Raw("SELECT s.schemaname AS table_schema, s.tablename AS table_name, s.attname AS column_name, " +
+			"(CASE WHEN s.n_distinct >= 0 THEN s.n_distinct ELSE -s.n_distinct * greatest(pc.reltuples, 0) END)::bigint AS cardinality " +
+			"FROM pg_stats s JOIN pg_namespace pn ON pn.nspname = s.schemaname JOIN pg_class pc ON pc.relnamespace = pn.oid AND pc.relname = s.tablename").Scan(&statistics).Error
 		if err != nil {
 			return c, err
 		}
+		c.applyStatistics(columns, statistics)
+		// indexes the tables are clustered on by `CLUSTER`
+		sortKeyColumns := []*columnStatistic{}
+		err = c.db.Raw("SELECT n.nspname AS table_schema, t.relname AS table_name, a.attname AS column_name, k.ord AS position " +
+			"FROM pg_index i JOIN pg_class t ON t.oid = i.indrelid JOIN pg_namespace n ON n.oid = t.relnamespace " +
+			"CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord) JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum " +
+			"WHERE i.indisclustered AND NOT i.indisprimary").Scan(&sortKeyColumns).Error
+		if err != nil {
+			return c, err
+		}
+		c.applyStatistics(columns, sortKeyColumns)
 		allColumns = append(allColumns, columns...)
+		userDefinedTypes := []*pgUserDefinedType{}
+		err = c.db.Raw("SELECT t.typname, t.typtype, coalesce(b.typname, '') AS base_type, " +
+			"coalesce((SELECT max(octet_length(e.enumlabel)) FROM pg_enum e WHERE e.enumtypid = t.oid), 0) AS enum_length " +
+			"FROM pg_type t LEFT JOIN pg_type b ON b.oid = t.typbasetype WHERE t.typtype IN ('d', 'e')").Scan(&userDefinedTypes).Error
+		if err != nil {
+			return c, err
+		}
+		for _, userDefinedType := range userDefinedTypes {
+			c.userDefinedTypes[database+"."+userDefinedType.TYPNAME] = userDefinedType
+		}
 		keyColumns := []*model.KeyColumnUsage{}
-		err = c.db.Where("table_catalog = ? and table_name in ? and table_schema in ?", database, tableNames, schemaNames).Find(&keyColumns).Error
+		err = c.db.Table("information_schema.key_column_usage k").
+			Select("k.*, t.constraint_type, coalesce(r.table_schema, '') AS referenced_table_schema, coalesce(r.table_name, '') AS referenced_table_name, coalesce(r.column_name, '') AS referenced_column_name").
+			Joins("JOIN information_schema.table_constraints t ON t.constraint_catalog = k.constraint_catalog AND t.constraint_schema = k.constraint_schema AND t.constraint_name = k.constraint_name AND t.table_schema = k.table_schema AND t.table_name = k.table_name").
+			Joins("LEFT JOIN information_schema.referential_constraints rc ON rc.constraint_catalog = k.constraint_catalog AND rc.constraint_schema = k.constraint_schema AND rc.constraint_name = k.constraint_name").
+			Joins("LEFT JOIN information_schema.key_column_usage r ON r.constraint_catalog = rc.unique_constraint_catalog AND r.constraint_schema = rc.unique_constraint_schema AND r.constraint_name = rc.unique_constraint_name AND r.ordinal_position = k.position_in_unique_constraint").
+			Where("k.table_catalog = ? and k.table_name in ? and k.table_schema in ?", database, tableNames, schemaNames).Find(&keyColumns).Error
 		if err != nil {
 			return c, err
 		}
@@ -159,11 +238,6 @@ func (c *PostgreSQLSource) Build() (IDBSourceProvider, error) {
 	if len(allColumns) == 0 {
 		return c, errors.New("Failed to get rows from information_schema.columns.")
 	}
-	for _, keyColumn := range keyColumnUsageRows {
-		if strings.HasSuffix(keyColumn.CONSTRAINT_NAME, "_pkey") {
-			keyColumn.CONSTRAINT_NAME = "PRIMARY"
-		}
-	}
 	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
 	if len(c.ruledTablesMap) == 0 {
 		return c, errors.New("No matching table columns found.")
@@ -171,37 +245,145 @@ func (c *PostgreSQLSource) Build() (IDBSourceProvider, error) {
 	return c, nil
 }
 
+// collapseInheritedTables removes partitions and inheriting tables,
+// their sizes are added to the root tables they belong to
+func (c *PostgreSQLSource) collapseInheritedTables(tables []*model.Table, inherits []*pgInherit) []*model.Table {
+	parents := map[string]string{}
+	for _, inherit := range inherits {
+		parents[inherit.CHILD_SCHEMA+"."+inherit.CHILD_NAME] = inherit.PARENT_SCHEMA + "." + inherit.PARENT_NAME
+	}
+	rootOf := func(name string) string {
+		for depth := 0; depth < len(inherits); depth++ {
+			parent, ok := parents[name]
+			if !ok {
+				break
+			}
+			name = parent
+		}
+		return name
+	}
+	rootTables := map[string]*model.Table{}
+	for _, table := range tables {
+		if _, ok := parents[table.TABLE_SCHEMA+"."+table.TABLE_NAME]; !ok {
+			rootTables[table.TABLE_SCHEMA+"."+table.TABLE_NAME] = table
+		}
+	}
+	collapsedTables := []*model.Table{}
+	for _, table := range tables {
+		name := table.TABLE_SCHEMA + "." + table.TABLE_NAME
+		if _, ok := parents[name]; !ok {
+			collapsedTables = append(collapsedTables, table)
+			continue
+		}
+		rootTable, ok := rootTables[rootOf(name)]
+		if !ok {
+			continue
+		}
+		rootTable.DATA_LENGTH += table.DATA_LENGTH
+		rootTable.INDEX_LENGTH += table.INDEX_LENGTH
+		rootTable.TABLE_ROWS += table.TABLE_ROWS
+		rootTable.ChildTableNames = append(rootTable.ChildTableNames, table.TABLE_NAME)
+	}
+	return collapsedTables
+}
+
 func (c *PostgreSQLSource) GetRuledTablesMap() map[*conf.TableRule][]*common.TableColumns {
 	return c.ruledTablesMap
 }
 
-func (c *PostgreSQLSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
-	colDataType := c.convertType(column.DATA_TYPE, column.UDT_NAME, column.NUMERIC_PRECISION, column.NUMERIC_SCALE, true)
+func (c *PostgreSQLSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.convertType(column, column.DATA_TYPE, column.UDT_NAME, true)
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, true)
 	columnStr := fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, colDataType, nullableStr)
 	return columnStr, nil
 }
 
-func (c *PostgreSQLSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
-	colDataType := c.convertType(column.DATA_TYPE, column.UDT_NAME, column.NUMERIC_PRECISION, column.NUMERIC_SCALE, false)
+func (c *PostgreSQLSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.convertType(column, column.DATA_TYPE, column.UDT_NAME, false)
+	switch strings.ToLower(column.DATA_TYPE) {
+	case "character", "char", "bpchar":
+		colDataType = c.stringType(matchedTableRule, column, true)
+		break
+	case "character varying", "varchar":
+		colDataType = c.stringType(matchedTableRule, column, false)
+		break
+	}
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
 	defaultStr := ""
+	if column.IsAutoIncrement() {
+		if dataType, ok := c.autoIncrement(table, column, colDataType); ok {
+			colDataType = dataType
+			nullableStr = "NOT NULL"
+			defaultStr = "AUTO_INCREMENT"
+		}
+	} else if column.COLUMN_DEFAULT != nil {
+		defaultStr = c.defaultClause(column, colDataType, *column.COLUMN_DEFAULT)
+	}
 	colDataType = strings.Replace(colDataType, "unsigned", "", -1)
 	colDataType = strings.Replace(colDataType, "UNSIGNED", "", -1)
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
 	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
 	return columnStr, nil
 }
 
-func (c *PostgreSQLSource) convertType(dt, udt string, NUMERIC_PRECISION, NUMERIC_SCALE uint64, flink bool) string {
+func (c *PostgreSQLSource) convertType(column *model.Column, dt, udt string, flink bool) string {
+	NUMERIC_PRECISION := column.NUMERIC_PRECISION
+	NUMERIC_SCALE := column.NUMERIC_SCALE
+	switch strings.ToLower(dt) {
+	case "array":
+		// udt_name of arrays is the element type prefixed with `_`
+		element := *column
+		element.NUMERIC_PRECISION = 0
+		element.NUMERIC_SCALE = 0
+		element.DATETIME_PRECISION = 6
+		elementType := strings.TrimPrefix(udt, "_")
+		return fmt.Sprintf("ARRAY<%s>", c.convertType(&element, elementType, elementType, flink))
+	case "user-defined":
+		userDefinedType, ok := c.userDefinedTypes[column.TABLE_CATALOG+"."+udt]
+		if !ok {
+			return "STRING"
+		}
+		if userDefinedType.TYPTYPE == "e" {
+			if flink {
+				return "STRING"
+			}
+			if userDefinedType.ENUM_LENGTH == 0 {
+				userDefinedType.ENUM_LENGTH = 1
+			}
+			return fmt.Sprintf("VARCHAR(%d)", userDefinedType.ENUM_LENGTH)
+		}
+		// domains resolve to their base types
+		if strings.HasPrefix(userDefinedType.BASE_TYPE, "_") {
+			return c.convertType(column, "ARRAY", userDefinedType.BASE_TYPE, flink)
+		}
+		return c.convertType(column, userDefinedType.BASE_TYPE, userDefinedType.BASE_TYPE, flink)
+	}
+	if _, ok := c.userDefinedTypes[column.TABLE_CATALOG+"."+dt]; ok {
+		return c.convertType(column, "USER-DEFINED", dt, flink)
+	}
 	switch strings.ReplaceAll(strings.ToLower(dt), "_", "") {
-	case "char", "character", "character varying", "varchar", "text", "json", "bytea", "uuid":
+	case "char", "character", "character varying", "varchar", "bpchar", "text", "bytea":
 		return "STRING"
+	case "json", "jsonb":
+		if flink {
+			return "STRING"
+		}
+		return "JSON"
+	case "uuid":
+		if flink {
+			return "STRING"
+		}
+		return "VARCHAR(36)"
+	case "interval":
+		// microseconds of the interval
+		return "BIGINT"
 	case "smallint", "smallserial", "int2":
 		return "SMALLINT"
 	case "integer", "serial", "int4":
@@ -209,7 +391,7 @@ func (c *PostgreSQLSource) convertType(dt, udt string, NUMERIC_PRECISION, NUMERI
 	case "bigint", "bigserial", "int8":
 		return "BIGINT"
 	case "decimal", "numeric":
-		if !c.config.UseDecimalV3 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
 			if NUMERIC_PRECISION <= 0 {
 				NUMERIC_PRECISION = 27
 			}
@@ -238,12 +420,18 @@ func (c *PostgreSQLSource) convertType(dt, udt string, NUMERIC_PRECISION, NUMERI
 		return fmt.Sprintf("DECIMAL(%d, %d)", 27, 2)
 	case "date":
 		return "DATE"
-	case "timestamp", "timestamp without time zone", "timestamp time zone":
+	case "timestamp", "timestamp without time zone":
 		if flink {
 			return "TIMESTAMP"
 		}
 		return "DATETIME"
-	case "boolean":
+	case "timestamptz", "timestamp with time zone":
+		DATETIME_PRECISION := column.DATETIME_PRECISION
+		if DATETIME_PRECISION > 6 {
+			DATETIME_PRECISION = 6
+		}
+		return c.instantType(int(DATETIME_PRECISION), flink)
+	case "boolean", "bool":
 		return "BOOLEAN"
 	case "bit", "bit varying":
 		if NUMERIC_PRECISION < 8 {
@@ -261,9 +449,6 @@ func (c *PostgreSQLSource) convertType(dt, udt string, NUMERIC_PRECISION, NUMERI
 		return "FLOAT"
 	case "double", "double precision", "float8":
 		return "DOUBLE"
-	case "array":
-		return "STRING"
-		// return fmt.Sprintf("ARRAY<%s>", c.convertType(udt, udt, NUMERIC_PRECISION, NUMERIC_SCALE, flink))
 	}
 	return "STRING"
 }
@@ -272,7 +457,7 @@ func (c *PostgreSQLSource) GetFlinkConnectorName() string {
 	return "postgres-cdc"
 }
 
-func (c *PostgreSQLSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *PostgreSQLSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return map[string]string{
 		"decoding.plugin.name": "pgoutput",
 	}
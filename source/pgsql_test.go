@@ -0,0 +1,62 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestPostgreSQLSource_convertType(t *testing.T) {
+	source := new(PostgreSQLSource).Construct(&conf.Config{UseDecimalV3: true}).(*PostgreSQLSource)
+	source.userDefinedTypes = map[string]*pgUserDefinedType{
+		"db.mood":       {TYPNAME: "mood", TYPTYPE: "e", ENUM_LENGTH: 9},
+		"db.mood_alias": {TYPNAME: "mood_alias", TYPTYPE: "d", BASE_TYPE: "mood"},
+	}
+	tests := []struct {
+		name      string
+		column    *model.Column
+		want      string
+		wantFlink string
+	}{
+		{"int array", &model.Column{DATA_TYPE: "ARRAY", UDT_NAME: "_int4"}, "ARRAY<INT>", "ARRAY<INT>"},
+		{"timestamptz array", &model.Column{DATA_TYPE: "ARRAY", UDT_NAME: "_timestamptz"}, "ARRAY<DATETIME>", "ARRAY<TIMESTAMP_LTZ(6)>"},
+		{"enum array", &model.Column{DATA_TYPE: "ARRAY", UDT_NAME: "_mood"}, "ARRAY<VARCHAR(9)>", "ARRAY<STRING>"},
+		{"jsonb", &model.Column{DATA_TYPE: "jsonb", UDT_NAME: "jsonb"}, "JSON", "STRING"},
+		{"uuid", &model.Column{DATA_TYPE: "uuid", UDT_NAME: "uuid"}, "VARCHAR(36)", "STRING"},
+		{"interval", &model.Column{DATA_TYPE: "interval", UDT_NAME: "interval"}, "BIGINT", "BIGINT"},
+		{"timestamptz", &model.Column{DATA_TYPE: "timestamp with time zone", UDT_NAME: "timestamptz", DATETIME_PRECISION: 3}, "DATETIME", "TIMESTAMP_LTZ(3)"},
+		{"enum", &model.Column{DATA_TYPE: "USER-DEFINED", UDT_NAME: "mood"}, "VARCHAR(9)", "STRING"},
+		{"domain of enum", &model.Column{DATA_TYPE: "USER-DEFINED", UDT_NAME: "mood_alias"}, "VARCHAR(9)", "STRING"},
+		{"unknown", &model.Column{DATA_TYPE: "USER-DEFINED", UDT_NAME: "hstore"}, "STRING", "STRING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.column.TABLE_CATALOG = "db"
+			if got := source.convertType(tt.column, tt.column.DATA_TYPE, tt.column.UDT_NAME, false); got != tt.want {
+				t.Errorf("convertType() = %v, want %v", got, tt.want)
+			}
+			if got := source.convertType(tt.column, tt.column.DATA_TYPE, tt.column.UDT_NAME, true); got != tt.wantFlink {
+				t.Errorf("convertType() flink = %v, want %v", got, tt.wantFlink)
+			}
+		})
+	}
+}
+
+func TestPostgreSQLSource_collapseInheritedTables(t *testing.T) {
+	newTable := func(name string, size uint64) *model.Table {
+		return &model.Table{ModelBase: model.ModelBase{TABLE_SCHEMA: "public", TABLE_NAME: name}, DATA_LENGTH: size}
+	}
+	tables := []*model.Table{newTable("events", 0), newTable("events_2023", 0), newTable("events_2023_01", 10), newTable("events_2023_02", 20), newTable("users", 5)}
+	inherits := []*pgInherit{
+		{CHILD_SCHEMA: "public", CHILD_NAME: "events_2023", PARENT_SCHEMA: "public", PARENT_NAME: "events"},
+		{CHILD_SCHEMA: "public", CHILD_NAME: "events_2023_01", PARENT_SCHEMA: "public", PARENT_NAME: "events_2023"},
+		{CHILD_SCHEMA: "public", CHILD_NAME: "events_2023_02", PARENT_SCHEMA: "public", PARENT_NAME: "events_2023"},
+	}
+	got := new(PostgreSQLSource).collapseInheritedTables(tables, inherits)
+	if len(got) != 2 || got[0].TABLE_NAME != "events" || got[1].TABLE_NAME != "users" {
+		t.Fatalf("collapseInheritedTables() = %v, want [events users]", got)
+	}
+	if got[0].DATA_LENGTH != 30 || len(got[0].ChildTableNames) != 3 {
+		t.Errorf("collapseInheritedTables() events size = %d, children = %v", got[0].DATA_LENGTH, got[0].ChildTableNames)
+	}
+}
@@ -0,0 +1,54 @@
+package source
+
+import (
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+
+	"github.com/thoas/go-funk"
+)
+
+// hidden primary key added by polardb-x to the tables without primary keys
+const polardbxImplicitKey = "_drds_implicit_id_"
+
+// PolarDBXSource polardb-x 2.0, captured by mysql-cdc through the global binlog,
+// the columns are in the types and the forms of mysql except the implicit keys
+type PolarDBXSource struct {
+	MySQLSource
+}
+
+func (c *PolarDBXSource) Construct(config *conf.Config) IDBSource {
+	c.MySQLSource.Construct(config)
+	return c
+}
+
+func (c *PolarDBXSource) Build() (IDBSourceProvider, error) {
+	if _, err := c.MySQLSource.Build(); err != nil {
+		return c, err
+	}
+	for _, tableColumnsList := range c.ruledTablesMap {
+		for _, tableColumns := range tableColumnsList {
+			c.dropImplicitKey(tableColumns)
+		}
+	}
+	return c, nil
+}
+
+// dropImplicitKey the implicit keys are not visible to the users nor in the global binlog
+func (c *PolarDBXSource) dropImplicitKey(tableColumns *common.TableColumns) {
+	tableColumns.Columns = funk.Filter(tableColumns.Columns, func(col *model.Column) bool {
+		return col.COLUMN_NAME != polardbxImplicitKey
+	}).([]*model.Column)
+	tableColumns.PrimaryKCU = funk.Filter(tableColumns.PrimaryKCU, func(kcu *model.KeyColumnUsage) bool {
+		return kcu.COLUMN_NAME != polardbxImplicitKey
+	}).([]*model.KeyColumnUsage)
+}
+
+func (c *PolarDBXSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
+	specialProps := make(map[string]string)
+	// global read locks are not supported, the snapshots without the incremental snapshot are taken without locks
+	if _, ok := matchedTableRule.FlinkSourceProps["debezium.snapshot.locking.mode"]; !ok {
+		specialProps["debezium.snapshot.locking.mode"] = "none"
+	}
+	return specialProps
+}
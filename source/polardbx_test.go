@@ -0,0 +1,47 @@
+package source
+
+import (
+	"reflect"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestPolarDBXSource_dropImplicitKey(t *testing.T) {
+	tableColumns := &common.TableColumns{
+		Table: &model.Table{},
+		Columns: []*model.Column{
+			{COLUMN_NAME: polardbxImplicitKey, DATA_TYPE: "bigint"},
+			{COLUMN_NAME: "name", DATA_TYPE: "varchar"},
+		},
+		PrimaryKCU: []*model.KeyColumnUsage{{COLUMN_NAME: polardbxImplicitKey}},
+	}
+	new(PolarDBXSource).Construct(&conf.Config{}).(*PolarDBXSource).dropImplicitKey(tableColumns)
+	if len(tableColumns.Columns) != 1 || tableColumns.Columns[0].COLUMN_NAME != "name" {
+		t.Errorf("dropImplicitKey() columns = %v", tableColumns.Columns)
+	}
+	if len(tableColumns.PrimaryKCU) != 0 {
+		t.Errorf("dropImplicitKey() primary keys = %v", tableColumns.PrimaryKCU)
+	}
+}
+
+func TestPolarDBXSource_GetFlinkSpecialProps(t *testing.T) {
+	tests := []struct {
+		name        string
+		sourceProps map[string]string
+		want        map[string]string
+	}{
+		{"default", map[string]string{}, map[string]string{"debezium.snapshot.locking.mode": "none"}},
+		{"configured", map[string]string{"debezium.snapshot.locking.mode": "minimal"}, map[string]string{}},
+	}
+	source := new(PolarDBXSource).Construct(&conf.Config{}).(*PolarDBXSource)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := source.GetFlinkSpecialProps(&conf.TableRule{FlinkSourceProps: tt.sourceProps}, &model.Table{})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetFlinkSpecialProps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
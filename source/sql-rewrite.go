@@ -0,0 +1,246 @@
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+// sqlFunctionRewriter rewrites a function call to the StarRocks dialect,
+// params are only set for parametric calls like `quantile(0.9)(x)`
+type sqlFunctionRewriter func(params, args []string) (string, error)
+
+type sqlUnsupportedSyntax struct {
+	pattern *regexp.Regexp
+	reason  string
+}
+
+// sqlRewriter translates expressions and queries of a source dialect to StarRocks
+type sqlRewriter struct {
+	functions map[string]sqlFunctionRewriter
+	// words followed by `(` which are not function calls
+	keywords []string
+	syntaxes []*sqlUnsupportedSyntax
+}
+
+var sqlKeywords = []string{
+	"all", "and", "as", "between", "by", "case", "distinct", "else", "exists", "from", "in", "interval",
+	"is", "join", "like", "not", "on", "or", "over", "select", "then", "union", "using", "values", "when", "where", "with",
+}
+
+func (r *sqlRewriter) rewrite(sql string) (string, []string) {
+	reasons := []string{}
+	stripped := stripSQLLiterals(sql)
+	for _, syntax := range r.syntaxes {
+		if syntax.pattern.MatchString(stripped) {
+			reasons = append(reasons, syntax.reason)
+		}
+	}
+	rewritten := r.rewriteExpr(sql, &reasons)
+	return rewritten, funk.UniqString(reasons)
+}
+
+func (r *sqlRewriter) rewriteExpr(sql string, reasons *[]string) string {
+	var sb strings.Builder
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		if ch == '\'' || ch == '`' {
+			end := skipSQLQuoted(sql, i)
+			sb.WriteString(sql[i:end])
+			i = end
+			continue
+		}
+		if ch == '"' {
+			end := skipSQLQuoted(sql, i)
+			sb.WriteString("`" + strings.Trim(sql[i:end], "\"") + "`")
+			i = end
+			continue
+		}
+		if !isSQLIdentStart(ch) || (i > 0 && isSQLIdentPart(sql[i-1])) {
+			sb.WriteByte(ch)
+			i++
+			continue
+		}
+		end := i
+		for end < len(sql) && isSQLIdentPart(sql[end]) {
+			end++
+		}
+		word := sql[i:end]
+		open := skipSQLSpaces(sql, end)
+		qualified := len(strings.TrimSpace(sql[:i])) > 0 && strings.HasSuffix(strings.TrimSpace(sql[:i]), ".")
+		if open >= len(sql) || sql[open] != '(' || qualified || funk.ContainsString(r.keywords, strings.ToLower(word)) {
+			sb.WriteString(word)
+			i = end
+			continue
+		}
+		closePos := matchSQLParen(sql, open)
+		if closePos >= 0 && precededBySQLAs(sql[:i]) {
+			// type name of a cast like `CAST(x AS DECIMAL(10, 2))`
+			sb.WriteString(sql[i : closePos+1])
+			i = closePos + 1
+			continue
+		}
+		if closePos < 0 {
+			*reasons = append(*reasons, "unbalanced parentheses")
+			sb.WriteString(sql[i:])
+			break
+		}
+		args := splitSQLArgs(sql[open+1 : closePos])
+		var params []string
+		next := closePos + 1
+		if paramOpen := skipSQLSpaces(sql, next); paramOpen < len(sql) && sql[paramOpen] == '(' {
+			if paramClose := matchSQLParen(sql, paramOpen); paramClose > 0 {
+				params = args
+				args = splitSQLArgs(sql[paramOpen+1 : paramClose])
+				next = paramClose + 1
+			}
+		}
+		for idx, arg := range args {
+			args[idx] = r.rewriteExpr(arg, reasons)
+		}
+		rewriter, ok := r.functions[strings.ToLower(word)]
+		if !ok {
+			*reasons = append(*reasons, fmt.Sprintf("function `%s` is not supported", word))
+			sb.WriteString(sql[i:next])
+			i = next
+			continue
+		}
+		rewritten, err := rewriter(params, args)
+		if err != nil {
+			*reasons = append(*reasons, fmt.Sprintf("function `%s`: %s", word, err.Error()))
+			sb.WriteString(sql[i:next])
+		} else {
+			sb.WriteString(rewritten)
+		}
+		i = next
+	}
+	return sb.String()
+}
+
+func sameSQLFunction(name string) sqlFunctionRewriter {
+	return func(params, args []string) (string, error) {
+		if params != nil {
+			return "", fmt.Errorf("parametric call is not supported")
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", ")), nil
+	}
+}
+
+// templateSQLFunction renders the args into the template in order
+func templateSQLFunction(template string, argNum int) sqlFunctionRewriter {
+	return func(params, args []string) (string, error) {
+		if params != nil {
+			return "", fmt.Errorf("parametric call is not supported")
+		}
+		if len(args) != argNum {
+			return "", fmt.Errorf("expected %d arguments but got %d", argNum, len(args))
+		}
+		return fmt.Sprintf(template, funk.Map(args, func(arg string) interface{} {
+			return arg
+		}).([]interface{})...), nil
+	}
+}
+
+func stripSQLLiterals(sql string) string {
+	var sb strings.Builder
+	for i := 0; i < len(sql); {
+		if sql[i] == '\'' || sql[i] == '`' || sql[i] == '"' {
+			end := skipSQLQuoted(sql, i)
+			sb.WriteString(" ")
+			i = end
+			continue
+		}
+		sb.WriteByte(sql[i])
+		i++
+	}
+	return sb.String()
+}
+
+// skipSQLQuoted returns the position after the quoted token starting at pos
+func skipSQLQuoted(sql string, pos int) int {
+	quote := sql[pos]
+	for i := pos + 1; i < len(sql); i++ {
+		if sql[i] == '\\' {
+			i++
+			continue
+		}
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+func skipSQLSpaces(sql string, pos int) int {
+	for pos < len(sql) && (sql[pos] == ' ' || sql[pos] == '\t' || sql[pos] == '\n' || sql[pos] == '\r') {
+		pos++
+	}
+	return pos
+}
+
+// matchSQLParen returns the position of the parenthesis closing the one at pos
+func matchSQLParen(sql string, pos int) int {
+	depth := 0
+	for i := pos; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '`', '"':
+			i = skipSQLQuoted(sql, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitSQLArgs splits the arguments of a function call on top-level commas
+func splitSQLArgs(sql string) []string {
+	args := []string{}
+	if len(strings.TrimSpace(sql)) == 0 {
+		return args
+	}
+	depth := 0
+	start := 0
+	for i := 0; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '`', '"':
+			i = skipSQLQuoted(sql, i) - 1
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(sql[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(sql[start:]))
+}
+
+func precededBySQLAs(sql string) bool {
+	prefix := strings.TrimRight(sql, " \t\r\n")
+	if len(prefix) < 2 || !strings.EqualFold(prefix[len(prefix)-2:], "as") {
+		return false
+	}
+	return len(prefix) == 2 || !isSQLIdentPart(prefix[len(prefix)-3])
+}
+
+func isSQLIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isSQLIdentPart(ch byte) bool {
+	return isSQLIdentStart(ch) || (ch >= '0' && ch <= '9')
+}
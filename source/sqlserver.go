@@ -3,6 +3,7 @@ package source
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
 	"starrocks-migrate-tool/model"
@@ -18,6 +19,36 @@ type SQLServerSource struct {
 	DBSource
 }
 
+// sqlServerComputedColumn sys.computed_columns rows
+type sqlServerComputedColumn struct {
+	TABLE_SCHEMA string `gorm:"column:table_schema"`
+	TABLE_NAME   string `gorm:"column:table_name"`
+	COLUMN_NAME  string `gorm:"column:column_name"`
+	DEFINITION   string `gorm:"column:definition"`
+}
+
+// sqlServerTypes types without a native counterpart, [StarRocks type, flink type]
+var sqlServerTypes = map[string][]string{
+	// 16 bytes guid in the canonical text form
+	"uniqueidentifier": {"VARCHAR(36)", "STRING"},
+	// `rowversion` is reported as `timestamp`, an 8 bytes binary counter captured as bytes
+	"timestamp":  {"STRING", "BYTES"},
+	"rowversion": {"STRING", "BYTES"},
+	"binary":     {"STRING", "BYTES"},
+	"varbinary":  {"STRING", "BYTES"},
+	"image":      {"STRING", "BYTES"},
+	// serialized tree path, `ToString()` gives the readable `/1/2/` form
+	"hierarchyid": {"STRING", "BYTES"},
+	// spatial types are captured as WKB
+	"geography": {"STRING", "BYTES"},
+	"geometry":  {"STRING", "BYTES"},
+	// values of mixed base types are only kept as text
+	"sql_variant": {"STRING", "STRING"},
+	"xml":         {"STRING", "STRING"},
+}
+
+var sqlServerIdentifierReg = regexp.MustCompile(`\[([^\]]+)\]`)
+
 func (c *SQLServerSource) Construct(config *conf.Config) IDBSource {
 	c.config = config
 	return c
@@ -70,10 +101,12 @@ func (c *SQLServerSource) Tables(db, schema string) ([]string, error) {
 }
 
 func (c *SQLServerSource) Sample(db, schema, table string, limit int) ([]map[string]interface{}, error) {
-	results := []map[string]interface{}{}
-	c.switchDB(db)
-	c.db.Raw(fmt.Sprintf("SELECT TOP %d * FROM %s.%s.%s;", limit, db, schema, table)).Find(&results)
-	return results, nil
+	return c.sampleOnce(db, schema, table, limit, func() ([]map[string]interface{}, error) {
+		results := []map[string]interface{}{}
+		c.switchDB(db)
+		err := c.db.Raw(fmt.Sprintf("SELECT TOP %d * FROM [%s].[%s].[%s];", limit, db, schema, table)).Find(&results).Error
+		return results, err
+	})
 }
 
 func (c *SQLServerSource) Destroy() {
@@ -140,12 +173,42 @@ func (c *SQLServerSource) Build() (IDBSourceProvider, error) {
 		c.switchDB(db)
 		matchedTables := []*model.Table{}
 		c.db.Where("TABLE_TYPE=?", "BASE TABLE").Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&matchedTables)
+		// history tables of system-versioned temporal tables
+		historyTables := []*model.Table{}
+		c.db.Raw("SELECT s.name AS table_schema, t.name AS table_name FROM sys.tables t JOIN sys.schemas s ON s.schema_id = t.schema_id WHERE t.temporal_type = 1").Scan(&historyTables)
+		matchedTables = funk.Filter(matchedTables, func(table *model.Table) bool {
+			return !funk.Some(historyTables, func(historyTable *model.Table) bool {
+				return historyTable.TABLE_SCHEMA == table.TABLE_SCHEMA && historyTable.TABLE_NAME == table.TABLE_NAME
+			})
+		}).([]*model.Table)
 		allMatchedTables = append(allMatchedTables, matchedTables...)
 		columns := []*model.Column{}
-		c.db.Order("ORDINAL_POSITION asc").Find(&columns)
+		c.db.Select("*, CASE WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(TABLE_SCHEMA) + '.' + QUOTENAME(TABLE_NAME)), COLUMN_NAME, 'IsIdentity') = 1 THEN 'auto_increment' ELSE '' END AS EXTRA").
+			Order("ORDINAL_POSITION asc").Find(&columns)
+		computedColumns := []*sqlServerComputedColumn{}
+		c.db.Raw("SELECT s.name AS table_schema, t.name AS table_name, cc.name AS column_name, cc.definition FROM sys.computed_columns cc JOIN sys.tables t ON t.object_id = cc.object_id JOIN sys.schemas s ON s.schema_id = t.schema_id").Scan(&computedColumns)
+		for _, computedColumn := range computedColumns {
+			for _, column := range columns {
+				if column.TABLE_SCHEMA == computedColumn.TABLE_SCHEMA && column.TABLE_NAME == computedColumn.TABLE_NAME && column.COLUMN_NAME == computedColumn.COLUMN_NAME {
+					column.GenerationExpression = computedColumn.DEFINITION
+					break
+				}
+			}
+		}
+		// clustered indexes other than the primary keys
+		sortKeyColumns := []*columnStatistic{}
+		c.db.Raw("SELECT s.name AS table_schema, t.name AS table_name, col.name AS column_name, ic.key_ordinal AS position FROM sys.indexes i " +
+			"JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id JOIN sys.columns col ON col.object_id = ic.object_id AND col.column_id = ic.column_id " +
+			"JOIN sys.tables t ON t.object_id = i.object_id JOIN sys.schemas s ON s.schema_id = t.schema_id WHERE i.type = 1 AND i.is_primary_key = 0 AND ic.key_ordinal > 0").Scan(&sortKeyColumns)
+		c.applyStatistics(columns, sortKeyColumns)
 		allColumns = append(allColumns, columns...)
 		keyColumnUsageRows := []*model.KeyColumnUsage{}
-		c.db.Model(&model.KeyColumnUsage{}).Find(&keyColumnUsageRows)
+		c.db.Table("information_schema.key_column_usage k").
+			Select("k.*, t.CONSTRAINT_TYPE, coalesce(r.TABLE_SCHEMA, '') AS REFERENCED_TABLE_SCHEMA, coalesce(r.TABLE_NAME, '') AS REFERENCED_TABLE_NAME, coalesce(r.COLUMN_NAME, '') AS REFERENCED_COLUMN_NAME").
+			Joins("JOIN information_schema.table_constraints t ON t.CONSTRAINT_CATALOG = k.CONSTRAINT_CATALOG AND t.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND t.CONSTRAINT_NAME = k.CONSTRAINT_NAME").
+			Joins("LEFT JOIN information_schema.referential_constraints rc ON rc.CONSTRAINT_CATALOG = k.CONSTRAINT_CATALOG AND rc.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = k.CONSTRAINT_NAME").
+			Joins("LEFT JOIN information_schema.key_column_usage r ON r.CONSTRAINT_CATALOG = rc.UNIQUE_CONSTRAINT_CATALOG AND r.CONSTRAINT_SCHEMA = rc.UNIQUE_CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = rc.UNIQUE_CONSTRAINT_NAME AND r.ORDINAL_POSITION = k.ORDINAL_POSITION").
+			Find(&keyColumnUsageRows)
 		allKeyColumnUsageRows = append(allKeyColumnUsageRows, keyColumnUsageRows...)
 	}
 	c.calculateRuledTablesMap(allMatchedTables, allColumns, allKeyColumnUsageRows)
@@ -159,7 +222,7 @@ func (c *SQLServerSource) GetRuledTablesMap() map[*conf.TableRule][]*common.Tabl
 	return c.ruledTablesMap
 }
 
-func (c *SQLServerSource) FormatFlinkColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *SQLServerSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := ""
 	switch column.DATA_TYPE {
 	case "char", "varchar", "nvarchar", "nchar", "text", "ntext", "xml":
@@ -191,6 +254,9 @@ func (c *SQLServerSource) FormatFlinkColumnDef(table *model.Table, column *model
 		break
 	default:
 		colDataType = "STRING"
+		if types, ok := sqlServerTypes[column.DATA_TYPE]; ok {
+			colDataType = types[1]
+		}
 		break
 	}
 
@@ -198,14 +264,22 @@ func (c *SQLServerSource) FormatFlinkColumnDef(table *model.Table, column *model
 	if column.IS_NULLABLE != "YES" {
 		nullableStr = "NOT NULL"
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, true)
 	columnStr := fmt.Sprintf("  `%s` %s %s", column.COLUMN_NAME, colDataType, nullableStr)
 	return columnStr, nil
 }
 
-func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *model.Column) (string, error) {
+func (c *SQLServerSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
 	colDataType := ""
 	switch column.DATA_TYPE {
-	case "char", "nchar", "varchar", "nvarchar", "text", "ntext":
+	case "char", "nchar":
+		colDataType = c.stringType(matchedTableRule, column, true)
+		break
+	case "varchar", "nvarchar":
+		// -1 of `max`
+		colDataType = c.stringType(matchedTableRule, column, false)
+		break
+	case "text", "ntext":
 		colDataType = "STRING"
 		break
 	case "tinyint", "smallint", "int", "bigint":
@@ -221,7 +295,7 @@ func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *m
 		}
 		break
 	case "decimal", "numeric", "money", "smallmoney":
-		if !c.config.UseDecimalV3 {
+		if !c.config.Supports(conf.CapabilityDecimalV3) {
 			if column.NUMERIC_PRECISION > 27 {
 				column.NUMERIC_PRECISION = 27
 			}
@@ -249,8 +323,23 @@ func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *m
 		break
 	default:
 		colDataType = "STRING"
+		if types, ok := sqlServerTypes[column.DATA_TYPE]; ok {
+			colDataType = types[0]
+		}
 		break
 	}
+	colDataType = c.columnType(matchedTableRule, column, colDataType, false)
+	if column.IsGenerated() {
+		expression, reasons := c.computedColumnRewriter(column).rewrite(c.unquoteIdentifiers(column.GenerationExpression))
+		for _, reason := range reasons {
+			column.AddDefaultWarning(fmt.Sprintf("computed column excluded: %s", reason))
+		}
+		if len(reasons) > 0 || !c.generatedColumnSupported(column) {
+			// computed columns not portable are excluded
+			return "", nil
+		}
+		return fmt.Sprintf("  `%s` %s NULL AS %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, expression, c.encodeComment(column.COLUMN_COMMENT)), nil
+	}
 
 	nullableStr := "NULL"
 	if column.IS_NULLABLE != "YES" {
@@ -260,20 +349,19 @@ func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *m
 	if column.COLUMN_DEFAULT != nil {
 		columnDefault := *column.COLUMN_DEFAULT
 		if len(columnDefault) > 0 {
-			if strings.HasPrefix(columnDefault, "(") {
-				columnDefault = columnDefault[1:]
-			}
-			if strings.HasSuffix(columnDefault, ")") {
-				columnDefault = columnDefault[0 : len(columnDefault)-1]
-			}
-			if strings.HasPrefix(columnDefault, "'") {
+			// `(N'abc')`, `((0))`
+			columnDefault = c.trimDefault(columnDefault)
+			if strings.HasPrefix(columnDefault, "N'") {
 				columnDefault = columnDefault[1:]
 			}
-			if strings.HasSuffix(columnDefault, "'") {
-				columnDefault = columnDefault[0 : len(columnDefault)-1]
-			}
-
-			if columnDefault != "NULL" {
+			literal, isLiteral := c.literalDefault(columnDefault)
+			if strings.EqualFold(columnDefault, "NULL") {
+				nullableStr = "NULL"
+			} else if !isLiteral {
+				// the current timestamp or unsupported functions
+				defaultStr = c.defaultClause(column, colDataType, columnDefault)
+			} else {
+				columnDefault = literal
 				dateTemplate := ""
 				if column.DATA_TYPE == "date" {
 					dateTemplate = common.DATE_TEMPLATE
@@ -294,6 +382,7 @@ func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *m
 					dt, err := time.Parse(dateTemplate, columnDefault)
 					if err != nil {
 						nullableStr = "NULL"
+						column.AddDefaultWarning(fmt.Sprintf("default `%s` is not a valid date, the column is nullable instead", columnDefault))
 					} else {
 						columnDefault = dt.Format(dateTemplate)
 						if column.DATA_TYPE == "time" {
@@ -307,22 +396,59 @@ func (c *SQLServerSource) FormatStarRocksColumnDef(table *model.Table, column *m
 				} else {
 					defaultStr = fmt.Sprintf("DEFAULT \"%s\"", columnDefault)
 				}
-			} else {
-				nullableStr = "NULL"
 			}
 		} else {
 			defaultStr = fmt.Sprintf("DEFAULT \"%s\"", columnDefault)
 		}
 	}
+	if column.IsAutoIncrement() {
+		if dataType, ok := c.autoIncrement(table, column, colDataType); ok {
+			colDataType = dataType
+			nullableStr = "NOT NULL"
+			defaultStr = "AUTO_INCREMENT"
+		}
+	}
 	columnStr := fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT))
 	return columnStr, nil
 }
 
+// unquoteIdentifiers quotes `[name]` identifiers with backticks
+func (c *SQLServerSource) unquoteIdentifiers(expression string) string {
+	return sqlServerIdentifierReg.ReplaceAllString(expression, "`$1`")
+}
+
+func (c *SQLServerSource) computedColumnRewriter(column *model.Column) *sqlRewriter {
+	functions := map[string]sqlFunctionRewriter{}
+	for _, name := range []string{
+		"abs", "floor", "round", "power", "sqrt", "upper", "lower", "ltrim", "rtrim", "trim", "replace", "substring",
+		"left", "right", "reverse", "coalesce", "nullif", "concat", "year", "month", "day",
+	} {
+		functions[name] = sameSQLFunction(name)
+	}
+	for name, target := range map[string]string{
+		"len": "char_length", "ceiling": "ceil", "isnull": "ifnull", "getdate": "now", "sysdatetime": "now", "iif": "if",
+	} {
+		functions[name] = sameSQLFunction(target)
+	}
+	syntaxes := []*sqlUnsupportedSyntax{
+		{pattern: regexp.MustCompile(`(?i)\bCONVERT\s*\(`), reason: "`CONVERT` is not supported"},
+	}
+	if funk.ContainsString([]string{"char", "nchar", "varchar", "nvarchar", "text", "ntext"}, column.DATA_TYPE) {
+		// `+` concatenates strings
+		syntaxes = append(syntaxes, &sqlUnsupportedSyntax{pattern: regexp.MustCompile(`\+`), reason: "string concatenation with `+` is not supported"})
+	}
+	return &sqlRewriter{
+		functions: functions,
+		keywords:  sqlKeywords,
+		syntaxes:  syntaxes,
+	}
+}
+
 func (c *SQLServerSource) GetFlinkConnectorName() string {
 	return "sqlserver-cdc"
 }
 
-func (c *SQLServerSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *SQLServerSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	return nil
 }
 
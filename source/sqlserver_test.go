@@ -0,0 +1,32 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+)
+
+func TestSQLServerSource_FormatStarRocksColumnDef(t *testing.T) {
+	source := new(SQLServerSource).Construct(&conf.Config{StarRocksVersion: "3.1.0", UseDecimalV3: true}).(*SQLServerSource)
+	tests := []struct {
+		name   string
+		column *model.Column
+		want   string
+	}{
+		{"uniqueidentifier", &model.Column{COLUMN_NAME: "id", DATA_TYPE: "uniqueidentifier", IS_NULLABLE: "NO"}, "  `id` VARCHAR(36) NOT NULL  COMMENT \"\""},
+		{"computed", &model.Column{COLUMN_NAME: "total", DATA_TYPE: "int", GenerationExpression: "([price]*isnull([qty],(0)))"}, "  `total` INT NULL AS (`price`*ifnull(`qty`, (0))) COMMENT \"\""},
+		{"string concatenation", &model.Column{COLUMN_NAME: "full_name", DATA_TYPE: "nvarchar", GenerationExpression: "(([first]+' ')+[last])"}, ""},
+		{"unknown function", &model.Column{COLUMN_NAME: "code", DATA_TYPE: "int", GenerationExpression: "(checksum([name]))"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.FormatStarRocksColumnDef(&conf.TableRule{}, nil, tt.column)
+			if err != nil || got != tt.want {
+				t.Errorf("FormatStarRocksColumnDef() = %v, %v, want %v", got, err, tt.want)
+			}
+			if (len(tt.column.DefaultWarnings) > 0) != (len(tt.want) == 0) {
+				t.Errorf("FormatStarRocksColumnDef() warnings = %v", tt.column.DefaultWarnings)
+			}
+		})
+	}
+}
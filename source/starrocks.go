@@ -0,0 +1,640 @@
+package source
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"starrocks-migrate-tool/common"
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+)
+
+// databases of the clusters not migrated
+var olapSystemDatabases = []string{"information_schema", "_statistics_", "__internal_schema", "sys", "mysql"}
+
+// aggregation functions of starrocks and doris
+var olapAggregations = []string{"SUM", "MAX", "MIN", "REPLACE", "REPLACE_IF_NOT_NULL", "HLL_UNION", "BITMAP_UNION", "PERCENTILE_UNION", "QUANTILE_UNION", "GENERIC"}
+
+var olapEngineReg = regexp.MustCompile(`(?i)^ENGINE\s*=\s*(\w+)`)
+var olapPartitionReg = regexp.MustCompile("(?is)^PARTITION\\s+`?([^`\\s]+)`?\\s+(VALUES\\b.*)$")
+var olapDateTruncReg = regexp.MustCompile(`(?is)^date_trunc\s*\(\s*(.+?)\s*,\s*('[^']*'|"[^"]*")\s*\)$`)
+var olapSizeReg = regexp.MustCompile(`(?i)^([\d.]+)\s*([KMGTP]?B)?$`)
+var olapNestedTypeReg = regexp.MustCompile(`(?i)\b(ARRAY|MAP|STRUCT)\s*$`)
+
+// StarRocksSource tables of starrocks and apache doris clusters reproduced by `SHOW CREATE TABLE`
+type StarRocksSource struct {
+	MySQLSource
+}
+
+func (c *StarRocksSource) Construct(config *conf.Config) IDBSource {
+	c.MySQLSource.Construct(config)
+	return c
+}
+
+func (c *StarRocksSource) ResultConventers() int {
+	return common.ConvertToStarRocks | common.ConvertToStarRocksExternal | common.ConvertToStarRocksBackfill
+}
+
+func (c *StarRocksSource) Build() (IDBSourceProvider, error) {
+	tables := []*model.Table{}
+	err := c.db.Where("TABLE_TYPE=?", "BASE TABLE").Order("TABLE_SCHEMA asc, TABLE_NAME asc").Find(&tables).Error
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to get rows from information_schema.tables.")
+	}
+	matchedTables := []*model.Table{}
+	allColumns := []*model.Column{}
+	keyColumnUsageRows := []*model.KeyColumnUsage{}
+	for _, table := range tables {
+		table.TABLE_CATALOG = table.TABLE_SCHEMA
+		if funk.ContainsString(olapSystemDatabases, strings.ToLower(table.TABLE_SCHEMA)) || c.matchTableRule(table.TABLE_CATALOG, table.TABLE_SCHEMA, table.TABLE_NAME) == nil {
+			continue
+		}
+		columns, kcuList, err := c.describeTable(table)
+		if err != nil {
+			return nil, fmt.Errorf("Table [%s.%s]: %s", table.TABLE_SCHEMA, table.TABLE_NAME, err.Error())
+		}
+		if table.Olap == nil {
+			glog.Warningf("table [%s.%s] is not an OLAP table, skipped", table.TABLE_SCHEMA, table.TABLE_NAME)
+			continue
+		}
+		matchedTables = append(matchedTables, table)
+		allColumns = append(allColumns, columns...)
+		keyColumnUsageRows = append(keyColumnUsageRows, kcuList...)
+	}
+	c.calculateRuledTablesMap(matchedTables, allColumns, keyColumnUsageRows)
+	if len(c.ruledTablesMap) == 0 {
+		return c, errors.New("No matching table columns found.")
+	}
+	return c, nil
+}
+
+// describeTable columns and keys of `SHOW CREATE TABLE`, rollups of `DESC ALL` and sizes of `SHOW PARTITIONS`
+func (c *StarRocksSource) describeTable(table *model.Table) ([]*model.Column, []*model.KeyColumnUsage, error) {
+	results := []map[string]interface{}{}
+	if err := c.db.Raw(fmt.Sprintf("SHOW CREATE TABLE `%s`.`%s`", table.TABLE_SCHEMA, table.TABLE_NAME)).Find(&results).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil, errors.New("`SHOW CREATE TABLE` returns nothing")
+	}
+	columns, olap, err := c.parseCreateTable(table, olapValue(results[0], "Create Table"))
+	if err != nil || olap == nil {
+		return nil, nil, err
+	}
+	table.Olap = olap
+	// 1. rollups
+	results = []map[string]interface{}{}
+	if err := c.db.Raw(fmt.Sprintf("DESC `%s`.`%s` ALL", table.TABLE_SCHEMA, table.TABLE_NAME)).Find(&results).Error; err != nil {
+		return nil, nil, err
+	}
+	olap.Rollups = c.rollups(table, results)
+	// 2. sizes of the partitions
+	results = []map[string]interface{}{}
+	if err := c.db.Raw(fmt.Sprintf("SHOW PARTITIONS FROM `%s`.`%s`", table.TABLE_SCHEMA, table.TABLE_NAME)).Find(&results).Error; err != nil {
+		return nil, nil, err
+	}
+	c.applyPartitionSizes(table, results)
+	// 3. keys of primary key and unique key tables
+	kcuList := []*model.KeyColumnUsage{}
+	if olap.KeysType == "PRIMARY" || olap.KeysType == "UNIQUE" {
+		for idx, key := range olap.Keys {
+			kcuList = append(kcuList, &model.KeyColumnUsage{
+				ModelBase:        table.ModelBase,
+				COLUMN_NAME:      key,
+				CONSTRAINT_NAME:  "PRIMARY",
+				CONSTRAINT_TYPE:  common.CONSTRAINT_PRIMARY_KEY,
+				ORDINAL_POSITION: uint64(idx + 1),
+			})
+		}
+	}
+	return columns, kcuList, nil
+}
+
+// parseCreateTable columns and the definition of the table, nil if not an OLAP table
+func (c *StarRocksSource) parseCreateTable(table *model.Table, ddl string) ([]*model.Column, *model.OlapTable, error) {
+	start := strings.Index(ddl, "(")
+	end := -1
+	if start > 0 {
+		end = matchOlapGroup(ddl, start)
+	}
+	if end < 0 {
+		return nil, nil, errors.New("malformed `SHOW CREATE TABLE`")
+	}
+	olap := &model.OlapTable{KeysType: "DUPLICATE", Aggregations: map[string]string{}, Properties: map[string]string{}}
+	// 1. clauses following the columns
+	tokenizer := &olapTokenizer{sql: ddl, pos: end + 1}
+	for tokenizer.pos < len(ddl) {
+		tokenizer.pos = skipSQLSpaces(ddl, tokenizer.pos)
+		if matches := olapEngineReg.FindStringSubmatch(ddl[tokenizer.pos:]); len(matches) > 0 {
+			if !strings.EqualFold(matches[1], "OLAP") {
+				return nil, nil, nil
+			}
+			tokenizer.pos += len(matches[0])
+			continue
+		}
+		token := tokenizer.next()
+		switch strings.ToUpper(token) {
+		case "":
+			tokenizer.pos = len(ddl)
+			break
+		case "DUPLICATE", "AGGREGATE", "UNIQUE", "PRIMARY":
+			olap.KeysType = strings.ToUpper(token)
+			// KEY
+			tokenizer.next()
+			olap.Keys = olapNames(tokenizer.next())
+			break
+		case "COMMENT":
+			table.TABLE_COMMENT = tokenizer.next()
+			break
+		case "AUTO", "PARTITION":
+			// partitions of doris created by the loaded data
+			auto := strings.EqualFold(token, "AUTO")
+			if auto {
+				tokenizer.next()
+			}
+			// BY
+			tokenizer.next()
+			c.parsePartitionBy(olap, auto, tokenizer)
+			break
+		case "DISTRIBUTED":
+			// BY
+			tokenizer.next()
+			if strings.EqualFold(tokenizer.next(), "HASH") {
+				olap.DistributedBy = olapNames(tokenizer.next())
+			}
+			if strings.EqualFold(tokenizer.peek(), "BUCKETS") {
+				tokenizer.next()
+				// 0 for BUCKETS AUTO
+				olap.Buckets, _ = strconv.ParseInt(tokenizer.next(), 10, 64)
+			}
+			break
+		case "ORDER":
+			// BY
+			tokenizer.next()
+			olap.OrderBy = olapNames(tokenizer.next())
+			break
+		case "PROPERTIES":
+			for _, property := range splitOlapDefs(strings.TrimSuffix(strings.TrimPrefix(tokenizer.next(), "("), ")")) {
+				key, after := olapToken(property, 0)
+				value, _ := olapToken(property, skipSQLSpaces(property, after)+1)
+				olap.Properties[key] = value
+			}
+			break
+		}
+	}
+	// 2. columns and indexes
+	columns := []*model.Column{}
+	for _, def := range splitOlapDefs(ddl[start+1 : end]) {
+		if strings.HasPrefix(strings.ToUpper(def), "INDEX") {
+			olap.Indexes = append(olap.Indexes, def)
+			continue
+		}
+		if !strings.HasPrefix(def, "`") {
+			continue
+		}
+		column, agg := c.parseColumn(table, def, len(columns)+1)
+		if len(agg) > 0 {
+			olap.Aggregations[column.COLUMN_NAME] = agg
+		}
+		columns = append(columns, column)
+	}
+	return columns, olap, nil
+}
+
+// parsePartitionBy `RANGE(cols) (partitions)`, `LIST(cols) (partitions)` or the expressions of the partitions created by the loaded data
+func (c *StarRocksSource) parsePartitionBy(olap *model.OlapTable, auto bool, tokenizer *olapTokenizer) {
+	kind := strings.ToUpper(tokenizer.peek())
+	if kind == "RANGE" || kind == "LIST" {
+		tokenizer.next()
+		olap.PartitionType = kind
+		olap.PartitionColumns = olapNames(tokenizer.next())
+	} else if strings.HasPrefix(kind, "(") {
+		olap.PartitionType = "EXPRESSION"
+		olap.PartitionColumns = olapNames(tokenizer.next())
+	} else {
+		olap.PartitionType = "EXPRESSION"
+		expression := tokenizer.next()
+		if tokenizer.pos < len(tokenizer.sql) && tokenizer.sql[tokenizer.pos] == '(' {
+			// date_trunc('day', dt)
+			expression += tokenizer.next()
+		}
+		olap.PartitionColumns = []string{expression}
+	}
+	if auto {
+		olap.PartitionType = "EXPRESSION"
+		for idx, expression := range olap.PartitionColumns {
+			// date_trunc(`dt`, 'day') of doris
+			if matches := olapDateTruncReg.FindStringSubmatch(expression); len(matches) > 0 {
+				olap.PartitionColumns[idx] = fmt.Sprintf("date_trunc(%s, %s)", matches[2], matches[1])
+			}
+		}
+	}
+	if !strings.HasPrefix(tokenizer.peek(), "(") {
+		return
+	}
+	group := tokenizer.next()
+	for _, def := range splitOlapDefs(group[1 : len(group)-1]) {
+		if matches := olapPartitionReg.FindStringSubmatch(def); len(matches) > 0 {
+			olap.Partitions = append(olap.Partitions, &model.OlapPartition{Name: matches[1], Values: strings.TrimSpace(matches[2])})
+		}
+	}
+}
+
+// parseColumn the column of the definition and its aggregation function
+func (c *StarRocksSource) parseColumn(table *model.Table, def string, position int) (*model.Column, string) {
+	name, pos := olapToken(def, 0)
+	pos = skipSQLSpaces(def, pos)
+	// types end at the first space out of the brackets
+	typeEnd := pos
+	for depth := 0; typeEnd < len(def); typeEnd++ {
+		ch := def[typeEnd]
+		if ch == '(' || ch == '<' || ch == '[' {
+			depth++
+		} else if ch == ')' || ch == '>' || ch == ']' {
+			depth--
+		} else if depth == 0 && (ch == ' ' || ch == '\t' || ch == '\n') {
+			break
+		}
+	}
+	colType := c.olapType(def[pos:typeEnd])
+	dataType := strings.ToLower(colType)
+	if idx := strings.IndexAny(dataType, "(<"); idx > 0 {
+		dataType = dataType[:idx]
+	}
+	column := &model.Column{
+		ModelBase:        table.ModelBase,
+		COLUMN_NAME:      name,
+		ORDINAL_POSITION: uint64(position),
+		IS_NULLABLE:      "YES",
+		DATA_TYPE:        dataType,
+		COLUMN_TYPE:      colType,
+	}
+	agg := ""
+	for pos = typeEnd; pos < len(def); {
+		start := skipSQLSpaces(def, pos)
+		token, after := olapToken(def, start)
+		if len(token) == 0 {
+			break
+		}
+		pos = after
+		switch strings.ToUpper(token) {
+		case "NOT":
+			// NULL
+			_, pos = olapToken(def, pos)
+			column.IS_NULLABLE = "NO"
+			break
+		case "AUTO_INCREMENT":
+			column.EXTRA = "auto_increment"
+			break
+		case "DEFAULT":
+			valueStart := skipSQLSpaces(def, pos)
+			value, valueEnd := olapToken(def, valueStart)
+			if valueStart < len(def) && (def[valueStart] == '"' || def[valueStart] == '\'') {
+				// quoted by single quotes as the other sources
+				value = "'" + strings.Replace(value, "'", "''", -1) + "'"
+			} else if next, nextEnd := olapToken(def, valueEnd); strings.HasPrefix(next, "(") {
+				// CURRENT_TIMESTAMP(3)
+				value, valueEnd = value+next, nextEnd
+			}
+			column.COLUMN_DEFAULT = &value
+			pos = valueEnd
+			break
+		case "ON":
+			// ON UPDATE CURRENT_TIMESTAMP
+			_, pos = olapToken(def, pos)
+			_, pos = olapToken(def, pos)
+			column.EXTRA = "on update CURRENT_TIMESTAMP"
+			break
+		case "AS":
+			column.GenerationExpression, pos = olapToken(def, pos)
+			break
+		case "COMMENT":
+			column.COLUMN_COMMENT, pos = olapToken(def, pos)
+			break
+		default:
+			if funk.ContainsString(olapAggregations, strings.ToUpper(token)) {
+				agg = strings.ToUpper(token)
+			}
+			break
+		}
+	}
+	return column, agg
+}
+
+// olapType the starrocks type of the starrocks or doris type, types of doris and older versions are upgraded
+func (c *StarRocksSource) olapType(colType string) string {
+	colType = strings.TrimSpace(colType)
+	name := strings.ToUpper(colType)
+	args := ""
+	if idx := strings.IndexAny(colType, "(<"); idx > 0 {
+		name = strings.ToUpper(strings.TrimSpace(colType[:idx]))
+		args = strings.TrimSpace(colType[idx+1 : len(colType)-1])
+	}
+	switch name {
+	case "BOOLEAN", "BOOL":
+		return "BOOLEAN"
+	case "TINYINT", "SMALLINT", "INT", "BIGINT", "LARGEINT", "FLOAT", "DOUBLE", "HLL", "BITMAP", "PERCENTILE", "TIME":
+		// display widths of starrocks
+		return name
+	case "INTEGER":
+		return "INT"
+	case "DECIMAL", "DECIMALV2", "DECIMALV3", "DECIMAL32", "DECIMAL64", "DECIMAL128", "DECIMAL128I", "NUMERIC":
+		precision, scale := uint64(10), uint64(0)
+		if parts := strings.Split(args, ","); len(args) > 0 {
+			precision, _ = strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64)
+			if len(parts) > 1 {
+				scale, _ = strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+			}
+		}
+		if (!c.config.Supports(conf.CapabilityDecimalV3) && precision > 27) || precision > 38 {
+			// decimal256 of doris
+			return "STRING"
+		}
+		return fmt.Sprintf("DECIMAL(%d, %d)", precision, scale)
+	case "DATE", "DATEV1", "DATEV2":
+		return "DATE"
+	case "DATETIME", "DATETIMEV1", "DATETIMEV2", "TIMESTAMP":
+		return "DATETIME"
+	case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+		if len(args) == 0 {
+			return name
+		}
+		if args == "*" {
+			// max length of doris
+			args = "65533"
+		}
+		return fmt.Sprintf("%s(%s)", name, args)
+	case "STRING", "TEXT":
+		return "STRING"
+	case "JSON", "JSONB", "VARIANT":
+		return "JSON"
+	case "IPV4":
+		return "VARCHAR(15)"
+	case "IPV6":
+		return "VARCHAR(39)"
+	case "ARRAY":
+		return fmt.Sprintf("ARRAY<%s>", c.olapType(args))
+	case "MAP":
+		kv := splitOlapDefs(args)
+		if len(kv) == 2 {
+			return fmt.Sprintf("MAP<%s, %s>", c.olapType(kv[0]), c.olapType(kv[1]))
+		}
+		break
+	case "STRUCT":
+		fields := []string{}
+		for _, field := range splitOlapDefs(args) {
+			// `name type` of starrocks, `name:type COMMENT ''` of doris
+			idx := strings.IndexAny(field, " :")
+			if idx <= 0 {
+				continue
+			}
+			fieldType := strings.TrimSpace(field[idx+1:])
+			if commentIdx := strings.Index(strings.ToUpper(fieldType), " COMMENT "); commentIdx > 0 {
+				fieldType = fieldType[:commentIdx]
+			}
+			fields = append(fields, fmt.Sprintf("`%s` %s", strings.Trim(field[:idx], "`"), c.olapType(fieldType)))
+		}
+		return fmt.Sprintf("STRUCT<%s>", strings.Join(fields, ", "))
+	}
+	glog.Warningf("type `%s` is not supported, converted to STRING", colType)
+	return "STRING"
+}
+
+// rollups the indexes of `DESC ALL` besides the base index named after the table
+func (c *StarRocksSource) rollups(table *model.Table, rows []map[string]interface{}) []*model.OlapRollup {
+	rollups := []*model.OlapRollup{}
+	var rollup *model.OlapRollup
+	for _, row := range rows {
+		if indexName := olapValue(row, "IndexName"); len(indexName) > 0 {
+			rollup = nil
+			if indexName != table.TABLE_NAME {
+				rollup = &model.OlapRollup{Name: indexName}
+				rollups = append(rollups, rollup)
+			}
+		}
+		if field := olapValue(row, "Field"); rollup != nil && len(field) > 0 {
+			rollup.Columns = append(rollup.Columns, field)
+		}
+	}
+	return rollups
+}
+
+// applyPartitionSizes sizes and rows of the tables are the sums of the partitions
+func (c *StarRocksSource) applyPartitionSizes(table *model.Table, rows []map[string]interface{}) {
+	dataLength, tableRows := uint64(0), uint64(0)
+	for _, row := range rows {
+		// `1.234 GB`, `0.000 ` and `2.372KB`
+		if matches := olapSizeReg.FindStringSubmatch(strings.TrimSpace(olapValue(row, "DataSize"))); len(matches) > 0 {
+			size, _ := strconv.ParseFloat(matches[1], 64)
+			if unit := strings.ToUpper(matches[2]); len(unit) == 2 {
+				size *= math.Pow(1024, float64(strings.Index("KMGTP", unit[:1])+1))
+			}
+			dataLength += uint64(size)
+		}
+		rowCount, _ := strconv.ParseUint(olapValue(row, "RowCount"), 10, 64)
+		tableRows += rowCount
+	}
+	if dataLength > 0 {
+		table.DATA_LENGTH = dataLength
+	}
+	if tableRows > 0 {
+		table.TABLE_ROWS = tableRows
+	}
+}
+
+func (c *StarRocksSource) FormatStarRocksColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.columnType(matchedTableRule, column, column.COLUMN_TYPE, false)
+	if table.Olap != nil && len(matchedTableRule.Aggregation(column.COLUMN_NAME)) == 0 {
+		if agg := table.Olap.Aggregations[column.COLUMN_NAME]; len(agg) > 0 {
+			colDataType += " " + agg
+		}
+	}
+	if column.IsGenerated() {
+		if !c.generatedColumnSupported(column) {
+			return "", nil
+		}
+		return fmt.Sprintf("  `%s` %s NULL AS %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, column.GenerationExpression, c.encodeComment(column.COLUMN_COMMENT)), nil
+	}
+	nullableStr := "NULL"
+	if column.IS_NULLABLE != "YES" {
+		nullableStr = "NOT NULL"
+	}
+	defaultStr := ""
+	if column.COLUMN_DEFAULT != nil {
+		defaultStr = c.defaultClause(column, colDataType, *column.COLUMN_DEFAULT)
+	}
+	if strings.Contains(column.EXTRA, "on update") {
+		column.AddDefaultWarning(fmt.Sprintf("`%s` is not supported, the values are copied from the source", column.EXTRA))
+	}
+	if column.IsAutoIncrement() {
+		if dataType, ok := c.autoIncrement(table, column, colDataType); ok {
+			colDataType = dataType
+			nullableStr = "NOT NULL"
+			defaultStr = "AUTO_INCREMENT"
+		}
+	}
+	return fmt.Sprintf("  `%s` %s %s %s COMMENT \"%s\"", column.COLUMN_NAME, colDataType, nullableStr, defaultStr, c.encodeComment(column.COLUMN_COMMENT)), nil
+}
+
+func (c *StarRocksSource) FormatFlinkColumnDef(matchedTableRule *conf.TableRule, table *model.Table, column *model.Column) (string, error) {
+	colDataType := c.columnType(matchedTableRule, column, flinkType(column.COLUMN_TYPE), true)
+	columnStr := fmt.Sprintf("  `%s` %s", column.COLUMN_NAME, colDataType)
+	if column.IS_NULLABLE == "NO" {
+		columnStr += " NOT NULL"
+	}
+	return columnStr, nil
+}
+
+func (c *StarRocksSource) GetFlinkConnectorName() string {
+	return ""
+}
+
+// olapValue the string of the column of `SHOW` statements, empty if absent
+func olapValue(row map[string]interface{}, name string) string {
+	switch value := row[name].(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// olapTokenizer tokens of the clauses of `SHOW CREATE TABLE`
+type olapTokenizer struct {
+	sql string
+	pos int
+}
+
+func (t *olapTokenizer) next() string {
+	token, end := olapToken(t.sql, t.pos)
+	t.pos = end
+	return token
+}
+
+func (t *olapTokenizer) peek() string {
+	token, _ := olapToken(t.sql, t.pos)
+	return token
+}
+
+// olapToken the token at pos and the position after it, quoted strings are unquoted, groups keep their brackets
+func olapToken(sql string, pos int) (string, int) {
+	pos = skipSQLSpaces(sql, pos)
+	if pos >= len(sql) {
+		return "", pos
+	}
+	switch sql[pos] {
+	case '\'', '"', '`':
+		end := skipSQLQuoted(sql, pos)
+		return unquoteOlapString(sql[pos:end]), end
+	case '(', '[':
+		end := matchOlapGroup(sql, pos)
+		if end < 0 {
+			return sql[pos:], len(sql)
+		}
+		return sql[pos : end+1], end + 1
+	}
+	end := pos
+	for end < len(sql) && !strings.ContainsRune(" \t\r\n(,;", rune(sql[end])) {
+		end++
+	}
+	if end == pos {
+		end++
+	}
+	return sql[pos:end], end
+}
+
+// unquoteOlapString the value of the quoted string, escaped and doubled quotes are unescaped
+func unquoteOlapString(quoted string) string {
+	if len(quoted) < 2 {
+		return quoted
+	}
+	quote := quoted[0]
+	var sb strings.Builder
+	for i := 1; i < len(quoted)-1; i++ {
+		if (quoted[i] == '\\' || (quoted[i] == quote && quoted[i+1] == quote)) && i+1 < len(quoted)-1 {
+			i++
+		}
+		sb.WriteByte(quoted[i])
+	}
+	return sb.String()
+}
+
+// matchOlapGroup the position closing the group at pos, `[("a"), ("b"))` of range partitions are closed by parentheses
+func matchOlapGroup(sql string, pos int) int {
+	depth := 0
+	for i := pos; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '`', '"':
+			i = skipSQLQuoted(sql, i) - 1
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitOlapDefs splits the definitions on the commas out of the brackets of groups and nested types,
+// angle brackets only count after ARRAY, MAP and STRUCT and are comparisons elsewhere
+func splitOlapDefs(sql string) []string {
+	defs := []string{}
+	depth := 0
+	typeDepth := 0
+	start := 0
+	for i := 0; i < len(sql); i++ {
+		switch sql[i] {
+		case '\'', '`', '"':
+			i = skipSQLQuoted(sql, i) - 1
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '<':
+			if olapNestedTypeReg.MatchString(sql[:i]) {
+				typeDepth++
+			}
+		case '>':
+			if typeDepth > 0 {
+				typeDepth--
+			}
+		case ',':
+			if depth == 0 && typeDepth == 0 {
+				defs = append(defs, strings.TrimSpace(sql[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(sql[start:]); len(last) > 0 {
+		defs = append(defs, last)
+	}
+	return defs
+}
+
+// olapNames names of the quoted columns of `(`a`, `b`)`, the expressions are kept
+func olapNames(group string) []string {
+	group = strings.TrimSpace(group)
+	if strings.HasPrefix(group, "(") && strings.HasSuffix(group, ")") {
+		group = group[1 : len(group)-1]
+	}
+	names := []string{}
+	for _, def := range splitOlapDefs(group) {
+		if name, end := olapToken(def, 0); end == len(def) {
+			names = append(names, name)
+			continue
+		}
+		names = append(names, def)
+	}
+	return names
+}
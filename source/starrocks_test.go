@@ -0,0 +1,164 @@
+package source
+
+import (
+	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
+	"testing"
+
+	funk "github.com/thoas/go-funk"
+)
+
+func TestStarRocksSource_parseCreateTable(t *testing.T) {
+	ddl := "CREATE TABLE `orders` (\n" +
+		"  `dt` date NOT NULL COMMENT \"\",\n" +
+		"  `id` bigint(20) NOT NULL COMMENT \"\",\n" +
+		"  `items` array<struct<sku varchar(32), qty int(11)>> NULL COMMENT \"\",\n" +
+		"  `amount` decimal128(38, 4) SUM NULL DEFAULT \"0\" COMMENT \"total \\\"amount\\\"\",\n" +
+		"  `users` bitmap BITMAP_UNION NULL COMMENT \"\",\n" +
+		"  INDEX idx_id (`id`) USING BITMAP COMMENT ''\n" +
+		") ENGINE=OLAP \n" +
+		"AGGREGATE KEY(`dt`, `id`, `items`)\n" +
+		"COMMENT \"orders\"\n" +
+		"PARTITION BY RANGE(`dt`)\n" +
+		"(PARTITION p202101 VALUES [(\"2021-01-01\"), (\"2021-02-01\")),\n" +
+		"PARTITION p202102 VALUES [(\"2021-02-01\"), (\"2021-03-01\")))\n" +
+		"DISTRIBUTED BY HASH(`id`) BUCKETS 8 \n" +
+		"PROPERTIES (\n" +
+		"\"replication_num\" = \"3\",\n" +
+		"\"storage_format\" = \"DEFAULT\"\n" +
+		");"
+	source := new(StarRocksSource).Construct(&conf.Config{UseDecimalV3: true}).(*StarRocksSource)
+	table := &model.Table{ModelBase: model.ModelBase{TABLE_CATALOG: "shop", TABLE_SCHEMA: "shop", TABLE_NAME: "orders"}}
+	columns, olap, err := source.parseCreateTable(table, ddl)
+	if err != nil || olap == nil {
+		t.Fatalf("parseCreateTable() = %v, %v", olap, err)
+	}
+	wantTypes := []string{"DATE", "BIGINT", "ARRAY<STRUCT<`sku` VARCHAR(32), `qty` INT>>", "DECIMAL(38, 4)", "BITMAP"}
+	if got := funk.Map(columns, func(col *model.Column) string { return col.COLUMN_TYPE }); !funk.Equal(got, wantTypes) {
+		t.Errorf("COLUMN_TYPE = %v, want %v", got, wantTypes)
+	}
+	if columns[0].IS_NULLABLE != "NO" || columns[3].COLUMN_COMMENT != "total \"amount\"" || *columns[3].COLUMN_DEFAULT != "'0'" {
+		t.Errorf("columns = %v, %v, %v", columns[0].IS_NULLABLE, columns[3].COLUMN_COMMENT, *columns[3].COLUMN_DEFAULT)
+	}
+	if olap.KeysType != "AGGREGATE" || !funk.Equal(olap.Keys, []string{"dt", "id", "items"}) || !funk.Equal(olap.Aggregations, map[string]string{"amount": "SUM", "users": "BITMAP_UNION"}) {
+		t.Errorf("keys = %v %v %v", olap.KeysType, olap.Keys, olap.Aggregations)
+	}
+	if olap.PartitionType != "RANGE" || !funk.Equal(olap.PartitionColumns, []string{"dt"}) || len(olap.Partitions) != 2 ||
+		olap.Partitions[1].Name != "p202102" || olap.Partitions[1].Values != "VALUES [(\"2021-02-01\"), (\"2021-03-01\"))" {
+		t.Errorf("partitions = %v %v %v", olap.PartitionType, olap.PartitionColumns, olap.Partitions)
+	}
+	if !funk.Equal(olap.DistributedBy, []string{"id"}) || olap.Buckets != 8 || len(olap.Indexes) != 1 || table.TABLE_COMMENT != "orders" {
+		t.Errorf("distribution = %v %v %v %v", olap.DistributedBy, olap.Buckets, olap.Indexes, table.TABLE_COMMENT)
+	}
+	if !funk.Equal(olap.Properties, map[string]string{"replication_num": "3", "storage_format": "DEFAULT"}) {
+		t.Errorf("properties = %v", olap.Properties)
+	}
+}
+
+func TestStarRocksSource_parseDorisCreateTable(t *testing.T) {
+	ddl := "CREATE TABLE `events` (\n" +
+		"  `id` BIGINT NOT NULL,\n" +
+		"  `ts` DATETIMEV2(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),\n" +
+		"  `name` VARCHAR(*) NULL COMMENT 'event name',\n" +
+		"  `attrs` MAP<TEXT,JSONB> NULL\n" +
+		") ENGINE=OLAP\n" +
+		"UNIQUE KEY(`id`, `ts`)\n" +
+		"COMMENT 'OLAP'\n" +
+		"AUTO PARTITION BY RANGE (date_trunc(`ts`, 'day'))\n" +
+		"()\n" +
+		"DISTRIBUTED BY RANDOM BUCKETS AUTO\n" +
+		"PROPERTIES (\n" +
+		"\"replication_allocation\" = \"tag.location.default: 3\",\n" +
+		"\"enable_unique_key_merge_on_write\" = \"true\"\n" +
+		");"
+	source := new(StarRocksSource).Construct(&conf.Config{}).(*StarRocksSource)
+	columns, olap, err := source.parseCreateTable(&model.Table{}, ddl)
+	if err != nil || olap == nil {
+		t.Fatalf("parseCreateTable() = %v, %v", olap, err)
+	}
+	wantTypes := []string{"BIGINT", "DATETIME", "VARCHAR(65533)", "MAP<STRING, JSON>"}
+	if got := funk.Map(columns, func(col *model.Column) string { return col.COLUMN_TYPE }); !funk.Equal(got, wantTypes) {
+		t.Errorf("COLUMN_TYPE = %v, want %v", got, wantTypes)
+	}
+	if *columns[1].COLUMN_DEFAULT != "CURRENT_TIMESTAMP(3)" || columns[2].COLUMN_COMMENT != "event name" {
+		t.Errorf("columns = %v, %v", *columns[1].COLUMN_DEFAULT, columns[2].COLUMN_COMMENT)
+	}
+	if olap.KeysType != "UNIQUE" || olap.PartitionType != "EXPRESSION" || !funk.Equal(olap.PartitionColumns, []string{"date_trunc('day', `ts`)"}) {
+		t.Errorf("olap = %v %v %v", olap.KeysType, olap.PartitionType, olap.PartitionColumns)
+	}
+	if len(olap.DistributedBy) != 0 || olap.Buckets != 0 || olap.Properties["replication_allocation"] != "tag.location.default: 3" {
+		t.Errorf("distribution = %v %v %v", olap.DistributedBy, olap.Buckets, olap.Properties)
+	}
+	// comparisons of generated columns
+	columns, _, err = source.parseCreateTable(&model.Table{}, "CREATE TABLE `t` (\n  `a` int NULL,\n  `b` boolean NULL AS (`a` > 0),\n  `c` int NULL\n) ENGINE=OLAP\nDUPLICATE KEY(`a`)\nDISTRIBUTED BY RANDOM")
+	if err != nil || len(columns) != 3 || columns[1].GenerationExpression != "(`a` > 0)" || columns[2].COLUMN_NAME != "c" {
+		t.Errorf("parseCreateTable() = %v, %v", columns, err)
+	}
+	// tables of the other engines are skipped
+	if _, olap, err := source.parseCreateTable(&model.Table{}, "CREATE EXTERNAL TABLE `t` (\n  `id` int(11) NULL\n) ENGINE=MYSQL\nPROPERTIES (\n\"host\" = \"127.0.0.1\"\n);"); olap != nil || err != nil {
+		t.Errorf("parseCreateTable() = %v, %v, want none", olap, err)
+	}
+}
+
+func TestStarRocksSource_rollupsAndSizes(t *testing.T) {
+	source := new(StarRocksSource).Construct(&conf.Config{}).(*StarRocksSource)
+	table := &model.Table{ModelBase: model.ModelBase{TABLE_NAME: "orders"}}
+	rollups := source.rollups(table, []map[string]interface{}{
+		{"IndexName": "orders", "Field": "dt"},
+		{"IndexName": "", "Field": "id"},
+		{"IndexName": "", "Field": "amount"},
+		{"IndexName": "r_dt", "Field": "dt"},
+		{"IndexName": "", "Field": "amount"},
+	})
+	if len(rollups) != 1 || rollups[0].Name != "r_dt" || !funk.Equal(rollups[0].Columns, []string{"dt", "amount"}) {
+		t.Errorf("rollups() = %v", rollups)
+	}
+	source.applyPartitionSizes(table, []map[string]interface{}{
+		{"DataSize": "1.500 GB", "RowCount": "100"},
+		{"DataSize": "512.000KB", "RowCount": "20"},
+		{"DataSize": "0.000 "},
+	})
+	if table.DATA_LENGTH != 1610612736+524288 || table.TABLE_ROWS != 120 {
+		t.Errorf("applyPartitionSizes() = %v, %v", table.DATA_LENGTH, table.TABLE_ROWS)
+	}
+}
+
+func TestSplitOlapDefs(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"`a` int, `b` boolean NULL AS (`a` > 0), `c` int", []string{"`a` int", "`b` boolean NULL AS (`a` > 0)", "`c` int"}},
+		{"`a` int, `b` boolean AS `a` < 0, `c` int", []string{"`a` int", "`b` boolean AS `a` < 0", "`c` int"}},
+		{"`m` MAP<INT, ARRAY<STRUCT<a INT, b INT>>>, `s` struct <x int, y int>, `d` decimal(10, 2)", []string{"`m` MAP<INT, ARRAY<STRUCT<a INT, b INT>>>", "`s` struct <x int, y int>", "`d` decimal(10, 2)"}},
+		{"\"k\" = \"a,b\", \"v\" = \"1\"", []string{"\"k\" = \"a,b\"", "\"v\" = \"1\""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			if got := splitOlapDefs(tt.sql); !funk.Equal(got, tt.want) {
+				t.Errorf("splitOlapDefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStarRocksSource_olapType(t *testing.T) {
+	tests := []struct {
+		colType      string
+		useDecimalV3 bool
+		want         string
+	}{
+		{"decimal128(38, 4)", true, "DECIMAL(38, 4)"},
+		{"DECIMALV3(76, 10)", true, "STRING"},
+		{"decimal(30, 2)", false, "STRING"},
+		{"decimalv2(27, 9)", false, "DECIMAL(27, 9)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.colType, func(t *testing.T) {
+			source := new(StarRocksSource).Construct(&conf.Config{UseDecimalV3: tt.useDecimalV3}).(*StarRocksSource)
+			if got := source.olapType(tt.colType); got != tt.want {
+				t.Errorf("olapType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package source
 import (
 	"starrocks-migrate-tool/common"
 	"starrocks-migrate-tool/conf"
+	"starrocks-migrate-tool/model"
 )
 
 type TiDBSource struct {
@@ -27,7 +28,7 @@ func (c *TiDBSource) GetFlinkConnectorName() string {
 	return "tidb-cdc"
 }
 
-func (c *TiDBSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule) map[string]string {
+func (c *TiDBSource) GetFlinkSpecialProps(matchedTableRule *conf.TableRule, table *model.Table) map[string]string {
 	specialProps := make(map[string]string)
 	if _, ok := matchedTableRule.FlinkSourceProps["pd-addresses"]; !ok {
 		pdAddresses, _ := c.pdInstance()